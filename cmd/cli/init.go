@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zguydev/openapi-filter/internal/utils"
+	"github.com/zguydev/openapi-filter/pkg/config"
+	"github.com/zguydev/openapi-filter/pkg/loader"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init input_spec [--output filter_config]",
+	Short: "Generate a starter filter config keeping all paths, methods and components of a spec",
+	Args:  cobra.ExactArgs(1),
+	Run:   runInit,
+}
+
+func init() {
+	initCmd.Flags().StringP("output", "o", "", "Path of the generated config (default: stdout)")
+	initCmd.Flags().Bool("force", false, "Overwrite the output file if it exists")
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) {
+	logger := utils.NewFallbackLogger()
+
+	// External refs are only read, to list the paths and components they define.
+	spec, err := loader.NewFileLoader(&config.LoaderConfig{IsExternalRefsAllowed: true}).
+		Load(cmd.Context(), args[0])
+	if err != nil {
+		fatal(logger, "failed to load spec from file", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if outPath, _ := cmd.Flags().GetString("output"); outPath != "" {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		if force, _ := cmd.Flags().GetBool("force"); force {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		}
+		f, err := os.OpenFile(outPath, flags, 0o644)
+		if err != nil {
+			fatal(logger, "failed to create config file", err)
+		}
+		defer f.Close() //nolint:errcheck
+		w = f
+	}
+
+	if err := config.WriteScaffold(w, spec); err != nil {
+		fatal(logger, "failed to write config", err)
+	}
+}
@@ -1,13 +1,15 @@
 package cli
 
 import (
+	"context"
 	"os"
+	"os/signal"
 
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "openapi-filter input_spec output_spec [--config filter_config]",
+	Use:   "openapi-filter input_spec output_spec|output_dir [--config filter_config]",
 	Short: "Filter an OpenAPI spec to only include specified paths/methods or components",
 	Args:  checkArgs,
 	Run:   run,
@@ -17,17 +19,39 @@ func checkArgs(cmd *cobra.Command, args []string) error {
 	if ok, _ := cmd.Flags().GetBool("version"); ok {
 		return nil
 	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	lint, _ := cmd.Flags().GetBool("lint")
+	if dryRun || lint {
+		const minArgs, maxArgs = 1, 2
+		return cobra.RangeArgs(minArgs, maxArgs)(cmd, args)
+	}
 	const exactArgs = 2
 	return cobra.ExactArgs(exactArgs)(cmd, args)
 }
 
+// Execute runs the command, canceling the loading of external refs and the
+// filtering on interrupt.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
-	rootCmd.Flags().String("config", ".openapi-filter.yaml", "Path to filter config")
+	rootCmd.Flags().String("config", ".openapi-filter.yaml", "Path or HTTP(S) URL of the filter config")
 	rootCmd.Flags().Bool("version", false, "Print version and exit")
+	rootCmd.Flags().Int("yaml-line-width", 0, "Preferred line width of the YAML output (0 = no wrap)")
+	rootCmd.Flags().Bool("sort-keys", false, "Sort the keys of the output spec alphabetically instead of keeping the source order")
+	rootCmd.Flags().Bool("check", false, "Compare the output spec to the existing file instead of writing it, printing a diff and exiting with status 1 if they differ")
+	rootCmd.Flags().Bool("fail-on-warnings", false, "Fail if filtering reports any warning, listing them all, like failOnWarnings in the config")
+	rootCmd.Flags().Bool("dry-run", false, "Print the filtering plan instead of writing the output spec")
+	rootCmd.Flags().String("plan-format", "text", "Format of the dry-run plan (text, github)")
+	rootCmd.Flags().Bool("lint", false, "Print the redundant, unmatched and no-op entries of the filter config, exiting with status 1 if any")
+	rootCmd.Flags().String("diff", "", "Print the difference between the input and filtered specs (text, json)")
+	rootCmd.Flags().Lookup("diff").NoOptDefVal = "text"
+	rootCmd.Flags().String("output-stats", "", "Print the path, component and byte counts of the input and filtered specs (text, json)")
+	rootCmd.Flags().Lookup("output-stats").NoOptDefVal = "text"
 }
@@ -1,22 +1,29 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
+	"path/filepath"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/spf13/cobra"
-	"go.uber.org/zap"
 
 	"github.com/zguydev/openapi-filter/internal"
 	"github.com/zguydev/openapi-filter/internal/utils"
 	"github.com/zguydev/openapi-filter/pkg/config"
+	"github.com/zguydev/openapi-filter/pkg/diff"
 	"github.com/zguydev/openapi-filter/pkg/filter"
 	"github.com/zguydev/openapi-filter/pkg/loader"
+	"github.com/zguydev/openapi-filter/pkg/plan"
+	"github.com/zguydev/openapi-filter/pkg/writer"
 )
 
 func run(cmd *cobra.Command, args []string) {
 	fallbackLogger := utils.NewFallbackLogger()
-	defer fallbackLogger.Sync() //nolint:errcheck
 
 	if ok, _ := cmd.Flags().GetBool("version"); ok {
 		info, _ := internal.GetInfo()
@@ -26,39 +33,326 @@ func run(cmd *cobra.Command, args []string) {
 
 	configPath, err := cmd.Flags().GetString("config")
 	if err != nil {
-		fallbackLogger.Fatal("failed to get config flag", zap.Error(err))
+		fatal(fallbackLogger, "failed to get config flag", err)
 	}
 
-	cfg, err := config.LoadConfig(configPath)
+	yamlLineWidth, err := cmd.Flags().GetInt("yaml-line-width")
 	if err != nil {
-		fallbackLogger.Fatal("failed to load config", zap.Error(err))
+		fatal(fallbackLogger, "failed to get yaml-line-width flag", err)
+	}
+
+	// The default config file is optional when the spec embeds its config.
+	if !cmd.Flags().Changed("config") && !fileExists(configPath) {
+		configPath = ""
+	}
+
+	ctx := cmd.Context()
+	cfg := &config.Config{}
+	if configPath != "" {
+		cfg, err = config.LoadConfigContext(ctx, configPath)
+		if err != nil {
+			fatal(fallbackLogger, "failed to load config", err)
+		}
 	}
 
 	logger, err := utils.NewLogger(cfg.Tool.Logger)
 	if err != nil {
-		fallbackLogger.Fatal("failed to init logger", zap.Error(err))
+		fatal(fallbackLogger, "failed to init logger", err)
 	}
 
-	inputSpecPath, outSpecPath := args[0], args[1]
+	inputSpecPath := args[0]
 
-	inputSpec, err := internal.LoadSpecFromFile(
-		loader.NewLoader(cfg.Tool.Loader), inputSpecPath)
+	specLoader := loader.NewFileLoader(cfg.Tool.Loader).WithLogger(logger)
+	inputSpec, err := specLoader.Load(ctx, inputSpecPath)
 	if err != nil {
 		logger.Error("failed to load spec from file",
-			zap.Error(err), zap.String("path", inputSpecPath))
+			slog.Any("error", err), slog.String("path", inputSpecPath))
+		os.Exit(1)
+	}
+
+	// Tool settings are taken from the config file only, as the logger and
+	// loader are needed before the spec with the embedded config is loaded.
+	if config.HasEmbeddedConfig(inputSpec) {
+		tool := cfg.Tool
+		cfg, err = config.LoadLayeredConfig(inputSpec, configPath)
+		if err != nil {
+			logger.Error("failed to load config embedded in spec",
+				slog.Any("error", err), slog.String("path", inputSpecPath))
+			os.Exit(1)
+		}
+		cfg.Tool = tool
+	} else if configPath == "" {
+		logger.Error("no filter config: config file not found and spec has no embedded config",
+			slog.String("path", inputSpecPath))
 		os.Exit(1)
 	}
+
+	for _, entry := range cfg.RemovedDuplicates() {
+		logger.Debug("duplicate removed from filter config", slog.String("entry", entry))
+	}
+
+	if failOnWarnings, _ := cmd.Flags().GetBool("fail-on-warnings"); failOnWarnings {
+		cfg.FailOnWarnings = true
+		for name, output := range cfg.Outputs {
+			output.FailOnWarnings = true
+			cfg.Outputs[name] = output
+		}
+	}
+
+	// Linted before validation, so the findings are printed for invalid
+	// configs too
+	if ok, _ := cmd.Flags().GetBool("lint"); ok {
+		runLint(cfg, inputSpec)
+		return
+	}
+
+	// The config is normalized by each filtering, it is only validated here
+	// to report the problems of every output at once
+	if err := cfg.Validate(inputSpec); err != nil {
+		fatal(logger, "invalid filter config", err)
+	}
+
+	if ok, _ := cmd.Flags().GetBool("dry-run"); ok {
+		annotatedPath := configPath
+		if annotatedPath == "" {
+			annotatedPath = inputSpecPath
+		}
+		runPlan(cmd, cfg, inputSpec, annotatedPath, logger)
+		return
+	}
+	reports := reportFormats{}
+	reports.diff, _ = cmd.Flags().GetString("diff")
+	reports.stats, _ = cmd.Flags().GetString("output-stats")
+	sortKeys, _ := cmd.Flags().GetBool("sort-keys")
+	check, _ := cmd.Flags().GetBool("check")
+	writeOpts := writer.Options{
+		YAMLLineWidth: yamlLineWidth,
+		SourcePath:    inputSpecPath,
+		SortKeys:      sortKeys,
+	}
+	upToDate := true
+	if cfg.IsMultiOutput() {
+		upToDate = runOutputs(ctx, cfg, inputSpec, args[1], reports, writeOpts, check, logger)
+	} else {
+		upToDate = filterToFile(ctx, cfg, inputSpec, args[1], reports, writeOpts, check, logger)
+	}
+	if !upToDate {
+		os.Exit(1)
+	}
+}
+
+// reportFormats are the formats of the reports printed when filtering,
+// empty when not requested.
+type reportFormats struct {
+	diff  string
+	stats string
+}
+
+// runOutputs writes every output of a multi-output config to outDir, or checks
+// them, see filterToFile. It reports whether every checked output is up to
+// date.
+func runOutputs(
+	ctx context.Context,
+	cfg *config.Config,
+	inputSpec *openapi3.T,
+	outDir string,
+	reports reportFormats,
+	writeOpts writer.Options,
+	check bool,
+	logger *slog.Logger,
+) bool {
+	upToDate := true
+	for _, name := range cfg.OutputNames() {
+		outSpecPath := filepath.Join(outDir, name)
+		if !check {
+			if err := os.MkdirAll(filepath.Dir(outSpecPath), 0o755); err != nil {
+				fatal(logger, "failed to create output directory", err)
+			}
+		}
+		if !filterToFile(ctx, cfg.OutputConfig(name), inputSpec, outSpecPath, reports, writeOpts, check, logger) {
+			upToDate = false
+		}
+	}
+	return upToDate
+}
+
+// filterToFile filters the input spec and writes the result to outSpecPath,
+// printing the requested reports first. In check mode, the result is compared
+// to the existing outSpecPath instead, printing a diff if they differ, and it
+// reports whether they are equal.
+func filterToFile(
+	ctx context.Context,
+	cfg *config.Config,
+	inputSpec *openapi3.T,
+	outSpecPath string,
+	reports reportFormats,
+	writeOpts writer.Options,
+	check bool,
+	logger *slog.Logger,
+) bool {
+	var snapshot *diff.Snapshot
+	if reports.diff != "" || reports.stats != "" {
+		var err error
+		if snapshot, err = diff.TakeSnapshot(inputSpec); err != nil {
+			logger.Error("failed to snapshot input spec", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
 	oaf := filter.NewOpenAPISpecFilter(cfg, logger)
-	outSpec, err := oaf.Filter(inputSpec)
+	outSpec, err := oaf.FilterContext(ctx, inputSpec)
 	if err != nil {
-		logger.Error("filter on spec failed", zap.Error(err))
+		logger.Error("filter on spec failed", slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	if err := internal.WriteSpecToFile(outSpec, outSpecPath); err != nil {
+	if reports.diff != "" {
+		if err := writeDiff(snapshot, outSpec, reports.diff); err != nil {
+			logger.Error("failed to write diff", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+	if reports.stats != "" {
+		if err := writeStats(snapshot, outSpec, reports.stats); err != nil {
+			logger.Error("failed to write stats", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	if check {
+		return checkSpecFile(outSpec, outSpecPath, writeOpts, logger)
+	}
+	if err := writer.WriteSpecToFile(outSpec, outSpecPath, writer.FormatYAML, writeOpts); err != nil {
 		logger.Error("failed to write filtered spec file",
-			zap.Error(err), zap.String("path", outSpecPath))
+			slog.Any("error", err), slog.String("path", outSpecPath))
 		os.Exit(1)
 	}
-	logger.Info("filtered and saved spec", zap.String("path", outSpecPath))
+	logger.Info("filtered and saved spec", slog.String("path", outSpecPath))
+	return true
+}
+
+// checkSpecFile reports whether the spec file at outSpecPath is the filtered
+// spec, written with writeOpts, printing the diff between them otherwise. A
+// missing file differs from any spec.
+func checkSpecFile(
+	outSpec *openapi3.T,
+	outSpecPath string,
+	writeOpts writer.Options,
+	logger *slog.Logger,
+) bool {
+	want, err := writer.MarshalSpec(outSpec, writer.FormatYAML, writeOpts)
+	if err != nil {
+		logger.Error("failed to marshal filtered spec", slog.Any("error", err))
+		os.Exit(1)
+	}
+	got, err := os.ReadFile(outSpecPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Error("failed to read filtered spec file",
+			slog.Any("error", err), slog.String("path", outSpecPath))
+		os.Exit(1)
+	}
+
+	d := internal.LineDiff(outSpecPath, outSpecPath+" (filtered)", got, want)
+	if d == "" {
+		logger.Info("filtered spec is up to date", slog.String("path", outSpecPath))
+		return true
+	}
+	fmt.Print(d)
+	logger.Error("filtered spec is not up to date", slog.String("path", outSpecPath))
+	return false
+}
+
+// runPlan prints the filtering plan of the input spec in the requested format.
+func runPlan(
+	cmd *cobra.Command,
+	cfg *config.Config,
+	inputSpec *openapi3.T,
+	configPath string,
+	logger *slog.Logger,
+) {
+	planFormat, _ := cmd.Flags().GetString("plan-format")
+	format, err := plan.ParseFormat(planFormat)
+	if err != nil {
+		logger.Error("invalid plan-format flag", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	var entries []plan.Entry
+	if cfg.IsMultiOutput() {
+		for _, name := range cfg.OutputNames() {
+			output, err := cfg.OutputConfig(name).NormalizedFilterConfig(inputSpec)
+			if err != nil {
+				fatal(logger, "invalid filter config", err)
+			}
+			for _, e := range plan.Build(output, inputSpec) {
+				e.Message = name + ": " + e.Message
+				entries = append(entries, e)
+			}
+		}
+	} else {
+		normalized, err := cfg.NormalizedFilterConfig(inputSpec)
+		if err != nil {
+			fatal(logger, "invalid filter config", err)
+		}
+		entries = plan.Build(normalized, inputSpec)
+	}
+	if err := plan.Write(os.Stdout, entries, format, configPath); err != nil {
+		logger.Error("failed to write plan", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+// runLint prints the entries of the filter config flagged by
+// filter.LintConfig, one per line, exiting with status 1 if any.
+func runLint(cfg *config.Config, inputSpec *openapi3.T) {
+	diagnostics := filter.LintConfig(cfg, inputSpec)
+	for _, diag := range diagnostics {
+		fmt.Println(diag)
+	}
+	if len(diagnostics) != 0 {
+		os.Exit(1)
+	}
+}
+
+// writeDiff prints the difference between the input and filtered specs.
+func writeDiff(snapshot *diff.Snapshot, outSpec *openapi3.T, format string) error {
+	d, err := diff.Compare(snapshot, outSpec)
+	if err != nil {
+		return fmt.Errorf("diff.Compare: %w", err)
+	}
+	switch format {
+	case "text":
+		return d.WriteText(os.Stdout)
+	case "json":
+		return d.WriteJSON(os.Stdout)
+	default:
+		return fmt.Errorf("unsupported diff format: %s", format)
+	}
+}
+
+// writeStats prints the size metrics of the input and filtered specs.
+func writeStats(snapshot *diff.Snapshot, outSpec *openapi3.T, format string) error {
+	stats, err := diff.ComputeStats(snapshot, outSpec)
+	if err != nil {
+		return fmt.Errorf("diff.ComputeStats: %w", err)
+	}
+	switch format {
+	case "text":
+		return stats.WriteText(os.Stdout)
+	case "json":
+		return stats.WriteJSON(os.Stdout)
+	default:
+		return fmt.Errorf("unsupported stats format: %s", format)
+	}
+}
+
+// fatal logs the error and exits.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
@@ -2,6 +2,8 @@ package components
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/getkin/kin-openapi/openapi3"
 
@@ -125,3 +127,32 @@ func ComponentTypeToCfgNames(
 		panic(fmt.Errorf("unsupported component type: %T", typ))
 	}
 }
+
+// ComponentNames returns the sorted names of the components of the given type.
+func ComponentNames(
+	components *openapi3.Components,
+	typ ComponentType,
+) []string {
+	switch typ {
+	case ComponentTypeSchema:
+		return slices.Sorted(maps.Keys(components.Schemas))
+	case ComponentTypeParameter:
+		return slices.Sorted(maps.Keys(components.Parameters))
+	case ComponentTypeHeader:
+		return slices.Sorted(maps.Keys(components.Headers))
+	case ComponentTypeRequestBody:
+		return slices.Sorted(maps.Keys(components.RequestBodies))
+	case ComponentTypeResponse:
+		return slices.Sorted(maps.Keys(components.Responses))
+	case ContentTypeSecuritySchema:
+		return slices.Sorted(maps.Keys(components.SecuritySchemes))
+	case ContentTypeExample:
+		return slices.Sorted(maps.Keys(components.Examples))
+	case ContentTypeLink:
+		return slices.Sorted(maps.Keys(components.Links))
+	case ContentTypeCallback:
+		return slices.Sorted(maps.Keys(components.Callbacks))
+	default:
+		panic(fmt.Errorf("unsupported component type: %T", typ))
+	}
+}
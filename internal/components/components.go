@@ -102,23 +102,23 @@ func isComponentMapEmpty(
 ) bool {
 	switch typ {
 	case ComponentTypeSchema:
-		return len(comps.Schemas) != 0
+		return len(comps.Schemas) == 0
 	case ComponentTypeParameter:
-		return len(comps.Parameters) != 0
+		return len(comps.Parameters) == 0
 	case ComponentTypeHeader:
-		return len(comps.Headers) != 0
+		return len(comps.Headers) == 0
 	case ComponentTypeRequestBody:
-		return len(comps.RequestBodies) != 0
+		return len(comps.RequestBodies) == 0
 	case ComponentTypeResponse:
-		return len(comps.Responses) != 0
+		return len(comps.Responses) == 0
 	case ContentTypeSecuritySchema:
-		return len(comps.SecuritySchemes) != 0
+		return len(comps.SecuritySchemes) == 0
 	case ContentTypeExample:
-		return len(comps.Examples) != 0
+		return len(comps.Examples) == 0
 	case ContentTypeLink:
-		return len(comps.Links) != 0
+		return len(comps.Links) == 0
 	case ContentTypeCallback:
-		return len(comps.Callbacks) != 0
+		return len(comps.Callbacks) == 0
 	default:
 		panic(fmt.Errorf("unsupported component type: %v", typ))
 	}
@@ -133,5 +133,5 @@ func IsEmptyComponents(comps *openapi3.Components) bool {
 			return false
 		}
 	}
-	return true
+	return len(comps.Extensions) == 0
 }
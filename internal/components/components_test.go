@@ -0,0 +1,27 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestIsEmptyComponents(t *testing.T) {
+	tests := []struct {
+		name  string
+		comps *openapi3.Components
+		want  bool
+	}{
+		{"nil", nil, true},
+		{"no maps", &openapi3.Components{}, true},
+		{"empty maps", &openapi3.Components{Schemas: openapi3.Schemas{}, Links: openapi3.Links{}}, true},
+		{"one schema", &openapi3.Components{Schemas: openapi3.Schemas{"Pet": {Value: &openapi3.Schema{}}}}, false},
+		{"one link", &openapi3.Components{Links: openapi3.Links{"Next": {Value: &openapi3.Link{}}}}, false},
+		{"extensions", &openapi3.Components{Extensions: map[string]any{"pathItems": map[string]any{}}}, false},
+	}
+	for _, tt := range tests {
+		if got := IsEmptyComponents(tt.comps); got != tt.want {
+			t.Errorf("%s: IsEmptyComponents() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package extensions
+
+import (
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+const extensionsField = "Extensions"
+
+// Strip walks the whole document and removes the extensions for which strip
+// returns true, wherever they appear (root, paths, operations, schemas, etc.).
+func Strip(doc *openapi3.T, strip func(key string) bool) {
+	walk.Walk(doc, func(v reflect.Value) bool {
+		if v.Kind() == reflect.Struct {
+			if fv := v.FieldByName(extensionsField); fv.IsValid() {
+				stripExtensions(fv, strip)
+			}
+		}
+		return true
+	})
+}
+
+func stripExtensions(fv reflect.Value, strip func(key string) bool) {
+	exts, ok := fv.Interface().(map[string]any)
+	if !ok || len(exts) == 0 || !fv.CanSet() {
+		return
+	}
+
+	kept := make(map[string]any, len(exts))
+	for key, val := range exts {
+		if !strip(key) {
+			kept[key] = val
+		}
+	}
+	if len(kept) == len(exts) {
+		return
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	fv.Set(reflect.ValueOf(kept))
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around a change.
+const diffContext = 3
+
+// LineDiff returns a unified diff of the lines of oldData and newData, or an
+// empty string if their lines are equal, whatever their line endings. Lines
+// common to the start and end of both are skipped and everything between them
+// is shown as a single hunk, which is not a minimal diff when there are
+// several changes but is cheap on large specs.
+func LineDiff(oldName, newName string, oldData, newData []byte) string {
+	oldLines := splitLines(oldData)
+	newLines := splitLines(newData)
+	if slices.Equal(oldLines, newLines) {
+		return ""
+	}
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	start := max(prefix-diffContext, 0)
+	oldEnd := min(len(oldLines)-suffix+diffContext, len(oldLines))
+	newEnd := min(len(newLines)-suffix+diffContext, len(newLines))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(start, oldEnd), hunkRange(start, newEnd))
+	for _, line := range oldLines[start:prefix] {
+		sb.WriteString(" " + line + "\n")
+	}
+	for _, line := range oldLines[prefix : len(oldLines)-suffix] {
+		sb.WriteString("-" + line + "\n")
+	}
+	for _, line := range newLines[prefix : len(newLines)-suffix] {
+		sb.WriteString("+" + line + "\n")
+	}
+	for _, line := range oldLines[len(oldLines)-suffix : oldEnd] {
+		sb.WriteString(" " + line + "\n")
+	}
+	return sb.String()
+}
+
+// splitLines splits data into lines without their line endings. A final
+// line ending does not start an empty line.
+func splitLines(data []byte) []string {
+	s := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// hunkRange formats the 0-based line range [start, end) as a unified diff
+// range, "start,count" with 1-based lines.
+func hunkRange(start, end int) string {
+	if start == end {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, end-start)
+}
@@ -0,0 +1,26 @@
+// Package oas31 reads the OpenAPI 3.1 objects the loaded kin-openapi version
+// does not model, which it keeps as raw extension values.
+package oas31
+
+import "github.com/getkin/kin-openapi/openapi3"
+
+// PathItemsDef is the components family of the reusable path items.
+const PathItemsDef = "pathItems"
+
+// WebhooksKey is the root key of the webhooks.
+const WebhooksKey = "webhooks"
+
+// PathItems returns the reusable path items of components.pathItems.
+func PathItems(comps *openapi3.Components) map[string]any {
+	if comps == nil {
+		return nil
+	}
+	items, _ := comps.Extensions[PathItemsDef].(map[string]any)
+	return items
+}
+
+// Webhooks returns the webhooks of the spec, by name.
+func Webhooks(doc *openapi3.T) map[string]any {
+	webhooks, _ := doc.Extensions[WebhooksKey].(map[string]any)
+	return webhooks
+}
@@ -1,12 +1,83 @@
 package refs
 
-import "strings"
+import (
+	"net/url"
+	"strings"
+)
 
+var (
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+// ParsePointer splits the JSON Pointer fragment of a ref into its reference
+// tokens. The fragment is URL-decoded first, then "~1" and "~0" are unescaped
+// to "/" and "~" in each token (RFC 6901). For refs to other documents, only
+// the fragment is parsed.
+func ParsePointer(ref string) (tokens []string, ok bool) {
+	_, fragment, found := strings.Cut(ref, "#")
+	if !found || !strings.HasPrefix(fragment, "/") {
+		return nil, false
+	}
+	fragment, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, false
+	}
+
+	tokens = strings.Split(fragment[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+	return tokens, true
+}
+
+// ParseRef parses a ref to a component, e.g. "#/components/schemas/Pet".
 func ParseRef(ref string) (def, name string, ok bool) {
-	elems := strings.Split(ref, "/")
-	if len(elems) != 4 {
+	tokens, ok := ParsePointer(ref)
+	if !ok || len(tokens) != 3 || tokens[0] != "components" {
+		return "", "", false
+	}
+	return tokens[1], tokens[2], true
+}
+
+// ParsePathRef parses a ref to a path item or to one of its operations, e.g.
+// "#/paths/~1users~1{id}/get". The method is empty for a path item ref.
+func ParsePathRef(ref string) (path, method string, ok bool) {
+	tokens, ok := ParsePointer(ref)
+	if !ok || tokens[0] != "paths" {
 		return "", "", false
 	}
-	def, name = elems[2], elems[3]
-	return def, name, true
+	switch len(tokens) {
+	case 2:
+		return tokens[1], "", true
+	case 3:
+		return tokens[1], tokens[2], true
+	}
+	return "", "", false
+}
+
+// FormatRef returns the canonical ref to a component, escaping the name as a
+// JSON Pointer token.
+func FormatRef(def, name string) string {
+	return "#/components/" + def + "/" + pointerEscaper.Replace(name)
+}
+
+// FormatPointer returns the JSON Pointer of the tokens, escaping them, e.g.
+// "/paths/~1users/get" for ["paths", "/users", "get"].
+func FormatPointer(tokens ...string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString("/" + pointerEscaper.Replace(token))
+	}
+	return sb.String()
+}
+
+// NormalizeRef returns the canonical form of a component ref, so that refs
+// written with different escaping compare equal. Other refs are returned as is.
+func NormalizeRef(ref string) string {
+	def, name, ok := ParseRef(ref)
+	if !ok {
+		return ref
+	}
+	return FormatRef(def, name)
 }
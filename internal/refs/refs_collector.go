@@ -9,17 +9,28 @@ import (
 )
 
 type RefsCollector struct {
-	refs map[string]struct{}
+	refs    map[string]struct{}
+	schemas map[*openapi3.Schema]struct{} // Collected schemas, as schemas can be recursive
 }
 
 func NewRefsCollector() *RefsCollector {
 	return &RefsCollector{
-		refs: make(map[string]struct{}),
+		refs:    make(map[string]struct{}),
+		schemas: make(map[*openapi3.Schema]struct{}),
 	}
 }
 
+// AddRef adds a ref to the collected refs. Component refs are stored in their
+// canonical form, see NormalizeRef.
 func (rc *RefsCollector) AddRef(ref string) {
-	rc.refs[ref] = struct{}{}
+	rc.refs[NormalizeRef(ref)] = struct{}{}
+}
+
+// AddRefs adds refs returned by the Refs method of another collector.
+func (rc *RefsCollector) AddRefs(refs map[string]struct{}) {
+	for ref := range refs {
+		rc.refs[ref] = struct{}{}
+	}
 }
 
 func (rc *RefsCollector) Refs() map[string]struct{} {
@@ -35,6 +46,11 @@ func (rc *RefsCollector) CollectOperation(op *openapi3.Operation) {
 	rc.collectCallbacks(op.Callbacks)
 }
 
+// CollectParameters collects the refs of parameters, e.g. of a path item.
+func (rc *RefsCollector) CollectParameters(params openapi3.Parameters) {
+	rc.collectParameters(params)
+}
+
 func (rc *RefsCollector) collectParameters(params openapi3.Parameters) {
 	for _, param := range params {
 		if param.Ref != "" {
@@ -193,6 +209,11 @@ func (rc *RefsCollector) collectSchemas(scs openapi3.Schemas) {
 }
 
 func (rc *RefsCollector) collectSchema(sc *openapi3.Schema) {
+	if _, ok := rc.schemas[sc]; ok {
+		return
+	}
+	rc.schemas[sc] = struct{}{}
+
 	rc.collectSchemaRefs(sc.OneOf)
 	rc.collectSchemaRefs(sc.AnyOf)
 	rc.collectSchemaRefs(sc.AllOf)
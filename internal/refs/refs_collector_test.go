@@ -0,0 +1,31 @@
+package refs
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestCollectOperationRecursiveSchema(t *testing.T) {
+	// A resolved recursive schema: Node.children.items points back to Node
+	node := &openapi3.Schema{Type: &openapi3.Types{"object"}, Properties: openapi3.Schemas{}}
+	nodeRef := &openapi3.SchemaRef{Ref: "#/components/schemas/Node", Value: node}
+	node.Properties["children"] = &openapi3.SchemaRef{Value: &openapi3.Schema{
+		Type:  &openapi3.Types{"array"},
+		Items: nodeRef,
+	}}
+	node.Properties["parent"] = nodeRef
+
+	op := openapi3.NewOperation()
+	op.AddResponse(200, openapi3.NewResponse().
+		WithDescription("Tree").
+		WithJSONSchemaRef(nodeRef))
+
+	rc := NewRefsCollector()
+	rc.CollectOperation(op)
+	if got := slices.Sorted(maps.Keys(rc.Refs())); !slices.Equal(got, []string{"#/components/schemas/Node"}) {
+		t.Errorf("got refs %v, want the Node schema", got)
+	}
+}
@@ -0,0 +1,67 @@
+package refs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		ref    string
+		want   []string
+		wantOK bool
+	}{
+		{"#/components/schemas/Pet", []string{"components", "schemas", "Pet"}, true},
+		{"#/paths/~1users~1{id}/get", []string{"paths", "/users/{id}", "get"}, true},
+		{"#/paths/~1users~1%7Bid%7D/get", []string{"paths", "/users/{id}", "get"}, true},
+		{"#/components/schemas/a~0b~1c", []string{"components", "schemas", "a~b/c"}, true},
+		{"#/components/schemas/~01", []string{"components", "schemas", "~1"}, true},
+		{"#/components/schemas/Pet%20Info", []string{"components", "schemas", "Pet Info"}, true},
+		{"pets.yaml#/components/schemas/Pet", []string{"components", "schemas", "Pet"}, true},
+		{"#/components/schemas/%zz", nil, false},
+		{"pets.yaml", nil, false},
+		{"#Pet", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParsePointer(tt.ref)
+		if ok != tt.wantOK || !slices.Equal(got, tt.want) {
+			t.Errorf("ParsePointer(%q) = %q, %v, want %q, %v", tt.ref, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParsePathRef(t *testing.T) {
+	tests := []struct {
+		ref, path, method string
+		ok                bool
+	}{
+		{"#/paths/~1users~1{id}/get", "/users/{id}", "get", true},
+		{"#/paths/~1users~1%7Bid%7D", "/users/{id}", "", true},
+		{"#/paths/~1users/get/responses", "", "", false},
+		{"#/components/schemas/Pet", "", "", false},
+	}
+	for _, tt := range tests {
+		path, method, ok := ParsePathRef(tt.ref)
+		if path != tt.path || method != tt.method || ok != tt.ok {
+			t.Errorf("ParsePathRef(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.ref, path, method, ok, tt.path, tt.method, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeRef(t *testing.T) {
+	tests := []struct{ ref, want string }{
+		{"#/components/schemas/Pet", "#/components/schemas/Pet"},
+		{"#/components/schemas/a~1b", "#/components/schemas/a~1b"},
+		{"#/components/schemas/Pet%20Info", "#/components/schemas/Pet Info"},
+		{"#/paths/~1users", "#/paths/~1users"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeRef(tt.ref); got != tt.want {
+			t.Errorf("NormalizeRef(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+	if got := FormatPointer("paths", "/users/{id}", "get"); got != "/paths/~1users~1{id}/get" {
+		t.Errorf("FormatPointer = %q, want /paths/~1users~1{id}/get", got)
+	}
+}
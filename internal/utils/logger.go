@@ -2,55 +2,60 @@ package utils
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"strings"
-
-	"go.uber.org/zap"
 
 	"github.com/zguydev/openapi-filter/pkg/config"
 )
 
-func NewFallbackLogger() *zap.Logger {
-	var zapCfg zap.Config
-	switch strings.ToLower(os.Getenv("APP_ENV")) {
-	case "production", "prod":
-		zapCfg = zap.NewProductionConfig()
-	default:
-		zapCfg = zap.NewDevelopmentConfig()
-	}
-	zapCfg.Level = zap.NewAtomicLevelAt(zap.ErrorLevel)
+// NewFallbackLogger creates the logger used before the config is loaded.
+// It only reports errors, as text on stderr.
+func NewFallbackLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+}
 
-	logger, err := zapCfg.Build()
+// NewLogger creates the tool logger from the config. A nil config or unset
+// fields default to info level, text format and stderr output.
+func NewLogger(cfg *config.LoggerConfig) (*slog.Logger, error) {
+	level, err := cfg.SlogLevel()
 	if err != nil {
-		panic(fmt.Errorf("failed to create fallback logger: %w", err))
+		return nil, fmt.Errorf("cfg.SlogLevel: %w", err)
+	}
+	format, err := cfg.FormatOrDefault()
+	if err != nil {
+		return nil, fmt.Errorf("cfg.FormatOrDefault: %w", err)
+	}
+	w, err := openLogOutput(cfg.OutputOrDefault())
+	if err != nil {
+		return nil, fmt.Errorf("openLogOutput: %w", err)
 	}
-	return logger
-}
-
-func NewLogger(cfg *config.LoggerConfig) (*zap.Logger, error) {
-    // Default to info if cfg is nil or level is empty
-    level := zap.NewAtomicLevelAt(zap.InfoLevel)
-    if cfg != nil && cfg.Level != "" {
-        parsed, err := zap.ParseAtomicLevel(cfg.Level)
-        if err != nil {
-            return nil, fmt.Errorf("wrong logger level in config: %w", err)
-        }
-        level = parsed
-    }
 
-	var zapCfg zap.Config
-	switch strings.ToLower(os.Getenv("APP_ENV")) {
-	case "production", "prod":
-		zapCfg = zap.NewProductionConfig()
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format {
+	case config.LoggerFormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
 	default:
-		zapCfg = zap.NewDevelopmentConfig()
+		handler = slog.NewTextHandler(w, opts)
 	}
-	zapCfg.Level = level
-	zapCfg.OutputPaths = []string{"stdout"}
+	return slog.New(handler), nil
+}
 
-    logger, err := zapCfg.Build()
-    if err != nil {
-        return nil, fmt.Errorf("zapCfg.Build: %w", err)
-    }
-    return logger, nil
+// openLogOutput returns the writer for a log output: stderr, stdout or a file
+// path, which is created if needed and appended to.
+func openLogOutput(output string) (io.Writer, error) {
+	switch output {
+	case config.LoggerOutputStderr:
+		return os.Stderr, nil
+	case config.LoggerOutputStdout:
+		return os.Stdout, nil
+	}
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("os.OpenFile: %w", err)
+	}
+	return f, nil
 }
@@ -0,0 +1,110 @@
+package walk
+
+import "reflect"
+
+// Clone returns a deep copy of root. Like Walk, it follows exported fields,
+// maps, slices and interfaces, and copies map-like types through their Map
+// and Set methods. Pointers shared within root are shared in the copy, so
+// refs keep pointing to the same values, and recursive values are handled.
+// Other unexported fields are copied shallowly.
+func Clone[T any](root T) T {
+	c := &cloner{copies: make(map[pointerKey]reflect.Value)}
+	v := reflect.ValueOf(&root).Elem()
+	out := reflect.New(v.Type()).Elem()
+	out.Set(c.clone(v))
+	return out.Interface().(T)
+}
+
+type cloner struct {
+	copies map[pointerKey]reflect.Value
+}
+
+// pointerKey identifies a copied pointer. The type is part of the key, as a
+// struct and its first field share the same address.
+type pointerKey struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		key := pointerKey{v.Type(), v.Pointer()}
+		if cp, ok := c.copies[key]; ok {
+			return cp
+		}
+		cp := reflect.New(v.Type().Elem())
+		c.copies[key] = cp
+		if !c.cloneMapLike(v, cp) {
+			cp.Elem().Set(c.clone(v.Elem()))
+		}
+		return cp
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(c.clone(v.Elem()))
+		return cp
+	case reflect.Struct:
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v) // Copies unexported fields shallowly
+		c.cloneFields(v, cp)
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), c.clone(iter.Value()))
+		}
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return cp
+	case reflect.Array:
+		cp := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return cp
+	default:
+		return v
+	}
+}
+
+// cloneMapLike copies a map-like value, which keeps its entries in an
+// unexported map, into the zero value cp through its Map and Set methods.
+func (c *cloner) cloneMapLike(v, cp reflect.Value) bool {
+	m, set := v.MethodByName("Map"), cp.MethodByName("Set")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 ||
+		!set.IsValid() || set.Type().NumIn() != 2 {
+		return false
+	}
+	c.cloneFields(v.Elem(), cp.Elem())
+	iter := m.Call(nil)[0].MapRange()
+	for iter.Next() {
+		set.Call([]reflect.Value{iter.Key(), c.clone(iter.Value())})
+	}
+	return true
+}
+
+func (c *cloner) cloneFields(v, cp reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			cp.Field(i).Set(c.clone(v.Field(i)))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package walk
+
+import "reflect"
+
+// Visitor is called for every pointer and struct value reachable from the
+// walked root. Returning false stops the walk from descending into the value.
+type Visitor func(v reflect.Value) bool
+
+// Walk visits all values reachable from root through exported fields, maps,
+// slices and interfaces. Each pointer is visited once, so recursive schemas
+// are handled. Map-like types (Paths, Responses, Callback), which keep their
+// entries in an unexported map, are walked through their Map method.
+func Walk(root any, visit Visitor) {
+	w := &walker{
+		visit:   visit,
+		visited: make(map[uintptr]struct{}),
+	}
+	w.walk(reflect.ValueOf(root))
+}
+
+type walker struct {
+	visit   Visitor
+	visited map[uintptr]struct{}
+}
+
+func (w *walker) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		if _, ok := w.visited[v.Pointer()]; ok {
+			return
+		}
+		w.visited[v.Pointer()] = struct{}{}
+		if !w.visit(v) {
+			return
+		}
+		w.walkMapLike(v)
+		w.walk(v.Elem())
+	case reflect.Interface:
+		if !v.IsNil() {
+			w.walk(v.Elem())
+		}
+	case reflect.Struct:
+		if !w.visit(v) {
+			return
+		}
+		w.walkStruct(v)
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			w.walk(iter.Value())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			w.walk(v.Index(i))
+		}
+	}
+}
+
+func (w *walker) walkMapLike(v reflect.Value) {
+	m := v.MethodByName("Map")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return
+	}
+	w.walk(m.Call(nil)[0])
+}
+
+func (w *walker) walkStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.IsExported() {
+			w.walk(v.Field(i))
+		}
+	}
+}
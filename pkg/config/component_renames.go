@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// componentNamePattern matches the component names allowed by OpenAPI.
+var componentNamePattern = regexp.MustCompile(`^[a-zA-Z0-9.\-_]+$`)
+
+// renamableComponents are the component families ComponentRenames can rename.
+var renamableComponents = []string{
+	"schemas", "parameters", "headers", "requestBodies", "responses",
+	"securitySchemes", "examples", "links", "callbacks",
+}
+
+// ParseComponentRename parses a ComponentRenames key, the component family
+// and name separated by a slash, e.g. "schemas/User".
+func ParseComponentRename(key string) (def, name string, err error) {
+	def, name, ok := strings.Cut(key, "/")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid component %q, expected family/name, e.g. schemas/User", key)
+	}
+	if !slices.Contains(renamableComponents, def) {
+		return "", "", fmt.Errorf("unknown component family %q, expected one of: %s", def, strings.Join(renamableComponents, ", "))
+	}
+	return def, name, nil
+}
+
+// validateComponentRenames reports the invalid keys and new names of
+// ComponentRenames, and the components of a family renamed to the same name.
+func (fc *FilterConfig) validateComponentRenames() error {
+	var errs []error
+	renamed := make(map[string]string) // Qualified new name to key
+	for _, key := range slices.Sorted(maps.Keys(fc.ComponentRenames)) {
+		def, _, err := ParseComponentRename(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		newName := fc.ComponentRenames[key]
+		if !componentNamePattern.MatchString(newName) {
+			errs = append(errs, fmt.Errorf("%s: invalid component name %q", key, newName))
+			continue
+		}
+		if other, ok := renamed[def+"/"+newName]; ok {
+			errs = append(errs, fmt.Errorf("%s: renamed to %s like %s", key, newName, other))
+			continue
+		}
+		renamed[def+"/"+newName] = key
+	}
+	return errors.Join(errs...)
+}
@@ -5,31 +5,425 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	pathpkg "path"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-viper/mapstructure/v2"
 )
 
+// ToolConfigKey is the key holding tool-specific configuration. It is also
+// the vendor extension name that is never kept in filtered specs.
+const ToolConfigKey = "x-openapi-filter"
+
 // Config represents the root configuration structure for the OpenAPI filter tool.
-// It combines tool-specific settings with filter configuration.
+// It combines tool-specific settings with filter configuration, or with the
+// filter configurations of several outputs.
 type Config struct {
-	Tool         ToolConfig `koanf:"x-openapi-filter"`
-	FilterConfig `koanf:",squash"`
+	Tool          ToolConfig              `koanf:"x-openapi-filter"` // Must match ToolConfigKey
+	Outputs       map[string]FilterConfig `koanf:"outputs"`          // Filter configuration by output file name, see OutputConfig
+	MethodPresets MethodPresets           `koanf:"methodPresets"`    // Method lists referenced by name from path configs
+	PathPresets   PathPresets             `koanf:"pathPresets"`      // Path configs referenced by name from path configs
+	FilterConfig  `koanf:",squash"`
+
+	removedDuplicates []string // See RemovedDuplicates
 }
 
 // FilterConfig defines the configuration for filtering an OpenAPI spec.
 // It specifies which parts of the spec should be included in the output.
+//
+// Servers are governed at two independent levels: root servers are kept when
+// Servers is set, while path-level servers are kept according to
+// PreservePathServers, which can be overridden per path by
+// PathConfig.PreserveServers. Operation-level servers are part of the
+// operation and kept with it. When Servers lists URL patterns, only the
+// matching server entries are kept at every level.
 type FilterConfig struct {
-	Servers            bool                    `koanf:"servers"`             // Include servers section
-	PreservePathServers bool                   `koanf:"preservePathServers"` // Preserve path-level servers (default: false)
-	Paths              map[string]PathConfig   `koanf:"paths"`               // Map of paths to path configuration
-	Components         *FilterComponentsConfig `koanf:"components"`          // Component filtering configuration
-	Security           bool                    `koanf:"security"`            // Include security requirements
-	Tags               bool                    `koanf:"tags"`                // Include tags
-	ExternalDocs       bool                    `koanf:"externalDocs"`         // Include external documentation
+	Servers             ServersConfig           `koanf:"servers"`             // Include servers section, optionally only matching URLs
+	PreservePathServers bool                    `koanf:"preservePathServers"` // Preserve path-level servers (default: false)
+	ServersByExtension  map[string]any          `koanf:"serversByExtension"`  // Keep only servers carrying all of these extension values
+	Paths               map[string]PathConfig   `koanf:"paths"`               // Map of paths to path configuration
+	DefaultPath         *PathConfig             `koanf:"defaultPath"`         // Path configuration of the spec paths missing from Paths
+	ExcludePaths        []string                `koanf:"excludePaths"`        // Paths to drop, glob patterns allowed, wins over any selection
+	Components          *FilterComponentsConfig `koanf:"components"`          // Component filtering configuration, nil keeps all components
+	Security            bool                    `koanf:"security"`            // Include security requirements
+	Tags                TagsConfig              `koanf:"tags"`                // Include tag definitions, optionally only the listed ones
+	PruneTags           bool                    `koanf:"pruneTags"`           // Drop tag definitions no kept operation references
+	ExternalDocs        bool                    `koanf:"externalDocs"`        // Include external documentation
+	SetExternalDocs     *ExternalDocsConfig     `koanf:"setExternalDocs"`     // Override top-level external documentation
+	Info                *InfoConfig             `koanf:"info"`                // Strip or override fields of the info object
+	Rules               []SelectionRule         `koanf:"rules"`               // Rules selecting operations across paths
+
+	IncludeOperationIds []string `koanf:"includeOperationIds"` // Operations to keep by operationId, on any path
+	ExcludeOperationIds []string `koanf:"excludeOperationIds"` // Operations to drop by operationId, wins over any selection
+	IncludeLinkTargets  bool     `koanf:"includeLinkTargets"`  // Also keep the operations targeted by links of kept responses
+
+	ExcludeParameterNames     []string `koanf:"excludeParameterNames"`     // Parameters to strip, as "name" or "name:in"
+	ExcludeParameterLocations []string `koanf:"excludeParameterLocations"` // Parameter locations to strip: "query", "header" or "cookie"
+	PrunePathParameters       bool     `koanf:"prunePathParameters"`       // Drop path-level parameters overridden by every kept operation
+
+	ExcludeByExtension        map[string]any `koanf:"excludeByExtension"`        // Drop operations carrying any of these extension values
+	ExcludeSchemasByExtension bool           `koanf:"excludeSchemasByExtension"` // Also drop schemas matching ExcludeByExtension
+
+	OperationOverrides *OperationOverridesConfig `koanf:"operationOverrides"` // Decisions declared by operations in their x-openapi-filter extension
+
+	VersionGate *VersionGateConfig `koanf:"versionGate"` // Keep operations by the version they were added in
+
+	StripExtensions []string `koanf:"stripExtensions"` // Vendor extensions to strip, glob patterns allowed
+	KeepExtensions  []string `koanf:"keepExtensions"`  // Vendor extensions to keep, glob patterns allowed
+
+	CollapseUnions    CollapseStrategy `koanf:"collapseUnions"`    // Collapse anyOf/oneOf to a single branch
+	DedupSchemas      bool             `koanf:"dedupSchemas"`      // Merge structurally equal component schemas
+	ExcludeEnumValues map[string][]any `koanf:"excludeEnumValues"` // Enum values removed from component schemas, by schema name
+
+	OnlyRequiredProperties bool `koanf:"onlyRequiredProperties"` // Drop the properties of schemas that are not required
+
+	StripExamples           bool `koanf:"stripExamples"`           // Drop examples of media types, parameters and headers
+	MaxExamplesPerMediaType int  `koanf:"maxExamplesPerMediaType"` // Keep at most this many examples per media type, 0 keeps all
+
+	StripDescriptions bool `koanf:"stripDescriptions"` // Clear description fields throughout the filtered spec
+	StripSummaries    bool `koanf:"stripSummaries"`    // Clear summary fields throughout the filtered spec
+
+	RequireResponseMediaType string `koanf:"requireResponseMediaType"` // Drop operations without a 2xx response of this media type
+	KeepOnlyPrimaryResponse  bool   `koanf:"keepOnlyPrimaryResponse"`  // Keep only the lowest 2xx response, else default, of each operation
+
+	ReadOnly bool `koanf:"readOnly"` // Keep only GET, HEAD and OPTIONS operations, without request bodies, within the selected paths
+
+	RequireSecurityScheme []string `koanf:"requireSecurityScheme"` // Keep only operations whose effective security uses one of these schemes
+	IncludeUnsecured      bool     `koanf:"includeUnsecured"`      // Keep operations without security when RequireSecurityScheme is set
+
+	IncludeMediaTypes []string          `koanf:"includeMediaTypes"` // Media types kept in request bodies and responses, glob patterns allowed
+	ExcludeMediaTypes []string          `koanf:"excludeMediaTypes"` // Media types dropped from request bodies and responses, wins over IncludeMediaTypes
+	MediaTypeRewrites map[string]string `koanf:"mediaTypeRewrites"` // Media types renamed in the content of the filtered spec, by source media type
+
+	StampProvenance *ProvenanceConfig `koanf:"stampProvenance"` // Stamp kept operations with a provenance extension
+
+	PathPrefix     string   `koanf:"pathPrefix"`     // Prefix prepended to every path of the filtered spec, e.g. "/api/v1"
+	RemovePointers []string `koanf:"removePointers"` // JSON Pointers of values removed from the filtered spec, last
+
+	LatestVersionOnly bool   `koanf:"latestVersionOnly"` // Keep only the highest version of versioned paths
+	VersionPattern    string `koanf:"versionPattern"`    // Version segment regexp, the first group is the version number
+
+	ValidateOutput     bool `koanf:"validateOutput"`     // Validate the filtered spec, failing the filtering if invalid
+	FailOnEmpty        bool `koanf:"failOnEmpty"`        // Fail the filtering if no operation and no component is kept
+	FailOnDanglingRefs bool `koanf:"failOnDanglingRefs"` // Fail the filtering if a kept operation references a missing component
+	FailOnWarnings     bool `koanf:"failOnWarnings"`     // Fail the filtering if any warning is reported, listing them all
+
+	MaxOperationsPerTag map[string]int `koanf:"maxOperationsPerTag"` // Max kept operations per tag, failing the filtering if exceeded, "*" for the tags not listed
+
+	OutputVersion     string `koanf:"outputVersion"`     // OpenAPI version of the filtered spec, "3.0" or "3.1", optionally with a patch version
+	DereferenceOutput bool   `koanf:"dereferenceOutput"` // Inline the component refs of the filtered spec, keeping refs closing a cycle
+
+	ComponentRenames map[string]string `koanf:"componentRenames"` // New names of components of the filtered spec, keyed by family/name, e.g. "schemas/User"
+}
+
+// DefaultVersionPattern matches the version segment of paths like "/v2/users"
+// when VersionPattern is unset.
+const DefaultVersionPattern = `/v(\d+)/`
+
+// CollapseStrategy selects the branch a union (anyOf/oneOf) is collapsed to.
+type CollapseStrategy string
+
+const (
+	// CollapseUnionsFirst collapses a union to its first branch.
+	CollapseUnionsFirst CollapseStrategy = "first"
+	// CollapseUnionsByDiscriminator collapses a union to the branch targeted by
+	// the first discriminator mapping (in key order), falling back to the
+	// first branch when the schema has no usable discriminator mapping.
+	CollapseUnionsByDiscriminator CollapseStrategy = "byDiscriminator"
+)
+
+// HasExtensionRules reports whether any vendor extension filtering is configured.
+func (fc *FilterConfig) HasExtensionRules() bool {
+	return len(fc.StripExtensions) != 0 || len(fc.KeepExtensions) != 0
+}
+
+// IsExtensionStripped reports whether the vendor extension with the given key
+// should be removed from the filtered spec. KeepExtensions takes precedence
+// over StripExtensions; when only KeepExtensions is set, every other extension
+// is stripped. The tool's own extension is always stripped.
+func (fc *FilterConfig) IsExtensionStripped(key string) bool {
+	if key == ToolConfigKey {
+		return true
+	}
+	if matchAny(fc.KeepExtensions, key) {
+		return false
+	}
+	if len(fc.StripExtensions) == 0 {
+		return len(fc.KeepExtensions) != 0
+	}
+	return matchAny(fc.StripExtensions, key)
+}
+
+// MatchExcludedExtension returns the key of the first ExcludeByExtension entry,
+// in key order, whose value equals the value of the same extension in exts.
+// Booleans, strings and numbers are compared by value, so an integer in the
+// config matches the same number in the spec.
+func (fc *FilterConfig) MatchExcludedExtension(exts map[string]any) (key string, ok bool) {
+	return matchExtensionValues(fc.ExcludeByExtension, exts)
+}
+
+// MatchesServerExtensions reports whether the server extensions carry every
+// ServersByExtension value, compared like ExcludeByExtension values. Every
+// server matches when ServersByExtension is empty.
+func (fc *FilterConfig) MatchesServerExtensions(exts map[string]any) bool {
+	for key, want := range fc.ServersByExtension {
+		if value, ok := exts[key]; !ok || !extensionValueEqual(want, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEnumValueExcluded reports whether the enum value is listed in the
+// ExcludeEnumValues of the schema. Values are compared like ExcludeByExtension
+// values.
+func (fc *FilterConfig) IsEnumValueExcluded(schema string, value any) bool {
+	return slices.ContainsFunc(fc.ExcludeEnumValues[schema], func(excluded any) bool {
+		return extensionValueEqual(excluded, value)
+	})
+}
+
+// extensionValueEqual reports whether two boolean, string or number extension
+// values are equal. Values of other types never match.
+func extensionValueEqual(want, got any) bool {
+	switch want := want.(type) {
+	case bool:
+		got, ok := got.(bool)
+		return ok && want == got
+	case string:
+		got, ok := got.(string)
+		return ok && want == got
+	}
+	wantNum, ok := toFloat(want)
+	if !ok {
+		return false
+	}
+	gotNum, ok := toFloat(got)
+	return ok && wantNum == gotNum
+}
+
+// toFloat converts a number of any kind to float64.
+func toFloat(v any) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// IsParameterExcluded reports whether a parameter with the given name and
+// location is listed in ExcludeParameterNames. Entries are either a bare name,
+// matching the parameter in any location, or "name:in" (e.g. "X-Debug:header").
+// Header names are matched case-insensitively. Path parameters are never
+// excluded, as path templates reference them.
+func (fc *FilterConfig) IsParameterExcluded(name, in string) bool {
+	if strings.EqualFold(in, openapi3.ParameterInPath) {
+		return false
+	}
+	for _, entry := range fc.ExcludeParameterNames {
+		exName, exIn, hasIn := strings.Cut(entry, ":")
+		if hasIn && !strings.EqualFold(exIn, in) {
+			continue
+		}
+		if exName == name || (strings.EqualFold(in, "header") && strings.EqualFold(exName, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsParameterLocationExcluded reports whether the parameter location is
+// listed in ExcludeParameterLocations, ignoring case.
+func (fc *FilterConfig) IsParameterLocationExcluded(in string) bool {
+	return slices.ContainsFunc(fc.ExcludeParameterLocations, func(location string) bool {
+		return strings.EqualFold(location, in)
+	})
+}
+
+// SelectionRule selects operations across all paths of the spec. All criteria
+// set within a rule must match (intersection), while an operation is kept if
+// it matches any of the rules (union). Unset criteria match everything.
+type SelectionRule struct {
+	Prefix  string   `koanf:"prefix"`  // Path prefix to match, e.g. "/v2"
+	Glob    string   `koanf:"glob"`    // Path glob pattern to match, e.g. "/v2/*/items"
+	Methods []string `koanf:"methods"` // HTTP methods to match
+	Tags    []string `koanf:"tags"`    // Operation tags to match, any of them is enough
+}
+
+// Matches reports whether an operation with the given path, method and tags
+// is selected by the rule. A malformed glob pattern never matches.
+func (r SelectionRule) Matches(path, method string, tags []string) bool {
+	if r.Prefix != "" && !strings.HasPrefix(path, r.Prefix) {
+		return false
+	}
+	if r.Glob != "" {
+		if ok, err := pathpkg.Match(r.Glob, path); err != nil || !ok {
+			return false
+		}
+	}
+	if len(r.Methods) != 0 && !slices.ContainsFunc(r.Methods, func(m string) bool {
+		return strings.EqualFold(m, method)
+	}) {
+		return false
+	}
+	if len(r.Tags) != 0 && !slices.ContainsFunc(r.Tags, func(t string) bool {
+		return slices.Contains(tags, t)
+	}) {
+		return false
+	}
+	return true
+}
+
+// ExternalDocsConfig defines a top-level externalDocs object that is set on the
+// filtered spec regardless of the source spec. When set, it takes precedence
+// over the ExternalDocs flag.
+type ExternalDocsConfig struct {
+	URL         string `koanf:"url"`         // URL of the external documentation
+	Description string `koanf:"description"` // Optional description of the documentation
+}
+
+// InfoFields are the optional fields of the info object that InfoConfig can
+// keep or drop. The required title and version are always kept.
+var InfoFields = []string{"description", "termsOfService", "contact", "license"}
+
+// InfoConfig strips or overrides fields of the info object of the filtered
+// spec. Include and Exclude list field names of InfoFields and vendor
+// extensions, glob patterns allowed. Overrides replace the source values.
+// Extensions kept here are still subject to StripExtensions and
+// KeepExtensions, which apply to the whole spec afterwards.
+type InfoConfig struct {
+	Include     []string `koanf:"include"`     // Fields and extensions to keep, all when empty
+	Exclude     []string `koanf:"exclude"`     // Fields and extensions to drop, wins over Include
+	Title       string   `koanf:"title"`       // Title override, e.g. a public API name
+	Description string   `koanf:"description"` // Description override
+	Version     string   `koanf:"version"`     // Version override
+	BumpVersion string   `koanf:"bumpVersion"` // Semver part of the source version to bump: major, minor or patch
+}
+
+// Semver parts InfoConfig.BumpVersion can bump.
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+// KeepsField reports whether the info field or extension name is kept.
+func (ic *InfoConfig) KeepsField(name string) bool {
+	if matchAny(ic.Exclude, name) {
+		return false
+	}
+	return len(ic.Include) == 0 || matchAny(ic.Include, name)
+}
+
+// Validate reports unknown field names and malformed patterns.
+func (ic *InfoConfig) Validate() error {
+	var errs []error
+	for _, name := range slices.Concat(ic.Include, ic.Exclude) {
+		if !strings.HasPrefix(name, "x-") && !slices.Contains(InfoFields, name) {
+			errs = append(errs, fmt.Errorf("unknown info field %s, expected one of %s or an x- extension",
+				name, strings.Join(InfoFields, ", ")))
+		}
+	}
+	if err := validatePatterns(slices.Concat(ic.Include, ic.Exclude)); err != nil {
+		errs = append(errs, err)
+	}
+	switch ic.BumpVersion {
+	case "", BumpMajor, BumpMinor, BumpPatch:
+	default:
+		errs = append(errs, fmt.Errorf("bumpVersion: unknown version part %s, expected one of %s, %s, %s",
+			ic.BumpVersion, BumpMajor, BumpMinor, BumpPatch))
+	}
+	if ic.Version != "" && ic.BumpVersion != "" {
+		errs = append(errs, errors.New("version and bumpVersion are mutually exclusive"))
+	}
+	return errors.Join(errs...)
+}
+
+// BumpedVersion returns version with the BumpVersion part incremented and
+// the lower parts reset, e.g. "1.2.3" bumped by minor is "1.3.0". The version
+// must be a full MAJOR.MINOR.PATCH semantic version, with an optional "v"
+// prefix, which is kept. Prerelease and build metadata are dropped.
+func (ic *InfoConfig) BumpedVersion(version string) (string, error) {
+	v, err := parseSemver(version)
+	if err != nil {
+		return "", err
+	}
+	core, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "+")
+	core, _, _ = strings.Cut(core, "-")
+	if v == nil || strings.Count(core, ".") != len(v.numbers)-1 {
+		return "", fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", version)
+	}
+
+	major, minor, patch := v.numbers[0], v.numbers[1], v.numbers[2]
+	switch ic.BumpVersion {
+	case BumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case BumpMinor:
+		minor, patch = minor+1, 0
+	case BumpPatch:
+		patch++
+	}
+	prefix := ""
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, major, minor, patch), nil
+}
+
+// Default provenance stamp, used for the unset fields of ProvenanceConfig.
+const (
+	DefaultProvenanceKey   = "x-filtered-by"
+	DefaultProvenanceValue = "openapi-filter"
+)
+
+// ProvenanceConfig defines the vendor extension stamped on every operation
+// kept in the filtered spec. The timestamp is only added when TimestampKey is
+// set, so the output stays deterministic by default.
+type ProvenanceConfig struct {
+	Key          string `koanf:"key"`          // Extension key, defaults to "x-filtered-by"
+	Value        string `koanf:"value"`        // Extension value, e.g. the config name, defaults to "openapi-filter"
+	TimestampKey string `koanf:"timestampKey"` // Extension key for the filtering time (RFC 3339), e.g. "x-filtered-at"
+}
+
+// KeyOrDefault returns the configured extension key or DefaultProvenanceKey.
+func (pc *ProvenanceConfig) KeyOrDefault() string {
+	if pc.Key == "" {
+		return DefaultProvenanceKey
+	}
+	return pc.Key
+}
+
+// ValueOrDefault returns the configured extension value or DefaultProvenanceValue.
+func (pc *ProvenanceConfig) ValueOrDefault() string {
+	if pc.Value == "" {
+		return DefaultProvenanceValue
+	}
+	return pc.Value
 }
 
 // FilterComponentsConfig specifies which components should be included in the
-// filtered OpenAPI spec. Each field is a list of component names to include.
+// filtered OpenAPI spec. Each field is a list of component names to include,
+// or glob patterns: ComponentWildcard ("*") includes the whole family. An
+// empty list includes none, though components referenced by kept operations
+// are always included. A nil FilterConfig.Components includes all components
+// of every family.
 type FilterComponentsConfig struct {
 	Schemas         []string `koanf:"schemas"`         // List of schema names to include
 	Parameters      []string `koanf:"parameters"`      // List of parameter names to include
@@ -40,6 +434,12 @@ type FilterComponentsConfig struct {
 	Examples        []string `koanf:"examples"`        // List of example names to include
 	Links           []string `koanf:"links"`           // List of link names to include
 	Callbacks       []string `koanf:"callbacks"`       // List of callback names to include
+	PathItems       []string `koanf:"pathItems"`       // List of reusable path item names to include (OpenAPI 3.1)
+
+	// PruneExplicitlyListedIfUnreferenced prunes listed components that are
+	// referenced only by operations excluded from the filtered spec.
+	// Listed components not referenced by any operation are still kept.
+	PruneExplicitlyListedIfUnreferenced bool `koanf:"pruneExplicitlyListedIfUnreferenced"`
 }
 
 // ToolConfig contains tool-specific configuration settings.
@@ -48,42 +448,212 @@ type ToolConfig struct {
 	Loader *LoaderConfig `koanf:"loader"` // OpenAPI loader configuration
 }
 
+// Validate reports an error if the tool configuration is invalid.
+func (tc ToolConfig) Validate() error {
+	if tc.Logger != nil {
+		if err := tc.Logger.Validate(); err != nil {
+			return fmt.Errorf("logger: %w", err)
+		}
+	}
+	return nil
+}
+
 // LoggerConfig defines the logging configuration for the tool.
+// Unset fields default to info level, text format and stderr output.
 type LoggerConfig struct {
-	Level string `koanf:"level"` // Log level (e.g., "debug", "info", "warn", "error")
+	Level  string `koanf:"level"`  // Log level (e.g., "debug", "info", "warn", "error")
+	Format string `koanf:"format"` // Log format: "text" or "json"
+	Output string `koanf:"output"` // Log output: "stderr", "stdout" or a file path
+}
+
+// Logger formats and outputs, other outputs are file paths.
+const (
+	LoggerFormatText   = "text"
+	LoggerFormatJSON   = "json"
+	LoggerOutputStderr = "stderr"
+	LoggerOutputStdout = "stdout"
+)
+
+var (
+	ErrInvalidLoggerLevel  = errors.New("invalid log level")
+	ErrInvalidLoggerFormat = errors.New("invalid log format")
+)
+
+// Validate reports an error if the level or format is not supported.
+func (lc *LoggerConfig) Validate() error {
+	if _, err := lc.SlogLevel(); err != nil {
+		return err
+	}
+	if _, err := lc.FormatOrDefault(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SlogLevel returns the configured log level, defaulting to info.
+func (lc *LoggerConfig) SlogLevel() (slog.Level, error) {
+	if lc == nil || lc.Level == "" {
+		return slog.LevelInfo, nil
+	}
+	switch strings.ToLower(lc.Level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf(`%w %q, expected one of "debug", "info", "warn", "error"`,
+		ErrInvalidLoggerLevel, lc.Level)
+}
+
+// FormatOrDefault returns the configured log format, defaulting to text.
+func (lc *LoggerConfig) FormatOrDefault() (string, error) {
+	if lc == nil || lc.Format == "" {
+		return LoggerFormatText, nil
+	}
+	switch format := strings.ToLower(lc.Format); format {
+	case LoggerFormatText, LoggerFormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf(`%w %q, expected "text" or "json"`, ErrInvalidLoggerFormat, lc.Format)
+}
+
+// OutputOrDefault returns the configured log output, defaulting to stderr.
+func (lc *LoggerConfig) OutputOrDefault() string {
+	if lc == nil || lc.Output == "" {
+		return LoggerOutputStderr
+	}
+	return lc.Output
 }
 
 // LoaderConfig defines configuration for the OpenAPI spec loader.
 type LoaderConfig struct {
-	IsExternalRefsAllowed bool `koanf:"external_refs_allowed"` // Whether to allow external references
+	IsExternalRefsAllowed bool          `koanf:"external_refs_allowed"`  // Whether to allow refs to remote documents, refs to local files are always allowed
+	MaxConcurrentFetches  int           `koanf:"max_concurrent_fetches"` // Max external ref documents fetched concurrently, defaults to 8
+	CacheDir              string        `koanf:"cache_dir"`              // Directory caching remote ref documents across runs, disabled if empty
+	CacheTTL              time.Duration `koanf:"cache_ttl"`              // Age under which cached documents are used without revalidation
+	MaxSpecBytes          int64         `koanf:"max_spec_bytes"`         // Max bytes read for the spec and its external ref documents, unlimited if 0
+	FetchRetries          int           `koanf:"fetch_retries"`          // Retries of a remote ref document fetch failing with a 5xx or connection error
+	FetchRetryBackoff     time.Duration `koanf:"fetch_retry_backoff"`    // Delay before the first retry, doubled after each retry, defaults to 500ms
+	HTTPTimeout           time.Duration `koanf:"http_timeout"`           // Timeout of each HTTP fetch, of remote ref documents or configs, defaults to 30s
+	AllowedHosts          []string      `koanf:"allowed_hosts"`          // Host glob patterns remote documents may be fetched from, any host if empty
+}
+
+// DefaultHTTPTimeout is the timeout of HTTP fetches when
+// LoaderConfig.HTTPTimeout is unset.
+const DefaultHTTPTimeout = 30 * time.Second
+
+// HTTPTimeoutOrDefault returns the configured HTTP timeout or
+// DefaultHTTPTimeout. lc may be nil.
+func (lc *LoaderConfig) HTTPTimeoutOrDefault() time.Duration {
+	if lc == nil || lc.HTTPTimeout <= 0 {
+		return DefaultHTTPTimeout
+	}
+	return lc.HTTPTimeout
+}
+
+// IsHostAllowed reports whether remote documents may be fetched from the
+// host, without port, e.g. "api.example.com". Hosts are matched ignoring
+// case. Every host is allowed when AllowedHosts is empty or lc is nil.
+func (lc *LoaderConfig) IsHostAllowed(host string) bool {
+	if lc == nil || len(lc.AllowedHosts) == 0 {
+		return true
+	}
+	return matchAny(lc.AllowedHosts, strings.ToLower(host))
 }
 
 // PathConfig defines configuration for a single API path.
 // It supports both simple format (array of methods) and advanced format (object with methods and preserveServers).
+// It can also be a "$name" reference to a method preset or a path preset, see PathPresets.
 type PathConfig struct {
-	Methods         []string `koanf:"methods"`         // List of HTTP methods to include
-	PreserveServers bool     `koanf:"preserveServers"` // Whether to preserve path-level servers
+	Methods         []string                `koanf:"methods"`         // List of HTTP methods to include
+	PreserveServers *bool                   `koanf:"preserveServers"` // Whether to preserve path-level servers, overrides PreservePathServers
+	Responses       map[string][]string     `koanf:"responses"`       // Per-method list of response status codes to keep
+	Components      *FilterComponentsConfig `koanf:"components"`      // Components kept along with the path, in addition to the global ones
+	Preset          string                  `koanf:"-"`               // Name of the referenced path preset, resolved by Normalize
+}
+
+// ShouldPreserveServers reports whether path-level servers are kept for the
+// path. The path's PreserveServers, when set, overrides the global default.
+func (pc PathConfig) ShouldPreserveServers(globalDefault bool) bool {
+	if pc.PreserveServers != nil {
+		return *pc.PreserveServers
+	}
+	return globalDefault
+}
+
+// KeepsMethod reports whether the path config selects the operation of the
+// given method, listed in any case, by the "*" method or by a method group.
+// Unlike Normalize, it does not check that the path defines the method.
+func (pc PathConfig) KeepsMethod(method string) bool {
+	for _, m := range pc.Methods {
+		switch {
+		case m == MethodWildcard || strings.EqualFold(m, method):
+			return true
+		case isMethodGroup(m):
+			if group, err := methodGroup(m); err == nil && slices.Contains(group, strings.ToUpper(method)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ResponseCodes returns the response status codes to keep for the given method
+// and whether a response filter is configured for it. A method without a
+// response filter keeps all of its responses.
+func (pc PathConfig) ResponseCodes(method string) (codes []string, ok bool) {
+	codes, ok = pc.Responses[strings.ToLower(method)]
+	return codes, ok
+}
+
+// MarshalJSON implements custom JSON marshaling, symmetric with UnmarshalJSON:
+// the simple array format is used unless PreserveServers is set or responses
+// are filtered or components listed.
+func (pc PathConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pc.encode())
+}
+
+// MarshalYAML implements custom YAML marshaling, symmetric with UnmarshalYAML:
+// the simple array format is used unless PreserveServers is set or responses
+// are filtered or components listed.
+func (pc PathConfig) MarshalYAML() (interface{}, error) {
+	return pc.encode(), nil
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling to support both simple array format
 // (backward compatible) and advanced object format.
 func (pc *PathConfig) UnmarshalJSON(data []byte) error {
-	// Try to unmarshal as array first (simple format)
+	// A path preset reference
+	var ref string
+	if err := json.Unmarshal(data, &ref); err == nil && isPresetRef(ref) {
+		*pc = PathConfig{Preset: strings.TrimPrefix(ref, MethodPresetPrefix)}
+		return nil
+	}
+
+	// Try to unmarshal as array (simple format)
 	var methods []string
 	if err := json.Unmarshal(data, &methods); err == nil {
 		pc.Methods = methods
-		pc.PreserveServers = false
+		pc.PreserveServers = nil
 		return nil
 	}
 
 	// Try to unmarshal as object (advanced format)
 	var obj struct {
-		Methods         []string `json:"methods"`
-		PreserveServers bool     `json:"preserveServers"`
+		Methods         []string                `json:"methods"`
+		PreserveServers *bool                   `json:"preserveServers"`
+		Responses       map[string][]string     `json:"responses"`
+		Components      *FilterComponentsConfig `json:"components"`
 	}
 	if err := json.Unmarshal(data, &obj); err == nil {
 		pc.Methods = obj.Methods
 		pc.PreserveServers = obj.PreserveServers
+		pc.Responses = normalizeResponses(obj.Responses)
+		pc.Components = obj.Components
 		return nil
 	}
 
@@ -93,22 +663,33 @@ func (pc *PathConfig) UnmarshalJSON(data []byte) error {
 // UnmarshalYAML implements custom YAML unmarshaling to support both simple array format
 // (backward compatible) and advanced object format.
 func (pc *PathConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	// Try to unmarshal as array first (simple format)
+	// A path preset reference
+	var ref string
+	if err := unmarshal(&ref); err == nil && isPresetRef(ref) {
+		*pc = PathConfig{Preset: strings.TrimPrefix(ref, MethodPresetPrefix)}
+		return nil
+	}
+
+	// Try to unmarshal as array (simple format)
 	var methods []string
 	if err := unmarshal(&methods); err == nil {
 		pc.Methods = methods
-		pc.PreserveServers = false
+		pc.PreserveServers = nil
 		return nil
 	}
 
 	// Try to unmarshal as object (advanced format)
 	var obj struct {
-		Methods         []string `yaml:"methods"`
-		PreserveServers bool     `yaml:"preserveServers"`
+		Methods         []string                `yaml:"methods"`
+		PreserveServers *bool                   `yaml:"preserveServers"`
+		Responses       map[string][]string     `yaml:"responses"`
+		Components      *FilterComponentsConfig `yaml:"components"`
 	}
 	if err := unmarshal(&obj); err == nil {
 		pc.Methods = obj.Methods
 		pc.PreserveServers = obj.PreserveServers
+		pc.Responses = normalizeResponses(obj.Responses)
+		pc.Components = obj.Components
 		return nil
 	}
 
@@ -118,6 +699,12 @@ func (pc *PathConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 // DecodeMapstructure implements custom decoding for mapstructure (used by koanf).
 // This allows koanf to properly decode PathConfig from raw interface{} values.
 func (pc *PathConfig) DecodeMapstructure(from interface{}) error {
+	return pc.decode(from, nil)
+}
+
+// decode decodes a PathConfig like DecodeMapstructure, expanding the method
+// preset references with presets.
+func (pc *PathConfig) decode(from interface{}, presets MethodPresets) error {
 	if from == nil {
 		return fmt.Errorf("path config cannot be nil")
 	}
@@ -145,14 +732,39 @@ func (pc *PathConfig) DecodeMapstructure(from interface{}) error {
 				return fmt.Errorf("path config array element must be a string, got %v", elem.Kind())
 			}
 		}
+		expanded, err := presets.expand(methods)
+		if err != nil {
+			return err
+		}
+		pc.Methods = expanded
+		pc.PreserveServers = nil
+		return nil
+
+	case reflect.String:
+		// Simple format: a method preset reference, else a path preset
+		// reference resolved by Normalize
+		if !isPresetRef(val.String()) {
+			break
+		}
+		*pc = PathConfig{}
+		name := strings.TrimPrefix(val.String(), MethodPresetPrefix)
+		if _, ok := presets[name]; !ok {
+			pc.Preset = name
+			return nil
+		}
+		methods, err := presets.expand([]string{val.String()})
+		if err != nil {
+			return err
+		}
 		pc.Methods = methods
-		pc.PreserveServers = false
 		return nil
 
 	case reflect.Map:
 		// Advanced format: map with methods and optional preserveServers
 		pc.Methods = []string{}
-		pc.PreserveServers = false
+		pc.PreserveServers = nil
+		pc.Responses = nil
+		pc.Components = nil
 
 		iter := val.MapRange()
 		for iter.Next() {
@@ -183,22 +795,45 @@ func (pc *PathConfig) DecodeMapstructure(from interface{}) error {
 								return fmt.Errorf("methods array element must be a string, got %v", elem.Kind())
 							}
 						}
+						expanded, err := presets.expand(methods)
+						if err != nil {
+							return err
+						}
+						pc.Methods = expanded
+					} else if actualValue.Kind() == reflect.String && isPresetRef(actualValue.String()) {
+						methods, err := presets.expand([]string{actualValue.String()})
+						if err != nil {
+							return err
+						}
 						pc.Methods = methods
 					} else {
 						return fmt.Errorf("methods field must be an array, got %v", actualValue.Kind())
 					}
 				case "preserveServers":
 					if value.Kind() == reflect.Bool {
-						pc.PreserveServers = value.Bool()
+						preserve := value.Bool()
+						pc.PreserveServers = &preserve
 					} else if value.Kind() == reflect.Interface {
 						if b, ok := value.Interface().(bool); ok {
-							pc.PreserveServers = b
+							pc.PreserveServers = &b
 						} else {
 							return fmt.Errorf("preserveServers field must be a boolean, got %T", value.Interface())
 						}
 					} else {
 						return fmt.Errorf("preserveServers field must be a boolean, got %v", value.Kind())
 					}
+				case "responses":
+					responses, err := decodeResponses(value)
+					if err != nil {
+						return err
+					}
+					pc.Responses = responses
+				case "components":
+					components, err := decodePathComponents(value)
+					if err != nil {
+						return err
+					}
+					pc.Components = components
 				}
 			}
 		}
@@ -212,3 +847,111 @@ func (pc *PathConfig) DecodeMapstructure(from interface{}) error {
 
 	return fmt.Errorf("invalid path config format: expected array or object, got %v", val.Kind())
 }
+
+// matchAny reports whether name matches any of the glob patterns.
+// Malformed patterns never match.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, err := pathpkg.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeResponses lowercases method keys of a per-method response filter so
+// lookups are case-insensitive.
+func normalizeResponses(responses map[string][]string) map[string][]string {
+	if responses == nil {
+		return nil
+	}
+	normalized := make(map[string][]string, len(responses))
+	for method, codes := range responses {
+		normalized[strings.ToLower(method)] = codes
+	}
+	return normalized
+}
+
+// decodeResponses decodes a per-method response filter: a map of HTTP methods
+// to lists of response status codes to keep.
+func decodeResponses(from reflect.Value) (map[string][]string, error) {
+	if from.Kind() == reflect.Interface {
+		from = reflect.ValueOf(from.Interface())
+	}
+	if from.Kind() != reflect.Map {
+		return nil, fmt.Errorf("responses field must be an object, got %v", from.Kind())
+	}
+
+	responses := make(map[string][]string, from.Len())
+	iter := from.MapRange()
+	for iter.Next() {
+		method, ok := iter.Key().Interface().(string)
+		if !ok {
+			return nil, fmt.Errorf("responses key must be a string, got %T", iter.Key().Interface())
+		}
+		codes, err := decodeStatusCodes(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("responses for method %q: %w", method, err)
+		}
+		responses[strings.ToLower(method)] = codes
+	}
+	return responses, nil
+}
+
+// decodePathComponents decodes the components of a path config, which list
+// component names like the components section.
+func decodePathComponents(from reflect.Value) (*FilterComponentsConfig, error) {
+	if from.Kind() == reflect.Interface {
+		from = reflect.ValueOf(from.Interface())
+	}
+	if from.Kind() != reflect.Map {
+		return nil, fmt.Errorf("components field must be an object, got %v", from.Kind())
+	}
+
+	cc := &FilterComponentsConfig{}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           cc,
+		TagName:          "koanf",
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mapstructure.NewDecoder: %w", err)
+	}
+	if err := decoder.Decode(from.Interface()); err != nil {
+		return nil, fmt.Errorf("components: %w", err)
+	}
+	if cc.PruneExplicitlyListedIfUnreferenced {
+		return nil, fmt.Errorf("components: pruneExplicitlyListedIfUnreferenced is only supported in the components section")
+	}
+	return cc, nil
+}
+
+// decodeStatusCodes decodes a list of response status codes. Codes may be
+// given as strings (e.g. "200", "4XX", "default") or as integers, since YAML
+// and TOML parsers decode unquoted codes as numbers.
+func decodeStatusCodes(from reflect.Value) ([]string, error) {
+	if from.Kind() == reflect.Interface {
+		from = reflect.ValueOf(from.Interface())
+	}
+	if from.Kind() != reflect.Slice && from.Kind() != reflect.Array {
+		return nil, fmt.Errorf("status codes must be an array, got %v", from.Kind())
+	}
+
+	codes := make([]string, from.Len())
+	for i := 0; i < from.Len(); i++ {
+		switch code := from.Index(i).Interface().(type) {
+		case string:
+			codes[i] = code
+		case int:
+			codes[i] = strconv.Itoa(code)
+		case int64:
+			codes[i] = strconv.FormatInt(code, 10)
+		case float64:
+			codes[i] = strconv.FormatFloat(code, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("status code must be a string or integer, got %T", code)
+		}
+	}
+	return codes, nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+func TestIsParameterExcluded(t *testing.T) {
+	fc := &FilterConfig{ExcludeParameterNames: []string{"X-Debug:header", "trace", "id"}}
+	tests := []struct {
+		name, in string
+		want     bool
+	}{
+		{"X-Debug", "header", true},
+		{"x-debug", "header", true},
+		{"X-Debug", "query", false},
+		{"trace", "query", true},
+		{"trace", "cookie", true},
+		{"Trace", "query", false},
+		{"other", "query", false},
+		{"id", "path", false},
+		{"id", "query", true},
+	}
+	for _, tt := range tests {
+		if got := fc.IsParameterExcluded(tt.name, tt.in); got != tt.want {
+			t.Errorf("IsParameterExcluded(%q, %q) = %v, want %v", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeRejectsExcludedPathParameters(t *testing.T) {
+	spec := &openapi3.T{OpenAPI: "3.0.3", Paths: openapi3.NewPaths()}
+	for _, fc := range []FilterConfig{
+		{ExcludeParameterNames: []string{"id:path"}},
+		{ExcludeParameterLocations: []string{"path"}},
+	} {
+		err := fc.Normalize(spec)
+		if err == nil || !strings.Contains(err.Error(), "path parameters cannot be excluded") {
+			t.Errorf("Normalize(%+v) = %v, want path parameters error", fc, err)
+		}
+	}
+}
+
+func TestPathConfigMarshalRoundTrip(t *testing.T) {
+	preserve, drop := true, false
+	tests := []struct {
+		name     string
+		pc       PathConfig
+		wantJSON string
+	}{
+		{"simple", PathConfig{Methods: []string{"GET", "POST"}}, `["GET","POST"]`},
+		{"servers not preserved", PathConfig{Methods: []string{"GET"}, PreserveServers: &drop}, `{"methods":["GET"],"preserveServers":false}`},
+		{"servers preserved", PathConfig{Methods: []string{"GET"}, PreserveServers: &preserve}, `{"methods":["GET"],"preserveServers":true}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.pc)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Errorf("got JSON %s, want %s", data, tt.wantJSON)
+			}
+			var fromJSON PathConfig
+			if err := json.Unmarshal(data, &fromJSON); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+
+			data, err = yaml.Marshal(tt.pc)
+			if err != nil {
+				t.Fatalf("yaml.Marshal: %v", err)
+			}
+			var fromYAML PathConfig
+			if err := yaml.Unmarshal(data, &fromYAML); err != nil {
+				t.Fatalf("yaml.Unmarshal: %v", err)
+			}
+
+			for format, got := range map[string]PathConfig{"JSON": fromJSON, "YAML": fromYAML} {
+				if !reflect.DeepEqual(got, tt.pc) {
+					t.Errorf("%s round trip got %+v, want %+v", format, got, tt.pc)
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalConfigKeepsServersOptOut(t *testing.T) {
+	drop := false
+	cfg := &Config{}
+	cfg.PreservePathServers = true
+	cfg.Paths = map[string]PathConfig{"/pets": {Methods: []string{"GET"}, PreserveServers: &drop}}
+
+	data, err := MarshalConfig(cfg, "yaml")
+	if err != nil {
+		t.Fatalf("MarshalConfig: %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), ".openapi-filter.yaml")
+	if err := os.WriteFile(configPath, data, 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	reloaded, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if reloaded.Paths["/pets"].ShouldPreserveServers(reloaded.PreservePathServers) {
+		t.Errorf("got /pets preserving servers after a round trip of:\n%s", data)
+	}
+}
+
+func TestEmptyServersAndTagsListsKeepNone(t *testing.T) {
+	formats := map[string]string{
+		"yaml": "servers: []\ntags: []\n",
+		"json": `{"servers": [], "tags": []}`,
+		"toml": "servers = []\ntags = []\n",
+	}
+	for format, content := range formats {
+		t.Run(format, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), ".openapi-filter."+format)
+			if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+			cfg, err := LoadConfig(configPath)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if cfg.Servers.KeepsRoot() {
+				t.Errorf("got servers %+v, want no root servers kept", cfg.Servers)
+			}
+			if cfg.Tags.Keeps("pets") {
+				t.Errorf("got tags %+v, want no tags kept", cfg.Tags)
+			}
+		})
+	}
+
+	var sc ServersConfig
+	if err := json.Unmarshal([]byte(`[]`), &sc); err != nil || sc.KeepsRoot() {
+		t.Errorf("json.Unmarshal([]) = %+v, %v, want no servers kept", sc, err)
+	}
+	var tc TagsConfig
+	if err := yaml.Unmarshal([]byte(`[]`), &tc); err != nil || tc.Keeps("pets") {
+		t.Errorf("yaml.Unmarshal([]) = %+v, %v, want no tags kept", tc, err)
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// RemovedDuplicates returns the duplicate entries removed from the name lists
+// of the config when it was loaded, e.g. "components.schemas: User".
+func (c *Config) RemovedDuplicates() []string {
+	return c.removedDuplicates
+}
+
+// dedupNames trims the component names and path config methods of the config
+// and removes their duplicates, recording them. Component names are case
+// sensitive, as in the spec, while methods are compared ignoring case.
+func (c *Config) dedupNames() {
+	c.removedDuplicates = c.FilterConfig.dedupNames("")
+	for _, name := range slices.Sorted(maps.Keys(c.PathPresets)) {
+		preset := c.PathPresets[name]
+		preset.Methods, c.removedDuplicates = dedupList(preset.Methods, strings.EqualFold,
+			"pathPresets."+name, c.removedDuplicates)
+		c.PathPresets[name] = preset
+	}
+	for _, name := range c.OutputNames() {
+		output := c.Outputs[name]
+		c.removedDuplicates = append(c.removedDuplicates, output.dedupNames("outputs."+name+".")...)
+		c.Outputs[name] = output
+	}
+}
+
+func (fc *FilterConfig) dedupNames(prefix string) []string {
+	var removed []string
+	for _, path := range slices.Sorted(maps.Keys(fc.Paths)) {
+		pathConfig := fc.Paths[path]
+		pathConfig.Methods, removed = dedupList(pathConfig.Methods, strings.EqualFold,
+			prefix+"paths."+path, removed)
+		removed = pathConfig.Components.dedupNames(prefix+"paths."+path+".components.", removed)
+		fc.Paths[path] = pathConfig
+	}
+	if fc.DefaultPath != nil {
+		fc.DefaultPath.Methods, removed = dedupList(fc.DefaultPath.Methods, strings.EqualFold,
+			prefix+"defaultPath", removed)
+		removed = fc.DefaultPath.Components.dedupNames(prefix+"defaultPath.components.", removed)
+	}
+	return fc.Components.dedupNames(prefix+"components.", removed)
+}
+
+// dedupNames trims the component name lists of cc and removes their
+// duplicates, appending them to removed.
+func (cc *FilterComponentsConfig) dedupNames(prefix string, removed []string) []string {
+	if cc == nil {
+		return removed
+	}
+	for _, list := range []struct {
+		key   string
+		names *[]string
+	}{
+		{"schemas", &cc.Schemas},
+		{"parameters", &cc.Parameters},
+		{"securitySchemes", &cc.SecuritySchemes},
+		{"requestBodies", &cc.RequestBodies},
+		{"responses", &cc.Responses},
+		{"headers", &cc.Headers},
+		{"examples", &cc.Examples},
+		{"links", &cc.Links},
+		{"callbacks", &cc.Callbacks},
+		{"pathItems", &cc.PathItems},
+	} {
+		*list.names, removed = dedupList(*list.names, func(a, b string) bool { return a == b },
+			prefix+list.key, removed)
+	}
+	return removed
+}
+
+// dedupList trims the entries of list and removes the empty ones and the
+// duplicates, appending the removed duplicates to removed as "key: entry".
+func dedupList(list []string, equal func(a, b string) bool, key string, removed []string) ([]string, []string) {
+	if list == nil {
+		return nil, removed
+	}
+	deduped := make([]string, 0, len(list))
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if slices.ContainsFunc(deduped, func(kept string) bool { return equal(kept, entry) }) {
+			removed = append(removed, fmt.Sprintf("%s: %s", key, entry))
+			continue
+		}
+		deduped = append(deduped, entry)
+	}
+	return deduped, removed
+}
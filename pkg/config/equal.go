@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Equal reports whether c and other are equivalent configurations, see Diff.
+func (c *Config) Equal(other *Config) bool {
+	return len(c.Diff(other)) == 0
+}
+
+// Diff returns the keys of the fields that differ between c and other, sorted,
+// e.g. "paths./pets.methods" or "x-openapi-filter.logger.level". Path configs
+// are compared in their normalized form, so the simple and advanced formats of
+// the same path config are equal, as are methods differing only by case or
+// order. Nil and empty lists or maps are equal too. Unlike Normalize, Diff
+// does not resolve glob patterns or the "*" method, which need the spec.
+func (c *Config) Diff(other *Config) []string {
+	var diffs []string
+	diffValues("", reflect.ValueOf(c.comparable()), reflect.ValueOf(other.comparable()), &diffs)
+	slices.Sort(diffs)
+	return diffs
+}
+
+// comparable returns a copy of c with normalized path configs.
+func (c *Config) comparable() Config {
+	cc := *c
+	cc.FilterConfig = c.FilterConfig.comparable()
+	if c.Outputs != nil {
+		cc.Outputs = make(map[string]FilterConfig, len(c.Outputs))
+		for name, output := range c.Outputs {
+			cc.Outputs[name] = output.comparable()
+		}
+	}
+	return cc
+}
+
+// comparable returns a copy of fc with normalized path configs.
+func (fc FilterConfig) comparable() FilterConfig {
+	fc.Paths = comparablePaths(fc.Paths)
+	if fc.DefaultPath != nil {
+		defaultPath := fc.DefaultPath.comparable()
+		fc.DefaultPath = &defaultPath
+	}
+	return fc
+}
+
+func comparablePaths(paths map[string]PathConfig) map[string]PathConfig {
+	if paths == nil {
+		return nil
+	}
+	normalized := make(map[string]PathConfig, len(paths))
+	for path, pathConfig := range paths {
+		normalized[path] = pathConfig.comparable()
+	}
+	return normalized
+}
+
+// comparable returns a copy of pc with uppercased, sorted and deduplicated
+// methods, and lowercased response methods.
+func (pc PathConfig) comparable() PathConfig {
+	methods := make([]string, 0, len(pc.Methods))
+	for _, method := range pc.Methods {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	slices.Sort(methods)
+	pc.Methods = slices.Compact(methods)
+	pc.Responses = normalizeResponses(pc.Responses)
+	return pc
+}
+
+// diffValues appends to diffs the keys of the fields that differ between a
+// and b, named after their koanf tags. Lists are compared as a whole.
+func diffValues(key string, a, b reflect.Value, diffs *[]string) {
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := range a.NumField() {
+			field := a.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("koanf"), ",")
+			fieldKey := key
+			if opts != "squash" {
+				if name == "" || name == "-" {
+					name = field.Name
+				}
+				fieldKey = joinKey(key, name)
+			}
+			diffValues(fieldKey, a.Field(i), b.Field(i), diffs)
+		}
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*diffs = append(*diffs, key)
+			}
+			return
+		}
+		diffValues(key, a.Elem(), b.Elem(), diffs)
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, m := range []reflect.Value{a, b} {
+			for _, k := range m.MapKeys() {
+				keys[fmt.Sprint(k.Interface())] = k
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(keys)) {
+			av, bv := a.MapIndex(keys[name]), b.MapIndex(keys[name])
+			if !av.IsValid() || !bv.IsValid() {
+				*diffs = append(*diffs, joinKey(key, name))
+				continue
+			}
+			diffValues(joinKey(key, name), av, bv, diffs)
+		}
+	case reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return
+		}
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*diffs = append(*diffs, key)
+		}
+	case reflect.Interface:
+		// Numbers decode to different types depending on the config format
+		if !extensionValueEqual(a.Interface(), b.Interface()) &&
+			!reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*diffs = append(*diffs, key)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*diffs = append(*diffs, key)
+		}
+	}
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
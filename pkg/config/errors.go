@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrConfigPathEmpty      = errors.New("config path is empty")
+	ErrUnsupportedFormat    = errors.New("unsupported config format")
+	ErrConfigRead           = errors.New("failed to read config")
+	ErrConfigParse          = errors.New("failed to parse config")
+	ErrConfigDecode         = errors.New("failed to decode config")
+	ErrInvalidConfig        = errors.New("invalid config")
+	ErrNoEmbeddedConfig     = errors.New("spec has no embedded " + ToolConfigKey + " config")
+	ErrInvalidEmbeddedValue = errors.New("embedded " + ToolConfigKey + " config must be an object")
+	ErrImportCycle          = errors.New("config import cycle")
+	ErrHostNotAllowed       = errors.New("host not allowed, add it to allowed_hosts")
+)
+
+// ConfigError is returned by the config loaders for errors in a config file.
+// It wraps one of the Err* sentinel errors, so callers can branch with
+// errors.Is, and carries the file path and, when the parser exposes it, the
+// line of the error.
+type ConfigError struct {
+	Path string // Config file path, empty for the embedded config
+	Line int    // 1-based line of the error, 0 if unknown
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	switch {
+	case e.Path != "" && e.Line > 0:
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	case e.Path != "":
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// KeyError is a config decoding error of a single key. Key is the dotted
+// path of the key, e.g. "paths./v1/users" or "x-openapi-filter.logger.level".
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// line returns the line at which the key is defined in the config file, or 0
+// if it cannot be located. The innermost named key is looked up, as list
+// indices do not appear in the file.
+func (e *KeyError) line(configPath string) int {
+	parts := strings.Split(e.Key, ".")
+	if strings.HasPrefix(e.Key, "paths.") {
+		parts = []string{"paths", strings.TrimPrefix(e.Key, "paths.")}
+	}
+	for i := len(parts) - 1; i >= 0; i-- {
+		if _, err := strconv.Atoi(parts[i]); err == nil {
+			continue
+		}
+		lines, err := KeyLines(configPath, []string{parts[i]})
+		if err != nil {
+			return 0
+		}
+		return lines[parts[i]]
+	}
+	return 0
+}
+
+// mapstructureKeyRe matches the key name that mapstructure quotes in its
+// error messages, e.g. "'rules[0].methods' expected a map, got 'string'".
+var mapstructureKeyRe = regexp.MustCompile(`^(?:error decoding )?'([^']+)'`)
+
+// decodeKeyError returns the first *KeyError of a decoding error. Errors
+// reported by mapstructure itself are converted to a *KeyError from the key
+// name in their message. Other errors are returned as is.
+func decodeKeyError(err error) error {
+	var keyErr *KeyError
+	if errors.As(err, &keyErr) {
+		return keyErr
+	}
+	if keyErr := mapstructureKeyError(err); keyErr != nil {
+		return keyErr
+	}
+	return err
+}
+
+// mapstructureKeyError returns the first error of a mapstructure decoding
+// error, which may join several, that names its key, as a *KeyError.
+func mapstructureKeyError(err error) *KeyError {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, inner := range joined.Unwrap() {
+			if keyErr := mapstructureKeyError(inner); keyErr != nil {
+				return keyErr
+			}
+		}
+		return nil
+	}
+	if m := mapstructureKeyRe.FindStringSubmatch(err.Error()); m != nil {
+		msg := strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(err.Error(), m[0])), ": ")
+		return &KeyError{
+			Key: strings.NewReplacer("[", ".", "]", "").Replace(m[1]),
+			Err: errors.New(msg),
+		}
+	}
+	if inner := errors.Unwrap(err); inner != nil {
+		return mapstructureKeyError(inner)
+	}
+	return nil
+}
+
+// lineRe matches the line number in error messages of parsers that do not
+// expose it otherwise, e.g. "yaml: line 3: mapping values are not allowed".
+var lineRe = regexp.MustCompile(`\bline (\d+)\b`)
+
+// errorLine returns the 1-based line of a parse error of the config content,
+// or 0 if the parser does not expose it.
+func errorLine(content []byte, err error) int {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return offsetLine(content, syntaxErr.Offset)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return offsetLine(content, typeErr.Offset)
+	}
+	var posErr interface{ Position() (row, column int) }
+	if errors.As(err, &posErr) {
+		row, _ := posErr.Position()
+		return row
+	}
+	if m := lineRe.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return line
+	}
+	return 0
+}
+
+// offsetLine returns the 1-based line of the byte offset in content.
+func offsetLine(content []byte, offset int64) int {
+	if offset < 0 || offset > int64(len(content)) {
+		return 0
+	}
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Hash returns the hex-encoded SHA-256 hash of the config, e.g. to key a
+// cache of filtered specs. Configs equal by Equal hash the same: path
+// configs are hashed in their normalized form and unset fields are omitted.
+// The hash is computed over the config encoded as JSON with sorted keys, so
+// it does not depend on map iteration order, the process or the Go version.
+func (c *Config) Hash() (string, error) {
+	cc := c.comparable()
+	data, err := json.Marshal(encodeValue(reflect.ValueOf(cc)))
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
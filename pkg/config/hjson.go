@@ -0,0 +1,33 @@
+package config
+
+import (
+	"github.com/hjson/hjson-go/v4"
+	"github.com/knadh/koanf/parsers/json"
+)
+
+// HJSON is a koanf parser for Hjson, a commented and relaxed JSON dialect:
+// comments (#, // and /* */), unquoted keys and strings, single-quoted
+// strings, multiline strings and optional commas are accepted. It also parses
+// the JSON5 documents configs are usually written in.
+type HJSON struct {
+	json *json.JSON
+}
+
+// HJSONParser returns an Hjson parser.
+func HJSONParser() *HJSON {
+	return &HJSON{json: json.Parser()}
+}
+
+// Unmarshal parses the given Hjson bytes.
+func (p *HJSON) Unmarshal(b []byte) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	if err := hjson.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Marshal marshals the given config map to plain JSON bytes.
+func (p *HJSON) Marshal(o map[string]interface{}) ([]byte, error) {
+	return p.json.Marshal(o)
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ImportsKey is the top-level config key listing the config files a config
+// file imports.
+const ImportsKey = "imports"
+
+// popImports removes the imports key from the parsed config and returns the
+// imported config paths, resolved against the path of the importing config.
+func popImports(parsed map[string]any, configPath string) ([]string, error) {
+	raw, ok := parsed[ImportsKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(parsed, ImportsKey)
+
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, &KeyError{Key: ImportsKey, Err: fmt.Errorf("imports must be a list of paths, got %T", raw)}
+	}
+	imports := make([]string, 0, len(list))
+	for i, item := range list {
+		importPath, ok := item.(string)
+		if !ok || importPath == "" {
+			return nil, &KeyError{
+				Key: fmt.Sprintf("%s[%d]", ImportsKey, i),
+				Err: fmt.Errorf("import must be a non-empty path, got %v", item),
+			}
+		}
+		resolved, err := resolveImport(configPath, importPath)
+		if err != nil {
+			return nil, &KeyError{Key: fmt.Sprintf("%s[%d]", ImportsKey, i), Err: err}
+		}
+		imports = append(imports, resolved)
+	}
+	return imports, nil
+}
+
+// resolveImport resolves the imported config path against the path of the
+// importing config. Relative paths are relative to the importing config's
+// directory, or URL, while absolute paths and URLs are kept as is.
+func resolveImport(configPath, importPath string) (string, error) {
+	if isRemoteConfig(importPath) {
+		return importPath, nil
+	}
+	if isRemoteConfig(configPath) {
+		base, err := url.Parse(configPath)
+		if err != nil {
+			return "", fmt.Errorf("url.Parse: %w", err)
+		}
+		ref, err := url.Parse(filepath.ToSlash(importPath))
+		if err != nil {
+			return "", fmt.Errorf("url.Parse: %w", err)
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	if filepath.IsAbs(importPath) {
+		return filepath.Clean(importPath), nil
+	}
+	return filepath.Join(filepath.Dir(configPath), importPath), nil
+}
+
+// importKey returns the key identifying the config at configPath in an
+// import chain, so that a file is recognized whatever the path it is
+// imported by.
+func importKey(configPath string) string {
+	if isRemoteConfig(configPath) {
+		return configPath
+	}
+	if abs, err := filepath.Abs(configPath); err == nil {
+		return abs
+	}
+	return filepath.Clean(configPath)
+}
+
+// checkImportCycle returns ErrImportCycle, with the chain of imports, if the
+// config at configPath is already being loaded in chain.
+func checkImportCycle(chain []string, configPath string) error {
+	key := importKey(configPath)
+	i := slices.IndexFunc(chain, func(p string) bool { return importKey(p) == key })
+	if i < 0 {
+		return nil
+	}
+	cycle := append(slices.Clone(chain[i:]), configPath)
+	return fmt.Errorf("%w: %s", ErrImportCycle, strings.Join(cycle, " -> "))
+}
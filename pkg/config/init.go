@@ -1,88 +1,352 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/knadh/koanf/parsers/json"
 	"github.com/knadh/koanf/parsers/toml/v2"
 	"github.com/knadh/koanf/parsers/yaml"
-	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
 )
 
-var ErrConfigPathEmpty = errors.New("config path is empty")
+func initConfig[C any](ctx context.Context, configPath string, opts LoadOptions) (*C, error) {
+	k := opts.newKoanf()
+	if err := loadFile(ctx, k, configPath, opts.Loader); err != nil {
+		return nil, err
+	}
+	if err := opts.loadEnv(k); err != nil {
+		return nil, err
+	}
+	return unmarshalConfig[C](k)
+}
 
-func initConfig[C any](configPath string) (*C, error) {
-	k := koanf.New(".")
+// loadFile loads the config file, or the config fetched from an HTTP(S) URL
+// as loader allows, into k, choosing the parser by extension. The configs it
+// imports are loaded first, so that its own keys override theirs. Errors are
+// returned as a *ConfigError.
+func loadFile(ctx context.Context, k *koanf.Koanf, configPath string, loader *LoaderConfig) error {
+	return loadFileChain(ctx, k, configPath, loader, nil)
+}
 
-	configExt := strings.TrimLeft(filepath.Ext(configPath), ".")
+// loadFileChain is loadFile for a config imported through the configs of
+// chain, the importing configs, outermost first.
+func loadFileChain(ctx context.Context, k *koanf.Koanf, configPath string, loader *LoaderConfig, chain []string) error {
+	if err := checkImportCycle(chain, configPath); err != nil {
+		return &ConfigError{Path: configPath, Err: err}
+	}
 
-	var parser koanf.Parser
-	switch configExt {
-	case "yaml", "yml":
-		parser = yaml.Parser()
-	case "toml":
-		parser = toml.Parser()
-	case "json":
-		parser = json.Parser()
-	default:
-		return nil, fmt.Errorf("unsupported config format: %s", configExt)
+	parser, err := ParseFormat(configFormat(configPath))
+	if err != nil {
+		return &ConfigError{Path: configPath, Err: err}
 	}
 
-	if err := k.Load(file.Provider(configPath), parser); err != nil {
-		return nil, fmt.Errorf("k.Load: %w", err)
+	content, err := readConfig(ctx, configPath, loader)
+	if err != nil {
+		return &ConfigError{Path: configPath, Err: fmt.Errorf("%w: %w", ErrConfigRead, err)}
+	}
+	parsed, err := parser.Unmarshal(content)
+	if err != nil {
+		return &ConfigError{
+			Path: configPath,
+			Line: errorLine(content, err),
+			Err:  fmt.Errorf("%w: %w", ErrConfigParse, err),
+		}
+	}
+	imports, err := popImports(parsed, configPath)
+	if err != nil {
+		return asConfigError(configPath, fmt.Errorf("%w: %w", ErrConfigParse, err))
+	}
+	chain = append(slices.Clip(chain), configPath)
+	for _, importPath := range imports {
+		if err := loadFileChain(ctx, k, importPath, loader, chain); err != nil {
+			return err
+		}
+	}
+	if err := k.Load(mapProvider(parsed), nil); err != nil {
+		return &ConfigError{Path: configPath, Err: fmt.Errorf("%w: %w", ErrConfigParse, err)}
+	}
+	return nil
+}
+
+// configFormats are the supported config formats, named after their file
+// extensions, with their parsers.
+var configFormats = []struct {
+	ext    string
+	parser func() koanf.Parser
+}{
+	{"yaml", func() koanf.Parser { return yaml.Parser() }},
+	{"yml", func() koanf.Parser { return yaml.Parser() }},
+	{"toml", func() koanf.Parser { return toml.Parser() }},
+	{"json", func() koanf.Parser { return json.Parser() }},
+	{"jsonc", func() koanf.Parser { return JSONCParser() }},
+	{"json5", func() koanf.Parser { return HJSONParser() }},
+	{"hjson", func() koanf.Parser { return HJSONParser() }},
+}
+
+// SupportedConfigFormats returns the supported config formats, named after
+// their file extensions without the dot, e.g. "yaml".
+func SupportedConfigFormats() []string {
+	formats := make([]string, len(configFormats))
+	for i, format := range configFormats {
+		formats[i] = format.ext
+	}
+	return formats
+}
+
+// ParseFormat returns the parser of a config format, named after its file
+// extension, with or without the dot. Returns ErrUnsupportedFormat for other
+// formats.
+func ParseFormat(ext string) (koanf.Parser, error) {
+	ext = strings.TrimPrefix(ext, ".")
+	for _, format := range configFormats {
+		if format.ext == ext {
+			return format.parser(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, ext)
+}
+
+// unmarshalConfig decodes the config loaded into k.
+func unmarshalConfig[C any](k *koanf.Koanf) (*C, error) {
+	presets, err := loadMethodPresets(k)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConfigDecode, err)
 	}
 
 	var cfg C
 	// Use koanf's Unmarshal with custom mapstructure hook
 	unmarshalOpts := koanf.UnmarshalConf{
 		DecoderConfig: &mapstructure.DecoderConfig{
-			Result:           &cfg,
-			DecodeHook:       pathConfigDecodeHook,
+			Result: &cfg,
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				pathConfigsDecodeHook(presets),
+				pathConfigDecodeHook(presets),
+				serversConfigDecodeHook,
+				tagsConfigDecodeHook,
+				mapstructure.StringToTimeDurationHookFunc(),
+			),
 			WeaklyTypedInput: true,
 		},
 	}
 	if err := k.UnmarshalWithConf("", &cfg, unmarshalOpts); err != nil {
-		return nil, fmt.Errorf("k.UnmarshalWithConf: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrConfigDecode, decodeKeyError(err))
 	}
 	return &cfg, nil
 }
 
-// pathConfigDecodeHook is a mapstructure decode hook that handles PathConfig decoding
-// from both simple array format and advanced object format.
-func pathConfigDecodeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
-	// Handle conversion to PathConfig (both value and pointer types)
-	pathConfigType := reflect.TypeOf(PathConfig{})
-	pathConfigPtrType := reflect.TypeOf((*PathConfig)(nil))
+// pathConfigsDecodeHook returns a mapstructure decode hook that decodes the
+// paths map, so that errors of a PathConfig are reported with the path key
+// they belong to, e.g. "paths./v1/users". Method preset references are
+// expanded with presets.
+func pathConfigsDecodeHook(presets MethodPresets) mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if to != reflect.TypeOf(map[string]PathConfig{}) || from == to {
+			return data, nil
+		}
+
+		val := reflect.ValueOf(data)
+		if val.Kind() != reflect.Map {
+			return nil, &KeyError{Key: "paths", Err: fmt.Errorf("paths must be an object, got %v", val.Kind())}
+		}
+		paths := make(map[string]PathConfig, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			path := fmt.Sprint(iter.Key().Interface())
+			pc := PathConfig{}
+			if err := pc.decode(iter.Value().Interface(), presets); err != nil {
+				return nil, &KeyError{Key: "paths." + path, Err: err}
+			}
+			paths[path] = pc
+		}
+		return paths, nil
+	}
+}
 
-	if to != pathConfigType && to != pathConfigPtrType {
+// pathConfigDecodeHook returns a mapstructure decode hook that handles
+// PathConfig decoding from both simple array format and advanced object format.
+// Method preset references are expanded with presets.
+func pathConfigDecodeHook(presets MethodPresets) mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		// Handle conversion to PathConfig (both value and pointer types)
+		pathConfigType := reflect.TypeOf(PathConfig{})
+		pathConfigPtrType := reflect.TypeOf((*PathConfig)(nil))
+
+		if (to != pathConfigType && to != pathConfigPtrType) ||
+			from == pathConfigType || from == pathConfigPtrType {
+			return data, nil
+		}
+
+		pc := &PathConfig{}
+		if err := pc.decode(data, presets); err != nil {
+			return nil, err
+		}
+
+		// Return pointer if target is pointer type
+		if to == pathConfigPtrType {
+			return pc, nil
+		}
+		return *pc, nil
+	}
+}
+
+// serversConfigDecodeHook is a mapstructure decode hook that handles ServersConfig
+// decoding from both boolean format and list of URL patterns format.
+func serversConfigDecodeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(ServersConfig{}) || from == to {
 		return data, nil
 	}
 
-	pc := &PathConfig{}
-	if err := pc.DecodeMapstructure(data); err != nil {
-		return nil, err
+	sc := ServersConfig{}
+	if err := sc.DecodeMapstructure(data); err != nil {
+		return nil, &KeyError{Key: "servers", Err: err}
+	}
+	return sc, nil
+}
+
+// tagsConfigDecodeHook is a mapstructure decode hook that handles TagsConfig
+// decoding from both boolean format and list of tag names format.
+func tagsConfigDecodeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(TagsConfig{}) || from == to {
+		return data, nil
 	}
 
-	// Return pointer if target is pointer type
-	if to == pathConfigPtrType {
-		return pc, nil
+	tc := TagsConfig{}
+	if err := tc.DecodeMapstructure(data); err != nil {
+		return nil, &KeyError{Key: "tags", Err: err}
+	}
+	return tc, nil
+}
+
+// mapProvider is a koanf provider of an already parsed, nested config map.
+// Keys are kept as is, so path keys containing the delimiter are not split.
+type mapProvider map[string]any
+
+func (mp mapProvider) ReadBytes() ([]byte, error) {
+	return nil, errors.New("mapProvider does not support ReadBytes")
+}
+
+func (mp mapProvider) Read() (map[string]any, error) {
+	return mp, nil
+}
+
+// loadEmbedded loads the config embedded in the spec's x-openapi-filter
+// extension into k.
+func loadEmbedded(k *koanf.Koanf, spec *openapi3.T) error {
+	raw, ok := spec.Extensions[ToolConfigKey]
+	if !ok {
+		return ErrNoEmbeddedConfig
 	}
-	return *pc, nil
+	embedded, ok := raw.(map[string]any)
+	if !ok {
+		return ErrInvalidEmbeddedValue
+	}
+	if err := k.Load(mapProvider(embedded), nil); err != nil {
+		return fmt.Errorf("k.Load: %w", err)
+	}
+	return nil
 }
 
+// LoadConfig loads the config file at configPath, or fetches it when
+// configPath is an HTTP(S) URL, inferring its format from the extension.
+// Returns ErrConfigPathEmpty if configPath is empty; other errors are
+// returned as a *ConfigError wrapping one of the Err* sentinel errors.
 func LoadConfig(configPath string) (*Config, error) {
+	return LoadConfigContext(context.Background(), configPath)
+}
+
+// LoadConfigContext is LoadConfig, canceling the fetch of a remote config
+// when ctx is done.
+func LoadConfigContext(ctx context.Context, configPath string) (*Config, error) {
+	return LoadConfigWithOptions(ctx, configPath, LoadOptions{})
+}
+
+// LoadConfigWithOptions is LoadConfigContext, with the koanf delimiter and
+// the environment variable overrides of opts.
+func LoadConfigWithOptions(ctx context.Context, configPath string, opts LoadOptions) (*Config, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if configPath == "" {
 		return nil, ErrConfigPathEmpty
 	}
-	cfg, err := initConfig[Config](configPath)
+	cfg, err := initConfig[Config](ctx, configPath, opts)
 	if err != nil {
-		return nil, fmt.Errorf("initConfig[Config]: %w", err)
+		return nil, asConfigError(configPath, err)
+	}
+	if err := cfg.Tool.Validate(); err != nil {
+		return nil, asConfigError(configPath, fmt.Errorf("%w: %w", ErrInvalidConfig, err))
+	}
+	cfg.dedupNames()
+	return cfg, nil
+}
+
+// asConfigError returns err as a *ConfigError for the config file at
+// configPath, unless it already is one. The line of a *KeyError is looked up in the config file.
+func asConfigError(configPath string, err error) error {
+	var cfgErr *ConfigError
+	if errors.As(err, &cfgErr) {
+		return err
+	}
+	cfgErr = &ConfigError{Path: configPath, Err: err}
+	var keyErr *KeyError
+	if configPath != "" && errors.As(err, &keyErr) {
+		cfgErr.Line = keyErr.line(configPath)
+	}
+	return cfgErr
+}
+
+// HasEmbeddedConfig reports whether the spec carries a filter config in its
+// x-openapi-filter extension.
+func HasEmbeddedConfig(spec *openapi3.T) bool {
+	_, ok := spec.Extensions[ToolConfigKey]
+	return ok
+}
+
+// LoadConfigFromSpec decodes the filter config embedded in the spec's
+// x-openapi-filter extension. The embedded config has the same shape as a
+// config file. Returns ErrNoEmbeddedConfig if the spec has none.
+func LoadConfigFromSpec(spec *openapi3.T) (*Config, error) {
+	return LoadLayeredConfig(spec, "")
+}
+
+// LoadLayeredConfig loads the config embedded in the spec and layers the
+// config file at configPath on top of it: every key set in the file overrides
+// the embedded one, key by key, while keys only set in the spec are kept.
+// An empty configPath loads the embedded config alone.
+func LoadLayeredConfig(spec *openapi3.T, configPath string) (*Config, error) {
+	return LoadLayeredConfigWithOptions(spec, configPath, LoadOptions{})
+}
+
+// LoadLayeredConfigWithOptions is LoadLayeredConfig, with the koanf delimiter
+// and the environment variable overrides of opts, layered on top of the
+// config file.
+func LoadLayeredConfigWithOptions(spec *openapi3.T, configPath string, opts LoadOptions) (*Config, error) {
+	k := opts.newKoanf()
+	if err := loadEmbedded(k, spec); err != nil {
+		return nil, fmt.Errorf("loadEmbedded: %w", err)
+	}
+	if configPath != "" {
+		if err := loadFile(context.Background(), k, configPath, opts.Loader); err != nil {
+			return nil, err
+		}
+	}
+	if err := opts.loadEnv(k); err != nil {
+		return nil, err
+	}
+	cfg, err := unmarshalConfig[Config](k)
+	if err != nil {
+		return nil, asConfigError(configPath, err)
+	}
+	if err := cfg.Tool.Validate(); err != nil {
+		return nil, asConfigError(configPath, fmt.Errorf("%w: %w", ErrInvalidConfig, err))
 	}
+	cfg.dedupNames()
 	return cfg, nil
 }
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"github.com/knadh/koanf/parsers/json"
+)
+
+// JSONC is a koanf parser for JSON with comments: it strips line (//) and
+// block (/* */) comments and trailing commas before parsing the data as JSON.
+type JSONC struct {
+	json *json.JSON
+}
+
+// JSONCParser returns a JSON with comments parser.
+func JSONCParser() *JSONC {
+	return &JSONC{json: json.Parser()}
+}
+
+// Unmarshal parses the given JSONC bytes.
+func (p *JSONC) Unmarshal(b []byte) (map[string]interface{}, error) {
+	return p.json.Unmarshal(stripJSONC(b))
+}
+
+// Marshal marshals the given config map to plain JSON bytes.
+func (p *JSONC) Marshal(o map[string]interface{}) ([]byte, error) {
+	return p.json.Marshal(o)
+}
+
+// stripJSONC removes comments and trailing commas outside of string literals.
+// Removed comments are replaced by whitespace so byte offsets and line numbers
+// reported by the JSON parser stay valid.
+func stripJSONC(b []byte) []byte {
+	out := make([]byte, len(b))
+	copy(out, b)
+
+	inString := false
+	lastComma := -1 // index of a comma not yet followed by a value
+	blank := func(i int) {
+		if out[i] != '\n' {
+			out[i] = ' '
+		}
+	}
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++ // skip the escaped character
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			lastComma = -1
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				blank(i)
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			blank(i)
+			blank(i + 1)
+			for i += 2; i < len(out); i++ {
+				if out[i] == '*' && i+1 < len(out) && out[i+1] == '/' {
+					blank(i)
+					blank(i + 1)
+					i++
+					break
+				}
+				blank(i)
+			}
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			lastComma = -1
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadJSONCConfig(t *testing.T) {
+	const data = `{
+  // Public API
+  "paths": {
+    "/pets": ["get", "post",], /* simple format */
+    "/pets/{id}": {
+      "methods": ["get"],
+      "preserveServers": true, // advanced format
+    },
+  },
+  "servers": true,
+  "info": {"title": "Pets // public /* API */"},
+}
+`
+	for _, ext := range []string{"jsonc", "json5"} {
+		t.Run(ext, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), ".openapi-filter."+ext)
+			if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+			cfg, err := LoadConfig(configPath)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if pc := cfg.Paths["/pets"]; !pc.KeepsMethod("POST") || pc.PreserveServers != nil {
+				t.Errorf("got /pets %+v, want the simple format with GET and POST", pc)
+			}
+			if pc := cfg.Paths["/pets/{id}"]; !pc.KeepsMethod("GET") || !pc.ShouldPreserveServers(false) {
+				t.Errorf("got /pets/{id} %+v, want the advanced format preserving servers", pc)
+			}
+			if !cfg.Servers.Enabled {
+				t.Error("got servers disabled, want enabled")
+			}
+			if cfg.Info == nil || cfg.Info.Title != "Pets // public /* API */" {
+				t.Errorf("got info %+v, want the title with comment markers kept", cfg.Info)
+			}
+		})
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`{"a": 1,}`, `{"a": 1 }`},
+		{`[1, 2, ]`, `[1, 2  ]`},
+		{"{\"a\": \"x,}\" // c\n}", "{\"a\": \"x,}\"     \n}"},
+		{`{"a": "\"/*"/* c */}`, `{"a": "\"/*"       }`},
+	}
+	for _, tt := range tests {
+		if got := string(stripJSONC([]byte(tt.in))); got != tt.want {
+			t.Errorf("stripJSONC(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
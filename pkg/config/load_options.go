@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// DefaultDelimiter is the delimiter of koanf key paths when
+// LoadOptions.Delimiter is unset.
+const DefaultDelimiter = "."
+
+// envKeySeparator separates the keys of nested sections in environment
+// variable names, e.g. OAF_X_OPENAPI_FILTER__LOGGER__LEVEL.
+const envKeySeparator = "__"
+
+// LoadOptions customize the loading of a config, for applications embedding
+// the filter with their own config conventions. The zero value loads configs
+// as LoadConfig does.
+type LoadOptions struct {
+	Delimiter string // Delimiter of koanf key paths, defaults to "."
+	EnvPrefix string // Prefix of the environment variables overriding config keys, none are read if empty
+	// EnvKeyTransformer maps the name of an environment variable, with its
+	// prefix, to the key path it sets, joined by the delimiter, or to "" to
+	// ignore the variable. Defaults to DefaultEnvKeyTransformer.
+	EnvKeyTransformer func(name string) string
+	// Loader sets the HTTPTimeout and AllowedHosts of the fetch of a config
+	// given by URL, and of the configs it imports. Defaults to
+	// DefaultHTTPTimeout and any host.
+	Loader *LoaderConfig
+}
+
+func (o LoadOptions) delimiter() string {
+	if o.Delimiter == "" {
+		return DefaultDelimiter
+	}
+	return o.Delimiter
+}
+
+func (o LoadOptions) newKoanf() *koanf.Koanf {
+	return koanf.New(o.delimiter())
+}
+
+// loadEnv loads the environment variables starting with EnvPrefix into k,
+// over the keys already loaded.
+func (o LoadOptions) loadEnv(k *koanf.Koanf) error {
+	if o.EnvPrefix == "" {
+		return nil
+	}
+	transform := o.EnvKeyTransformer
+	if transform == nil {
+		transform = DefaultEnvKeyTransformer(o.EnvPrefix, o.delimiter())
+	}
+
+	env := make(map[string]any)
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, o.EnvPrefix) {
+			continue
+		}
+		key := transform(name)
+		if key == "" {
+			continue
+		}
+		setNested(env, strings.Split(key, o.delimiter()), value)
+	}
+	if err := k.Load(mapProvider(env), nil); err != nil {
+		return fmt.Errorf("%w: %w", ErrConfigParse, err)
+	}
+	return nil
+}
+
+// setNested sets the value at the key path in m, creating the intermediate
+// maps.
+func setNested(m map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[key] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+// splitList splits a comma-separated environment variable value, trimming
+// spaces and dropping empty items.
+func splitList(s string) []string {
+	items := []string{}
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// DefaultEnvKeyTransformer returns the default LoadOptions.EnvKeyTransformer.
+// The prefix is trimmed and the rest of the name split into keys at double
+// underscores. Keys are matched to config fields ignoring case, underscores
+// and hyphens, e.g. OAF_FAIL_ON_WARNINGS sets failOnWarnings and
+// OAF_X_OPENAPI_FILTER__LOADER__MAX_SPEC_BYTES sets
+// x-openapi-filter.loader.max_spec_bytes. Map keys, e.g. output names, are
+// lowercased. Variables not naming a config field are ignored.
+func DefaultEnvKeyTransformer(prefix, delim string) func(name string) string {
+	return func(name string) string {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok || rest == "" {
+			return ""
+		}
+		keys, ok := configKeyPath(reflect.TypeFor[Config](), strings.Split(rest, envKeySeparator))
+		if !ok {
+			return ""
+		}
+		return strings.Join(keys, delim)
+	}
+}
+
+// configKeyPath resolves the environment variable segments to the keys of
+// the config type typ, named after their koanf tags.
+func configKeyPath(typ reflect.Type, segments []string) ([]string, bool) {
+	if len(segments) == 0 {
+		return nil, true
+	}
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return configKeyPath(typ.Elem(), segments)
+	case reflect.Map:
+		rest, ok := configKeyPath(typ.Elem(), segments[1:])
+		return append([]string{strings.ToLower(segments[0])}, rest...), ok
+	case reflect.Struct:
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("koanf"), ",")
+			if opts == "squash" {
+				if keys, ok := configKeyPath(field.Type, segments); ok {
+					return keys, true
+				}
+				continue
+			}
+			if name == "" || name == "-" || envKeyName(name) != envKeyName(segments[0]) {
+				continue
+			}
+			rest, ok := configKeyPath(field.Type, segments[1:])
+			return append([]string{name}, rest...), ok
+		}
+	}
+	return nil, false
+}
+
+// envKeyName normalizes a key for matching against an environment variable
+// segment.
+func envKeyName(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
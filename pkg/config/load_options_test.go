@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadEnvServersAndTags(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".openapi-filter.yaml")
+	if err := os.WriteFile(configPath, []byte("paths:\n  /pets: [get]\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	tests := []struct {
+		name          string
+		servers, tags string
+		wantServers   ServersConfig
+		wantTags      TagsConfig
+	}{
+		{"enabled", "true", "true", ServersConfig{Enabled: true}, TagsConfig{Enabled: true}},
+		{"disabled", "false", "0", ServersConfig{}, TagsConfig{}},
+		{"empty lists", "", " , ", ServersConfig{}, TagsConfig{}},
+		{
+			"lists",
+			"https://api.example.com/*, https://*.example.org", "pets,store",
+			ServersConfig{Enabled: true, Patterns: []string{"https://api.example.com/*", "https://*.example.org"}},
+			TagsConfig{Enabled: true, Names: []string{"pets", "store"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OAF_SERVERS", tt.servers)
+			t.Setenv("OAF_TAGS", tt.tags)
+			cfg, err := LoadConfigWithOptions(context.Background(), configPath, LoadOptions{EnvPrefix: "OAF_"})
+			if err != nil {
+				t.Fatalf("LoadConfigWithOptions: %v", err)
+			}
+			if cfg.Servers.Enabled != tt.wantServers.Enabled || !slices.Equal(cfg.Servers.Patterns, tt.wantServers.Patterns) {
+				t.Errorf("got servers %+v, want %+v", cfg.Servers, tt.wantServers)
+			}
+			if cfg.Tags.Enabled != tt.wantTags.Enabled || !slices.Equal(cfg.Tags.Names, tt.wantTags.Names) {
+				t.Errorf("got tags %+v, want %+v", cfg.Tags, tt.wantTags)
+			}
+		})
+	}
+}
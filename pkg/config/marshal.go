@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// MarshalConfig encodes the config in format, one of SupportedConfigFormats,
+// e.g. "toml" or "yaml". Unset fields are
+// omitted. A path config is written in the simple format, a list of methods,
+// unless it needs the advanced one: when it sets PreserveServers, either way,
+// filters responses or lists components. Method
+// preset references, expanded when loading, are written expanded.
+// Marshaling to JSON5 or Hjson writes plain JSON.
+func MarshalConfig(c *Config, format string) ([]byte, error) {
+	parser, err := ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	encoded, _ := encodeValue(reflect.ValueOf(*c)).(map[string]any)
+	data, err := parser.Marshal(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("parser.Marshal: %w", err)
+	}
+	return data, nil
+}
+
+// encodeValue converts a config value to maps, lists and scalars, with the
+// keys of its koanf tags, as the parsers decode it.
+func encodeValue(v reflect.Value) any {
+	switch v := v.Interface().(type) {
+	case ServersConfig:
+		if v.IsFiltered() {
+			return v.Patterns
+		}
+		return v.Enabled
+	case TagsConfig:
+		if v.IsFiltered() {
+			return v.Names
+		}
+		return v.Enabled
+	case PathConfig:
+		return v.encode()
+	case time.Duration:
+		return v.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return encodeValue(v.Elem())
+	case reflect.Struct:
+		encoded := make(map[string]any)
+		encodeFields(v, encoded)
+		return encoded
+	case reflect.Map:
+		encoded := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			encoded[fmt.Sprint(iter.Key().Interface())] = encodeValue(iter.Value())
+		}
+		return encoded
+	case reflect.Slice, reflect.Array:
+		encoded := make([]any, v.Len())
+		for i := range v.Len() {
+			encoded[i] = encodeValue(v.Index(i))
+		}
+		return encoded
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return v.Interface()
+}
+
+// encodeFields adds the set exported fields of the struct v to encoded,
+// merging squashed structs.
+func encodeFields(v reflect.Value, encoded map[string]any) {
+	for i := range v.NumField() {
+		field, value := v.Type().Field(i), v.Field(i)
+		if !field.IsExported() || isUnset(value) {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("koanf"), ",")
+		if opts == "squash" {
+			if squashed, ok := encodeValue(value).(map[string]any); ok {
+				for key, value := range squashed {
+					encoded[key] = value
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		encoded[name] = encodeValue(value)
+	}
+}
+
+// isUnset reports whether a field value is zero, or an empty list or map.
+func isUnset(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// encode returns the path config in the simple format, unless it sets
+// PreserveServers, filters responses or lists components. An explicit false
+// is kept, as it overrides PreservePathServers.
+func (pc PathConfig) encode() any {
+	if pc.Preset != "" {
+		return MethodPresetPrefix + pc.Preset
+	}
+	methods := pc.Methods
+	if methods == nil {
+		methods = []string{}
+	}
+	if pc.PreserveServers == nil && len(pc.Responses) == 0 && pc.Components == nil {
+		return methods
+	}
+	encoded := map[string]any{"methods": methods}
+	if pc.PreserveServers != nil {
+		encoded["preserveServers"] = *pc.PreserveServers
+	}
+	if len(pc.Responses) != 0 {
+		encoded["responses"] = pc.Responses
+	}
+	if pc.Components != nil {
+		encoded["components"] = encodeValue(reflect.ValueOf(pc.Components))
+	}
+	return encoded
+}
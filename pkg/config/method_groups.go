@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// MethodGroupPrefix marks a method group token, e.g. "@safe".
+const MethodGroupPrefix = "@"
+
+// MethodGroups are the method groups a path config can list in place of
+// methods, named after the HTTP method semantics of RFC 9110. Like the "*"
+// method, a group expands to the methods of the group the path defines.
+var MethodGroups = map[string][]string{
+	"safe":     {http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace},
+	"readonly": {http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace},
+	"mutating": {http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+	"unsafe":   {http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+	"idempotent": {
+		http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete,
+	},
+}
+
+// isMethodGroup reports whether a method is a method group token.
+func isMethodGroup(method string) bool {
+	return strings.HasPrefix(method, MethodGroupPrefix)
+}
+
+// methodGroup returns the methods of the method group token. An unknown
+// group is an error.
+func methodGroup(token string) ([]string, error) {
+	methods, ok := MethodGroups[strings.ToLower(strings.TrimPrefix(token, MethodGroupPrefix))]
+	if !ok {
+		return nil, fmt.Errorf("unknown method group %s, expected one of %s", token,
+			MethodGroupPrefix+strings.Join(slices.Sorted(maps.Keys(MethodGroups)), ", "+MethodGroupPrefix))
+	}
+	return methods, nil
+}
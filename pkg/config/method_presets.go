@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// MethodPresetPrefix marks a method preset reference, e.g. "$readonly".
+const MethodPresetPrefix = "$"
+
+// MethodPresets maps preset names to the lists of methods they stand for. A
+// path config references a preset in place of its methods list, as in
+// `methods: "$readonly"`, or as an entry of the list, as in
+// `[ "$readonly", post ]`. References are expanded when the config is loaded.
+type MethodPresets map[string][]string
+
+// isPresetRef reports whether a method is a method preset reference.
+func isPresetRef(method string) bool {
+	return strings.HasPrefix(method, MethodPresetPrefix)
+}
+
+// expand replaces the preset references of methods by the methods of the
+// presets. An unknown preset is an error.
+func (mp MethodPresets) expand(methods []string) ([]string, error) {
+	expanded := make([]string, 0, len(methods))
+	for _, method := range methods {
+		if !isPresetRef(method) {
+			expanded = append(expanded, method)
+			continue
+		}
+		preset, ok := mp[strings.TrimPrefix(method, MethodPresetPrefix)]
+		if !ok {
+			return nil, fmt.Errorf("unknown method preset: %s", method)
+		}
+		expanded = append(expanded, preset...)
+	}
+	return expanded, nil
+}
+
+// loadMethodPresets decodes the methodPresets section of the config loaded
+// into k, which must be known before path configs are decoded.
+func loadMethodPresets(k *koanf.Koanf) (MethodPresets, error) {
+	var presets MethodPresets
+	if err := k.UnmarshalWithConf("methodPresets", &presets, koanf.UnmarshalConf{}); err != nil {
+		return nil, &KeyError{Key: "methodPresets", Err: err}
+	}
+	return presets, nil
+}
@@ -0,0 +1,582 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	pathpkg "path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/oas31"
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// MethodWildcard selects every operation of a path.
+const MethodWildcard = "*"
+
+// ComponentWildcard, listed in a component name list, keeps every component
+// of the family, while an empty list keeps none but the referenced ones. An
+// unset components section keeps every component of every family.
+const ComponentWildcard = "*"
+
+// httpMethods are the methods an operation can be defined for.
+var httpMethods = []string{
+	http.MethodConnect,
+	http.MethodDelete,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPatch,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodTrace,
+}
+
+// safeMethods are the methods kept by ReadOnly.
+var safeMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+// KeepsMethod reports whether operations of the method may be kept, which
+// ReadOnly restricts to the safe methods. The method is case-insensitive.
+func (fc *FilterConfig) KeepsMethod(method string) bool {
+	return !fc.ReadOnly || slices.Contains(safeMethods, strings.ToUpper(method))
+}
+
+// Normalize validates the config, resolves the path preset references of its
+// filter configurations and resolves them against spec, see
+// [FilterConfig.Normalize].
+func (c *Config) Normalize(spec *openapi3.T) error {
+	var errs []error
+	if err := c.Tool.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.validateOutputs(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.resolvePathPresets(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.FilterConfig.Normalize(spec); err != nil {
+		errs = append(errs, err)
+	}
+	for _, name := range c.OutputNames() {
+		output := c.Outputs[name]
+		if err := output.Normalize(spec); err != nil {
+			errs = append(errs, fmt.Errorf("outputs.%s: %w", name, err))
+		}
+		c.Outputs[name] = output
+	}
+	return errors.Join(errs...)
+}
+
+// Validate reports the problems Normalize would report, leaving c unchanged.
+func (c *Config) Validate(spec *openapi3.T) error {
+	normalized := walk.Clone(*c)
+	return normalized.Normalize(spec)
+}
+
+// NormalizedFilterConfig returns a copy of the filter configuration of c with
+// its path preset references resolved, normalized against spec, see
+// [FilterConfig.Normalize]. c is left unchanged, so it can be used again with
+// other specs.
+func (c *Config) NormalizedFilterConfig(spec *openapi3.T) (*FilterConfig, error) {
+	normalized := walk.Clone(*c)
+	if err := normalized.resolvePathPresets(); err != nil {
+		return nil, err
+	}
+	if err := normalized.FilterConfig.Normalize(spec); err != nil {
+		return nil, err
+	}
+	return &normalized.FilterConfig, nil
+}
+
+// Normalize validates the filter configuration and resolves it against spec
+// into its canonical form, which the filter operates on:
+//   - path preset references left unresolved by Config.Normalize are errors;
+//   - path keys with glob patterns ("*", "?", "[...]", e.g. "/pets/*") are
+//     replaced by the paths of spec they match. An explicitly listed path
+//     overrides the patterns matching it;
+//   - when DefaultPath is set, the paths of spec matching no key are added
+//     with DefaultPath as their configuration. When only ExcludePaths selects
+//     paths, every path of spec is added with all of its methods;
+//   - paths matching ExcludePaths are removed;
+//   - methods are uppercased, and the "*" method and method groups (e.g.
+//     "@safe") are replaced by the methods of the operations defined for the
+//     path in spec. A listed path with a method it does not define in spec
+//     is an error, while such methods are dropped from the paths selected by
+//     patterns or DefaultPath;
+//   - component names with glob patterns (e.g. "User*") are replaced by the
+//     names of the components of spec they match, including in the
+//     components of path configs. A nil Components, i.e. an
+//     unset components section, lists every component of spec;
+//   - a nil Paths map is replaced by an empty one;
+//   - PathPrefix gets a single leading slash and no trailing slash.
+//
+// All problems are reported together. Normalizing an already normalized config
+// against the same spec leaves it unchanged.
+func (fc *FilterConfig) Normalize(spec *openapi3.T) error {
+	var errs []error
+	if err := fc.CollapseUnions.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("collapseUnions: %w", err))
+	}
+	if fc.MaxExamplesPerMediaType < 0 {
+		errs = append(errs, fmt.Errorf("maxExamplesPerMediaType: must not be negative: %d", fc.MaxExamplesPerMediaType))
+	}
+	if _, err := fc.VersionRegexp(); err != nil {
+		errs = append(errs, fmt.Errorf("versionPattern: %w", err))
+	}
+	if version, err := fc.OpenAPIVersion(); err != nil {
+		errs = append(errs, fmt.Errorf("outputVersion: %w", err))
+	} else if fc.ValidateOutput && strings.HasPrefix(version, "3.1.") {
+		// kin-openapi rejects the "null" type of 3.1 schemas
+		errs = append(errs, fmt.Errorf("validateOutput: not supported with outputVersion %s, only 3.0 specs are validated", fc.OutputVersion))
+	}
+
+	// Known path presets are resolved by Config.Normalize
+	for _, path := range slices.Sorted(maps.Keys(fc.Paths)) {
+		if preset := fc.Paths[path].Preset; preset != "" {
+			errs = append(errs, fmt.Errorf("paths.%s: unknown path preset: %s%s", path, MethodPresetPrefix, preset))
+		}
+	}
+	if fc.DefaultPath != nil && fc.DefaultPath.Preset != "" {
+		errs = append(errs, fmt.Errorf("defaultPath: unknown path preset: %s%s", MethodPresetPrefix, fc.DefaultPath.Preset))
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(fc.Paths)) {
+		if cc := fc.Paths[path].Components; cc != nil {
+			if err := cc.expandNames(spec.Components); err != nil {
+				errs = append(errs, fmt.Errorf("paths.%s.components: %w", path, err))
+			}
+		}
+	}
+	if fc.DefaultPath != nil && fc.DefaultPath.Components != nil {
+		if err := fc.DefaultPath.Components.expandNames(spec.Components); err != nil {
+			errs = append(errs, fmt.Errorf("defaultPath.components: %w", err))
+		}
+	}
+
+	paths, err := expandPaths(fc.Paths, spec)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	defaultPath := fc.DefaultPath
+	if defaultPath == nil && fc.keepsAllPaths() {
+		defaultPath = &PathConfig{Methods: []string{MethodWildcard}}
+	}
+	if err := addDefaultPaths(paths, defaultPath, spec); err != nil {
+		errs = append(errs, fmt.Errorf("defaultPath: %w", err))
+	}
+	if err := validatePatterns(fc.ExcludePaths); err != nil {
+		errs = append(errs, fmt.Errorf("excludePaths: %w", err))
+	}
+	if err := fc.validateComponentRenames(); err != nil {
+		errs = append(errs, fmt.Errorf("componentRenames: %w", err))
+	}
+	if err := fc.validateTagOperationLimits(); err != nil {
+		errs = append(errs, fmt.Errorf("maxOperationsPerTag: %w", err))
+	}
+	if fc.VersionGate != nil {
+		if err := fc.VersionGate.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("versionGate: %w", err))
+		}
+	}
+	if fc.Info != nil {
+		if err := fc.Info.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("info: %w", err))
+		}
+	}
+	for _, entry := range fc.ExcludeParameterNames {
+		if _, in, ok := strings.Cut(entry, ":"); ok && strings.EqualFold(in, openapi3.ParameterInPath) {
+			errs = append(errs, fmt.Errorf("excludeParameterNames: %s: path parameters cannot be excluded, path templates reference them", entry))
+		}
+	}
+	for _, location := range fc.ExcludeParameterLocations {
+		switch strings.ToLower(location) {
+		case openapi3.ParameterInQuery, openapi3.ParameterInHeader, openapi3.ParameterInCookie:
+		case openapi3.ParameterInPath:
+			// Every path parameter is referenced by its path template
+			errs = append(errs, fmt.Errorf("excludeParameterLocations: path parameters cannot be excluded, path templates reference them"))
+		default:
+			errs = append(errs, fmt.Errorf("excludeParameterLocations: unknown parameter location: %s", location))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(fc.ExcludeEnumValues)) {
+		if spec.Components == nil || spec.Components.Schemas[name] == nil {
+			errs = append(errs, fmt.Errorf("excludeEnumValues.%s: schema not found in spec", name))
+		}
+	}
+	for _, from := range slices.Sorted(maps.Keys(fc.MediaTypeRewrites)) {
+		if strings.TrimSpace(fc.MediaTypeRewrites[from]) == "" {
+			errs = append(errs, fmt.Errorf("mediaTypeRewrites.%s: empty target media type", from))
+		}
+	}
+	fc.PathPrefix = normalizePathPrefix(fc.PathPrefix)
+	for _, pointer := range fc.RemovePointers {
+		if _, err := ParsePointer(pointer); err != nil {
+			errs = append(errs, fmt.Errorf("removePointers: %w", err))
+		}
+	}
+	maps.DeleteFunc(paths, func(path string, _ PathConfig) bool {
+		return fc.IsPathExcluded(path)
+	})
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		pathConfig := paths[path]
+		pathItem := spec.Paths.Value(path)
+		methods, err := normalizeMethods(pathConfig.Methods, pathItem)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("paths.%s: %w", path, err))
+		}
+		if _, listed := fc.Paths[path]; listed {
+			if err := checkMethodsDefined(methods, pathItem); err != nil {
+				errs = append(errs, fmt.Errorf("paths.%s: %w", path, err))
+			}
+		} else {
+			methods = definedMethods(methods, pathItem)
+		}
+		pathConfig.Methods = methods
+		pathConfig.Responses = normalizeResponses(pathConfig.Responses)
+		paths[path] = pathConfig
+	}
+	fc.Paths = paths
+
+	if fc.Components == nil {
+		fc.Components = allComponents()
+	}
+	if err := fc.Components.expandNames(spec.Components); err != nil {
+		errs = append(errs, fmt.Errorf("components: %w", err))
+	}
+
+	for i := range fc.Rules {
+		for j, method := range fc.Rules[i].Methods {
+			fc.Rules[i].Methods[j] = strings.ToUpper(method)
+			if !slices.Contains(httpMethods, fc.Rules[i].Methods[j]) {
+				errs = append(errs, fmt.Errorf("rules[%d]: unknown HTTP method: %s", i, method))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isGlobPattern reports whether a paths key or a component name is a glob
+// pattern rather than a literal. Paths and component names cannot contain
+// glob metacharacters.
+func isGlobPattern(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// expandPaths replaces the glob pattern keys of paths by the paths of spec
+// they match, as with SelectionRule.Glob. A pattern matching no path, or a
+// path matched by several patterns, is an error.
+func expandPaths(paths map[string]PathConfig, spec *openapi3.T) (map[string]PathConfig, error) {
+	expanded := make(map[string]PathConfig, len(paths))
+	matchedBy := make(map[string]string)
+	var errs []error
+
+	patterns := make([]string, 0, len(paths))
+	for path, pathConfig := range paths {
+		if isGlobPattern(path) {
+			patterns = append(patterns, path)
+			continue
+		}
+		expanded[path] = pathConfig
+	}
+	slices.Sort(patterns)
+
+	var specPaths []string
+	if spec.Paths != nil {
+		specPaths = spec.Paths.InMatchingOrder()
+	}
+	for _, pattern := range patterns {
+		if _, err := pathpkg.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("paths.%s: invalid path pattern: %w", pattern, err))
+			continue
+		}
+		matched := false
+		for _, path := range specPaths {
+			if ok, _ := pathpkg.Match(pattern, path); !ok {
+				continue
+			}
+			matched = true
+			if _, ok := paths[path]; ok {
+				continue // Listed explicitly
+			}
+			if other, ok := matchedBy[path]; ok {
+				errs = append(errs, fmt.Errorf("paths.%s: path %s already matched by pattern %s", pattern, path, other))
+				continue
+			}
+			matchedBy[path] = pattern
+			expanded[path] = paths[pattern]
+		}
+		if !matched {
+			errs = append(errs, fmt.Errorf("paths.%s: pattern matches no path in spec", pattern))
+		}
+	}
+	return expanded, errors.Join(errs...)
+}
+
+// keepsAllPaths reports whether ExcludePaths is the only path selection, which
+// keeps every path of the spec but the excluded ones.
+func (fc *FilterConfig) keepsAllPaths() bool {
+	return len(fc.ExcludePaths) != 0 && len(fc.Paths) == 0 && fc.DefaultPath == nil &&
+		len(fc.Rules) == 0 && len(fc.IncludeOperationIds) == 0 && !fc.HasOverrideSelection()
+}
+
+// IsPathExcluded reports whether the path matches a pattern of ExcludePaths,
+// as with SelectionRule.Glob. Malformed patterns never match.
+func (fc *FilterConfig) IsPathExcluded(path string) bool {
+	return matchAny(fc.ExcludePaths, path)
+}
+
+// validatePatterns reports an error for each malformed glob pattern.
+func validatePatterns(patterns []string) error {
+	var errs []error
+	for _, pattern := range patterns {
+		if _, err := pathpkg.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid pattern %s: %w", pattern, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// allComponents returns the components config listing every component, the
+// meaning of an unset components section.
+func allComponents() *FilterComponentsConfig {
+	all := []string{ComponentWildcard}
+	return &FilterComponentsConfig{
+		Schemas:         all,
+		Parameters:      all,
+		SecuritySchemes: all,
+		RequestBodies:   all,
+		Responses:       all,
+		Headers:         all,
+		Examples:        all,
+		Links:           all,
+		Callbacks:       all,
+		PathItems:       all,
+	}
+}
+
+// addDefaultPaths adds the paths of spec missing from paths with defaultPath
+// as their configuration. Nothing is added if defaultPath is nil or has an
+// unknown method, which is reported once rather than for every added path.
+func addDefaultPaths(paths map[string]PathConfig, defaultPath *PathConfig, spec *openapi3.T) error {
+	if defaultPath == nil || spec.Paths == nil {
+		return nil
+	}
+	if _, err := normalizeMethods(defaultPath.Methods, nil); err != nil {
+		return err
+	}
+	for _, path := range spec.Paths.InMatchingOrder() {
+		if _, ok := paths[path]; !ok {
+			paths[path] = *defaultPath
+		}
+	}
+	return nil
+}
+
+// expandNames replaces the glob patterns of the component name lists by the
+// names of the components of comps they match, in sorted order. A pattern
+// matching no component is an error, except ComponentWildcard, as keeping all
+// components of an empty family is not a mistake.
+func (cc *FilterComponentsConfig) expandNames(comps *openapi3.Components) error {
+	if comps == nil {
+		comps = &openapi3.Components{}
+	}
+	var errs []error
+	for _, list := range []struct {
+		key   string
+		names *[]string
+		keys  []string
+	}{
+		{"schemas", &cc.Schemas, sortedKeys(comps.Schemas)},
+		{"parameters", &cc.Parameters, sortedKeys(comps.Parameters)},
+		{"securitySchemes", &cc.SecuritySchemes, sortedKeys(comps.SecuritySchemes)},
+		{"requestBodies", &cc.RequestBodies, sortedKeys(comps.RequestBodies)},
+		{"responses", &cc.Responses, sortedKeys(comps.Responses)},
+		{"headers", &cc.Headers, sortedKeys(comps.Headers)},
+		{"examples", &cc.Examples, sortedKeys(comps.Examples)},
+		{"links", &cc.Links, sortedKeys(comps.Links)},
+		{"callbacks", &cc.Callbacks, sortedKeys(comps.Callbacks)},
+		{oas31.PathItemsDef, &cc.PathItems, sortedKeys(oas31.PathItems(comps))},
+	} {
+		expanded := make([]string, 0, len(*list.names))
+		add := func(name string) {
+			if !slices.Contains(expanded, name) {
+				expanded = append(expanded, name)
+			}
+		}
+		for _, name := range *list.names {
+			if !isGlobPattern(name) {
+				add(name)
+				continue
+			}
+			if _, err := pathpkg.Match(name, ""); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid name pattern %s: %w", list.key, name, err))
+				continue
+			}
+			matched := false
+			for _, key := range list.keys {
+				if ok, _ := pathpkg.Match(name, key); ok {
+					matched = true
+					add(key)
+				}
+			}
+			if !matched && name != ComponentWildcard {
+				errs = append(errs, fmt.Errorf("%s: pattern %s matches no component in spec", list.key, name))
+			}
+		}
+		*list.names = expanded
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys[M ~map[string]V, V any](m M) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// normalizeMethods uppercases methods, expands the "*" method and method
+// groups to the operations of pathItem and drops duplicates. Without
+// pathItem, i.e. for a path missing from the spec, they expand to nothing.
+func normalizeMethods(methods []string, pathItem *openapi3.PathItem) ([]string, error) {
+	var errs []error
+	normalized := make([]string, 0, len(methods))
+	add := func(method string) {
+		if !slices.Contains(normalized, method) {
+			normalized = append(normalized, method)
+		}
+	}
+	for _, method := range methods {
+		if method == MethodWildcard {
+			if pathItem != nil {
+				for _, method := range httpMethods {
+					if pathItem.GetOperation(method) != nil {
+						add(method)
+					}
+				}
+			}
+			continue
+		}
+		if isMethodGroup(method) {
+			group, err := methodGroup(method)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, method := range group {
+				if pathItem != nil && pathItem.GetOperation(method) != nil {
+					add(method)
+				}
+			}
+			continue
+		}
+		upper := strings.ToUpper(method)
+		if !slices.Contains(httpMethods, upper) {
+			errs = append(errs, fmt.Errorf("unknown HTTP method: %s", method))
+			continue
+		}
+		add(upper)
+	}
+	return normalized, errors.Join(errs...)
+}
+
+// normalizePathPrefix returns the prefix with a single leading slash and no
+// trailing slash, so that prefixed paths have no "//". A prefix of slashes
+// only is empty.
+func normalizePathPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return "/" + prefix
+}
+
+// checkMethodsDefined reports an error for each of the normalized methods
+// with no operation defined for pathItem, e.g. a method listed by mistake
+// for a similar path. Nothing is reported for a path missing from the spec.
+func checkMethodsDefined(methods []string, pathItem *openapi3.PathItem) error {
+	if pathItem == nil {
+		return nil
+	}
+	var errs []error
+	for _, method := range methods {
+		if pathItem.GetOperation(method) == nil {
+			errs = append(errs, fmt.Errorf("method %s not defined in spec", method))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// definedMethods returns the normalized methods with an operation defined
+// for pathItem. Paths selected by a pattern or by DefaultPath share their
+// methods, so the methods a path does not define are dropped rather than
+// reported.
+func definedMethods(methods []string, pathItem *openapi3.PathItem) []string {
+	if pathItem == nil {
+		return methods
+	}
+	return slices.DeleteFunc(methods, func(method string) bool {
+		return pathItem.GetOperation(method) == nil
+	})
+}
+
+// VersionRegexp compiles VersionPattern, or DefaultVersionPattern when unset.
+// The pattern must have a capture group for the version number.
+func (fc *FilterConfig) VersionRegexp() (*regexp.Regexp, error) {
+	pattern := fc.VersionPattern
+	if pattern == "" {
+		pattern = DefaultVersionPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("regexp.Compile: %w", err)
+	}
+	if re.NumSubexp() < 1 {
+		return nil, fmt.Errorf("must have a capture group for the version number: %s", pattern)
+	}
+	return re, nil
+}
+
+// OpenAPI versions OutputVersion can set, by minor version, with the patch
+// version used when only the minor version is set.
+var outputVersions = map[string]string{
+	"3.0": "3.0.4",
+	"3.1": "3.1.1",
+}
+
+var outputVersionPattern = regexp.MustCompile(`^(\d+\.\d+)(\.\d+)?$`)
+
+// OpenAPIVersion returns the OpenAPI version of the filtered spec set by
+// OutputVersion, with the patch version of outputVersions when only the
+// minor version is set, or "" when unset.
+func (fc *FilterConfig) OpenAPIVersion() (string, error) {
+	if fc.OutputVersion == "" {
+		return "", nil
+	}
+	match := outputVersionPattern.FindStringSubmatch(fc.OutputVersion)
+	if match == nil {
+		return "", fmt.Errorf("invalid version: %s", fc.OutputVersion)
+	}
+	patched, ok := outputVersions[match[1]]
+	if !ok {
+		return "", fmt.Errorf("unsupported version %s, expected 3.0 or 3.1", fc.OutputVersion)
+	}
+	if match[2] != "" {
+		return fc.OutputVersion, nil
+	}
+	return patched, nil
+}
+
+// Validate reports an error for an unsupported strategy. The empty strategy
+// disables collapsing.
+func (cs CollapseStrategy) Validate() error {
+	switch cs {
+	case "", CollapseUnionsFirst, CollapseUnionsByDiscriminator:
+		return nil
+	default:
+		return fmt.Errorf("unsupported strategy: %s", cs)
+	}
+}
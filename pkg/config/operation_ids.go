@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// HasOperationIDSelection reports whether operations are selected or excluded
+// by operationId.
+func (fc *FilterConfig) HasOperationIDSelection() bool {
+	return len(fc.IncludeOperationIds) != 0 || len(fc.ExcludeOperationIds) != 0
+}
+
+// IsOperationIDIncluded reports whether the operationId is listed in
+// IncludeOperationIds. An empty operationId is never included.
+func (fc *FilterConfig) IsOperationIDIncluded(id string) bool {
+	return id != "" && slices.Contains(fc.IncludeOperationIds, id)
+}
+
+// IsOperationIDExcluded reports whether the operationId is listed in
+// ExcludeOperationIds. An empty operationId is never excluded.
+func (fc *FilterConfig) IsOperationIDExcluded(id string) bool {
+	return id != "" && slices.Contains(fc.ExcludeOperationIds, id)
+}
+
+// addOperationIDs adds the operations of pathItem, and of their callbacks,
+// to the operations by operationId.
+func addOperationIDs(operations map[string][]string, pathItem *openapi3.PathItem, path string) {
+	for method, op := range pathItem.Operations() {
+		if op.OperationID != "" {
+			operations[op.OperationID] = append(operations[op.OperationID], method+" "+path)
+		}
+		for name, cbr := range op.Callbacks {
+			if cbr == nil || cbr.Value == nil {
+				continue
+			}
+			for expression, cbPathItem := range cbr.Value.Map() {
+				if cbPathItem != nil {
+					addOperationIDs(operations, cbPathItem, fmt.Sprintf("%s callback %s %s", path, name, expression))
+				}
+			}
+		}
+	}
+}
+
+// ValidateOperationIDs reports the problems making selection by operationId
+// ambiguous: operationIds shared by several operations of the spec, including
+// callback operations, and listed operationIds not found in the spec. It returns nil if no operationIds are
+// listed.
+func (fc *FilterConfig) ValidateOperationIDs(doc *openapi3.T) []string {
+	if !fc.HasOperationIDSelection() {
+		return nil
+	}
+
+	operations := make(map[string][]string)
+	for _, path := range doc.Paths.InMatchingOrder() {
+		addOperationIDs(operations, doc.Paths.Value(path), path)
+	}
+
+	var problems []string
+	for _, id := range slices.Sorted(maps.Keys(operations)) {
+		if ops := operations[id]; len(ops) > 1 {
+			slices.Sort(ops)
+			problems = append(problems, fmt.Sprintf("duplicate operationId in spec: %s (%s)",
+				id, strings.Join(ops, ", ")))
+		}
+	}
+	for _, id := range slices.Concat(fc.IncludeOperationIds, fc.ExcludeOperationIds) {
+		if _, ok := operations[id]; !ok {
+			problems = append(problems, "operationId not found in spec: "+id)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"maps"
+	"slices"
+)
+
+// OperationOverridesConfig maps the settings operations declare in their own
+// x-openapi-filter extension, e.g. `x-openapi-filter: {visibility: internal}`,
+// to filtering decisions, so spec authors can control filtering locally.
+// Settings are compared like ExcludeByExtension values. The extension is
+// always stripped from the filtered spec.
+type OperationOverridesConfig struct {
+	Include map[string]any `koanf:"include"` // Keep operations declaring any of these settings, on any path
+	Exclude map[string]any `koanf:"exclude"` // Drop operations declaring any of these settings, wins over any selection
+}
+
+// HasOverrideSelection reports whether operations are selected by the
+// settings of their x-openapi-filter extension.
+func (fc *FilterConfig) HasOverrideSelection() bool {
+	return fc.OperationOverrides != nil && len(fc.OperationOverrides.Include) != 0
+}
+
+// MatchIncludedOverride returns the key of the first Include setting, in key
+// order, declared with the same value by the operation extensions exts.
+func (fc *FilterConfig) MatchIncludedOverride(exts map[string]any) (key string, ok bool) {
+	if fc.OperationOverrides == nil {
+		return "", false
+	}
+	return matchExtensionValues(fc.OperationOverrides.Include, operationSettings(exts))
+}
+
+// MatchExcludedOverride returns the key of the first Exclude setting, in key
+// order, declared with the same value by the operation extensions exts.
+func (fc *FilterConfig) MatchExcludedOverride(exts map[string]any) (key string, ok bool) {
+	if fc.OperationOverrides == nil {
+		return "", false
+	}
+	return matchExtensionValues(fc.OperationOverrides.Exclude, operationSettings(exts))
+}
+
+// operationSettings returns the x-openapi-filter object of the operation
+// extensions, or nil if it is missing or not an object.
+func operationSettings(exts map[string]any) map[string]any {
+	settings, _ := exts[ToolConfigKey].(map[string]any)
+	return settings
+}
+
+// matchExtensionValues returns the first key of want, in key order, whose
+// value equals the value of the same key in got.
+func matchExtensionValues(want, got map[string]any) (key string, ok bool) {
+	if len(want) == 0 || len(got) == 0 {
+		return "", false
+	}
+	for _, key := range slices.Sorted(maps.Keys(want)) {
+		if value, ok := got[key]; ok && extensionValueEqual(want[key], value) {
+			return key, true
+		}
+	}
+	return "", false
+}
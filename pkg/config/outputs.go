@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+	"maps"
+	"slices"
+)
+
+// IsMultiOutput reports whether the config defines several outputs, each
+// filtered from the same input spec with its own filter configuration.
+func (c *Config) IsMultiOutput() bool {
+	return len(c.Outputs) != 0
+}
+
+// OutputNames returns the output file names, sorted.
+func (c *Config) OutputNames() []string {
+	return slices.Sorted(maps.Keys(c.Outputs))
+}
+
+// OutputConfig returns the config of the named output: its own filter
+// configuration with the tool settings and presets of c. The root filter
+// configuration is not inherited. Outputs are filtered independently, so each one only gets
+// the components it references, even if they are shared with other outputs.
+func (c *Config) OutputConfig(name string) *Config {
+	return &Config{
+		Tool:          c.Tool,
+		MethodPresets: c.MethodPresets,
+		PathPresets:   c.PathPresets,
+		FilterConfig:  c.Outputs[name],
+	}
+}
+
+// validateOutputs reports an error if outputs are set along with a root filter
+// configuration, which would be ignored, or if an output name is empty.
+func (c *Config) validateOutputs() error {
+	if !c.IsMultiOutput() {
+		return nil
+	}
+	var errs []error
+	if len(c.Paths) != 0 || c.DefaultPath != nil || len(c.ExcludePaths) != 0 ||
+		len(c.Rules) != 0 || len(c.IncludeOperationIds) != 0 || c.HasOverrideSelection() {
+		errs = append(errs, errors.New("outputs: paths, defaultPath, excludePaths, rules, includeOperationIds and operationOverrides.include must be set per output"))
+	}
+	if _, ok := c.Outputs[""]; ok {
+		errs = append(errs, errors.New("outputs: empty output name"))
+	}
+	return errors.Join(errs...)
+}
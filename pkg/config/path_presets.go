@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// PathPresets maps preset names to shared path configs. A path config
+// references a preset with a string in place of its methods list or object,
+// as in `/admin/users: "$admin"`, using the MethodPresetPrefix. References
+// are kept when the config is loaded, and resolved by Config.Normalize.
+// Method presets are expanded first, so a name cannot be both.
+type PathPresets map[string]PathConfig
+
+// resolvePathPresets replaces the path preset references of the filter
+// configurations of c by copies of the presets.
+func (c *Config) resolvePathPresets() error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(c.PathPresets)) {
+		preset := c.PathPresets[name]
+		if preset.Preset != "" {
+			errs = append(errs, fmt.Errorf("pathPresets.%s: path presets cannot reference path presets", name))
+		}
+		if _, ok := c.MethodPresets[name]; ok {
+			errs = append(errs, fmt.Errorf("pathPresets.%s: name already used by a method preset", name))
+		}
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+
+	c.PathPresets.resolve(&c.FilterConfig)
+	for _, name := range c.OutputNames() {
+		output := c.Outputs[name]
+		c.PathPresets.resolve(&output)
+		c.Outputs[name] = output
+	}
+	return nil
+}
+
+// resolve replaces the known path preset references of the paths and default
+// path of fc. Unknown references are left for FilterConfig.Normalize to report.
+func (pp PathPresets) resolve(fc *FilterConfig) {
+	for path, pathConfig := range fc.Paths {
+		if resolved, ok := pp.lookup(pathConfig); ok {
+			fc.Paths[path] = resolved
+		}
+	}
+	if fc.DefaultPath != nil {
+		if resolved, ok := pp.lookup(*fc.DefaultPath); ok {
+			fc.DefaultPath = &resolved
+		}
+	}
+}
+
+// lookup returns a copy of the preset referenced by pc, so that normalizing
+// the path config leaves the preset unchanged.
+func (pp PathPresets) lookup(pc PathConfig) (PathConfig, bool) {
+	if pc.Preset == "" {
+		return PathConfig{}, false
+	}
+	preset, ok := pp[pc.Preset]
+	if !ok {
+		return PathConfig{}, false
+	}
+	preset.Methods = slices.Clone(preset.Methods)
+	preset.Responses = maps.Clone(preset.Responses)
+	return preset, true
+}
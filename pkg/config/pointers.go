@@ -0,0 +1,30 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParsePointer returns the unescaped reference tokens of a JSON Pointer
+// (RFC 6901), e.g. ["paths", "/users", "get"] for "/paths/~1users/get". The
+// empty pointer, referencing the whole document, is rejected, as the
+// document cannot be removed.
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, errors.New("empty pointer")
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("pointer must start with '/': %s", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid escape in pointer: %s", pointer)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyLines returns the 1-based line numbers at which the given keys are
+// defined in the config file. It recognizes YAML (`key:`), JSON (`"key":`)
+// and TOML (`"key" =` or `[paths."key"]`) definitions. Keys that cannot be
+// located are omitted from the result.
+func KeyLines(configPath string, keys []string) (map[string]int, error) {
+	f, err := os.Open(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	lines := make(map[string]int, len(keys))
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, string(utf8BOM))
+		}
+		line = strings.TrimSpace(line)
+		for _, key := range keys {
+			if _, ok := lines[key]; ok {
+				continue
+			}
+			if isKeyDefinition(line, key) {
+				lines[key] = lineNum
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner.Scan: %w", err)
+	}
+	return lines, nil
+}
+
+// isKeyDefinition reports whether the trimmed config line defines the key.
+func isKeyDefinition(line, key string) bool {
+	for _, quoted := range []string{key, `"` + key + `"`, `'` + key + `'`} {
+		rest, ok := strings.CutPrefix(line, quoted)
+		if ok {
+			rest = strings.TrimSpace(rest)
+			if strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "=") {
+				return true
+			}
+		}
+		if quoted != key && strings.HasPrefix(line, "[") &&
+			strings.Contains(line, "."+quoted) {
+			return true // TOML table header
+		}
+	}
+	return false
+}
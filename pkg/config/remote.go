@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/knadh/koanf/providers/file"
+)
+
+// isRemoteConfig reports whether the config path is an HTTP(S) URL.
+func isRemoteConfig(configPath string) bool {
+	return strings.HasPrefix(configPath, "http://") || strings.HasPrefix(configPath, "https://")
+}
+
+// configFormat returns the format of the config at configPath, named after
+// its extension. The query and fragment of a URL are ignored.
+func configFormat(configPath string) string {
+	if isRemoteConfig(configPath) {
+		if u, err := url.Parse(configPath); err == nil {
+			return strings.TrimLeft(path.Ext(u.Path), ".")
+		}
+	}
+	return strings.TrimLeft(filepath.Ext(configPath), ".")
+}
+
+// utf8BOM is the byte order mark some Windows editors prepend to UTF-8 files.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// readConfig reads the config file at configPath, or fetches it when
+// configPath is an HTTP(S) URL, within the HTTP timeout and from the allowed
+// hosts of loader, which may be nil. A leading UTF-8 BOM is stripped, as the
+// JSON and TOML parsers reject it.
+func readConfig(ctx context.Context, configPath string, loader *LoaderConfig) ([]byte, error) {
+	if !isRemoteConfig(configPath) {
+		content, err := file.Provider(configPath).ReadBytes()
+		if err != nil {
+			return nil, fmt.Errorf("file.Provider.ReadBytes: %w", err)
+		}
+		return bytes.TrimPrefix(content, utf8BOM), nil
+	}
+
+	u, err := url.Parse(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse: %w", err)
+	}
+	if !loader.IsHostAllowed(u.Hostname()) {
+		return nil, fmt.Errorf("%w: %s", ErrHostNotAllowed, u.Host)
+	}
+	ctx, cancel := context.WithTimeout(ctx, loader.HTTPTimeoutOrDefault())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http.DefaultClient.Do: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll: %w", err)
+	}
+	return bytes.TrimPrefix(content, utf8BOM), nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigWithBOM(t *testing.T) {
+	configs := map[string]string{
+		"yaml": "paths:\n  /pets: [get]\n",
+		"json": `{"paths": {"/pets": ["get"]}}`,
+		"toml": "[paths]\n\"/pets\" = [\"get\"]\n",
+	}
+	for format, content := range configs {
+		t.Run(format, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), ".openapi-filter."+format)
+			if err := os.WriteFile(configPath, append([]byte("\xef\xbb\xbf"), content...), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+			cfg, err := LoadConfig(configPath)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if !cfg.Paths["/pets"].KeepsMethod("GET") {
+				t.Errorf("got paths %v, want /pets kept with GET", cfg.Paths)
+			}
+
+			lines, err := KeyLines(configPath, []string{"paths"})
+			if err != nil {
+				t.Fatalf("KeyLines: %v", err)
+			}
+			if format == "yaml" && lines["paths"] != 1 {
+				t.Errorf("got paths key at line %d, want 1", lines["paths"])
+			}
+		})
+	}
+}
+
+func TestLoadRemoteConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/filter.yaml":
+			fmt.Fprint(w, "paths:\n  /pets: [get]\n")
+		case "/slow.yaml":
+			time.Sleep(200 * time.Millisecond)
+			fmt.Fprint(w, "paths:\n  /pets: [get]\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		loader  *LoaderConfig
+		wantErr string
+	}{
+		{"defaults", "/filter.yaml", nil, ""},
+		{"allowed host", "/filter.yaml", &LoaderConfig{AllowedHosts: []string{"127.0.0.*"}}, ""},
+		{"host not allowed", "/filter.yaml", &LoaderConfig{AllowedHosts: []string{"config.example.com"}}, "host not allowed"},
+		{"timeout", "/slow.yaml", &LoaderConfig{HTTPTimeout: 20 * time.Millisecond}, "deadline exceeded"},
+		{"not found", "/missing.yaml", nil, "404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfigWithOptions(context.Background(), srv.URL+tt.path, LoadOptions{Loader: tt.loader})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+				if !errors.Is(err, ErrConfigRead) {
+					t.Errorf("got %v, want ErrConfigRead", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfigWithOptions: %v", err)
+			}
+			if !cfg.Paths["/pets"].KeepsMethod("GET") {
+				t.Errorf("got paths %v, want /pets kept with GET", cfg.Paths)
+			}
+		})
+	}
+}
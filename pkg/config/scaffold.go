@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// scaffoldMethods are the methods of path items, in the order of the OpenAPI
+// specification.
+var scaffoldMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// WriteScaffold writes a starter YAML config for spec to w, keeping
+// everything: all paths with all their methods and all components are listed,
+// so unwanted entries only have to be deleted. Paths with path-level servers
+// use the advanced form, with preserveServers set; others use the simple form.
+func WriteScaffold(w io.Writer, spec *openapi3.T) error {
+	root := &yaml.Node{
+		Kind:        yaml.MappingNode,
+		HeadComment: "Generated by openapi-filter init. Delete what should not be kept.",
+	}
+	addBool := func(key string, keep bool, comment string) {
+		if keep {
+			root.Content = append(root.Content, scaffoldKey(key, comment), scalarNode("true", "!!bool"))
+		}
+	}
+	addBool("servers", len(spec.Servers) != 0, "Keep root servers")
+	addBool("security", len(spec.Security) != 0, "Keep global security requirements")
+	addBool("tags", len(spec.Tags) != 0, "Keep tag definitions")
+	addBool("externalDocs", spec.ExternalDocs != nil, "Keep external documentation")
+
+	paths := &yaml.Node{Kind: yaml.MappingNode}
+	for _, path := range slices.Sorted(maps.Keys(spec.Paths.Map())) {
+		paths.Content = append(paths.Content, scalarNode(path, "!!str"), scaffoldPath(spec.Paths.Value(path)))
+	}
+	root.Content = append(root.Content, scaffoldKey("paths", "Paths and methods to keep"), paths)
+
+	if comps := scaffoldComponents(spec.Components); comps != nil {
+		root.Content = append(root.Content,
+			scaffoldKey("components", "Components to keep, referenced components are kept anyway"), comps)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return fmt.Errorf("enc.Encode: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("enc.Close: %w", err)
+	}
+	return nil
+}
+
+// scaffoldPath returns the config of a path item, in the advanced form when
+// the path has path-level servers.
+func scaffoldPath(pathItem *openapi3.PathItem) *yaml.Node {
+	methods := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, method := range scaffoldMethods {
+		if pathItem.GetOperation(strings.ToUpper(method)) != nil {
+			methods.Content = append(methods.Content, scalarNode(method, "!!str"))
+		}
+	}
+	if len(pathItem.Servers) == 0 {
+		return methods
+	}
+	return &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		scalarNode("methods", "!!str"), methods,
+		scalarNode("preserveServers", "!!str"), scalarNode("true", "!!bool"),
+	}}
+}
+
+// scaffoldComponents lists the components of comps by type, or returns nil if
+// there are none.
+func scaffoldComponents(comps *openapi3.Components) *yaml.Node {
+	if comps == nil {
+		return nil
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, list := range []struct {
+		key   string
+		names []string
+	}{
+		{"schemas", slices.Sorted(maps.Keys(comps.Schemas))},
+		{"parameters", slices.Sorted(maps.Keys(comps.Parameters))},
+		{"securitySchemes", slices.Sorted(maps.Keys(comps.SecuritySchemes))},
+		{"requestBodies", slices.Sorted(maps.Keys(comps.RequestBodies))},
+		{"responses", slices.Sorted(maps.Keys(comps.Responses))},
+		{"headers", slices.Sorted(maps.Keys(comps.Headers))},
+		{"examples", slices.Sorted(maps.Keys(comps.Examples))},
+		{"links", slices.Sorted(maps.Keys(comps.Links))},
+		{"callbacks", slices.Sorted(maps.Keys(comps.Callbacks))},
+	} {
+		if len(list.names) == 0 {
+			continue
+		}
+		names := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, name := range list.names {
+			names.Content = append(names.Content, scalarNode(name, "!!str"))
+		}
+		node.Content = append(node.Content, scalarNode(list.key, "!!str"), names)
+	}
+	if len(node.Content) == 0 {
+		return nil
+	}
+	return node
+}
+
+func scaffoldKey(key, comment string) *yaml.Node {
+	n := scalarNode(key, "!!str")
+	n.HeadComment = comment
+	return n
+}
+
+func scalarNode(value, tag string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
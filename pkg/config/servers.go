@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ServersConfig selects the servers kept in the filtered spec. It supports
+// both a boolean, keeping all or none of the root servers (backward
+// compatible), and a list of server URL glob patterns. Patterns select the
+// server entries kept at every level: root, path and operation. An empty
+// list keeps no servers, as false does.
+// In patterns, "*" matches any sequence of characters, including "/", and
+// "?" matches a single character.
+type ServersConfig struct {
+	Enabled  bool     // Keep root servers
+	Patterns []string // Server URL glob patterns, empty keeps all servers
+}
+
+// newServersConfig returns the config of a list of URL patterns. An empty
+// list is the same as false, rather than a filter keeping every server.
+func newServersConfig(patterns []string) ServersConfig {
+	if len(patterns) == 0 {
+		return ServersConfig{}
+	}
+	return ServersConfig{Enabled: true, Patterns: patterns}
+}
+
+// KeepsRoot reports whether root servers are kept. Setting patterns implies
+// keeping the matching root servers.
+func (sc ServersConfig) KeepsRoot() bool {
+	return sc.Enabled || len(sc.Patterns) != 0
+}
+
+// IsFiltered reports whether server entries are selected by URL patterns.
+func (sc ServersConfig) IsFiltered() bool {
+	return len(sc.Patterns) != 0
+}
+
+// Matches reports whether a server with the given URL is kept. Every server
+// is kept when no patterns are set.
+func (sc ServersConfig) Matches(url string) bool {
+	if !sc.IsFiltered() {
+		return true
+	}
+	for _, pattern := range sc.Patterns {
+		if globToRegexp(pattern).MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling to support both boolean
+// format (backward compatible) and list of URL patterns format.
+func (sc *ServersConfig) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*sc = ServersConfig{Enabled: enabled}
+		return nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(data, &patterns); err == nil {
+		*sc = newServersConfig(patterns)
+		return nil
+	}
+
+	return fmt.Errorf("invalid servers format: expected boolean or array of URL patterns")
+}
+
+// UnmarshalYAML implements custom YAML unmarshaling to support both boolean
+// format (backward compatible) and list of URL patterns format.
+func (sc *ServersConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*sc = ServersConfig{Enabled: enabled}
+		return nil
+	}
+
+	var patterns []string
+	if err := unmarshal(&patterns); err == nil {
+		*sc = newServersConfig(patterns)
+		return nil
+	}
+
+	return fmt.Errorf("invalid servers format: expected boolean or array of URL patterns")
+}
+
+// DecodeMapstructure implements custom decoding for mapstructure (used by koanf).
+// Strings, as set by environment variables, are parsed as a boolean or a
+// comma-separated list of URL patterns.
+func (sc *ServersConfig) DecodeMapstructure(from interface{}) error {
+	val := reflect.ValueOf(from)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Bool:
+		*sc = ServersConfig{Enabled: val.Bool()}
+		return nil
+
+	case reflect.String:
+		// Environment variables set a boolean or a comma-separated list
+		if enabled, err := strconv.ParseBool(val.String()); err == nil {
+			*sc = ServersConfig{Enabled: enabled}
+			return nil
+		}
+		*sc = newServersConfig(splitList(val.String()))
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		patterns := make([]string, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			pattern, ok := val.Index(i).Interface().(string)
+			if !ok {
+				return fmt.Errorf("servers pattern must be a string, got %T", val.Index(i).Interface())
+			}
+			patterns[i] = pattern
+		}
+		*sc = newServersConfig(patterns)
+		return nil
+	}
+
+	return fmt.Errorf("invalid servers format: expected boolean or array of URL patterns, got %v", val.Kind())
+}
+
+// globToRegexp converts a URL glob pattern to an anchored regular expression.
+func globToRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
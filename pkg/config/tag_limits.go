@@ -0,0 +1,34 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// TagWildcard, as a MaxOperationsPerTag key, sets the limit of the tags not
+// listed.
+const TagWildcard = "*"
+
+// TagOperationLimit returns the max number of kept operations of the tag, and
+// whether it is limited.
+func (fc *FilterConfig) TagOperationLimit(tag string) (int, bool) {
+	if limit, ok := fc.MaxOperationsPerTag[tag]; ok {
+		return limit, true
+	}
+	limit, ok := fc.MaxOperationsPerTag[TagWildcard]
+	return limit, ok
+}
+
+// validateTagOperationLimits reports the negative limits of
+// MaxOperationsPerTag.
+func (fc *FilterConfig) validateTagOperationLimits() error {
+	var errs []error
+	for _, tag := range slices.Sorted(maps.Keys(fc.MaxOperationsPerTag)) {
+		if limit := fc.MaxOperationsPerTag[tag]; limit < 0 {
+			errs = append(errs, fmt.Errorf("%s: must not be negative: %d", tag, limit))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// TagsConfig selects the root tag definitions kept in the filtered spec. It
+// supports both a boolean, keeping all or none of them (backward
+// compatible), and a list of the names of the tags to keep. An empty list
+// keeps no tags, as false does.
+type TagsConfig struct {
+	Enabled bool     // Keep root tag definitions
+	Names   []string // Names of the tags to keep, empty keeps all tags
+}
+
+// newTagsConfig returns the config of a list of tag names. An empty list is
+// the same as false, rather than a filter keeping every tag.
+func newTagsConfig(names []string) TagsConfig {
+	if len(names) == 0 {
+		return TagsConfig{}
+	}
+	return TagsConfig{Enabled: true, Names: names}
+}
+
+// IsFiltered reports whether tag definitions are selected by name.
+func (tc TagsConfig) IsFiltered() bool {
+	return len(tc.Names) != 0
+}
+
+// Keeps reports whether the tag definition with the given name is kept.
+func (tc TagsConfig) Keeps(name string) bool {
+	return tc.Enabled && (!tc.IsFiltered() || slices.Contains(tc.Names, name))
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling to support both boolean
+// format (backward compatible) and list of tag names format.
+func (tc *TagsConfig) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*tc = TagsConfig{Enabled: enabled}
+		return nil
+	}
+
+	var names []string
+	if err := json.Unmarshal(data, &names); err == nil {
+		*tc = newTagsConfig(names)
+		return nil
+	}
+
+	return fmt.Errorf("invalid tags format: expected boolean or array of tag names")
+}
+
+// UnmarshalYAML implements custom YAML unmarshaling to support both boolean
+// format (backward compatible) and list of tag names format.
+func (tc *TagsConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*tc = TagsConfig{Enabled: enabled}
+		return nil
+	}
+
+	var names []string
+	if err := unmarshal(&names); err == nil {
+		*tc = newTagsConfig(names)
+		return nil
+	}
+
+	return fmt.Errorf("invalid tags format: expected boolean or array of tag names")
+}
+
+// DecodeMapstructure implements custom decoding for mapstructure (used by koanf).
+// Strings, as set by environment variables, are parsed as a boolean or a
+// comma-separated list of tag names.
+func (tc *TagsConfig) DecodeMapstructure(from interface{}) error {
+	val := reflect.ValueOf(from)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Bool:
+		*tc = TagsConfig{Enabled: val.Bool()}
+		return nil
+
+	case reflect.String:
+		// Environment variables set a boolean or a comma-separated list
+		if enabled, err := strconv.ParseBool(val.String()); err == nil {
+			*tc = TagsConfig{Enabled: enabled}
+			return nil
+		}
+		*tc = newTagsConfig(splitList(val.String()))
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		names := make([]string, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			name, ok := val.Index(i).Interface().(string)
+			if !ok {
+				return fmt.Errorf("tag name must be a string, got %T", val.Index(i).Interface())
+			}
+			names[i] = name
+		}
+		*tc = newTagsConfig(names)
+		return nil
+	}
+
+	return fmt.Errorf("invalid tags format: expected boolean or array of tag names, got %v", val.Kind())
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultVersionGateExtension is the extension read by VersionGateConfig
+// when Extension is unset.
+const DefaultVersionGateExtension = "x-api-version-added"
+
+// Policies for the operations without a version annotation.
+const (
+	UnannotatedKeep = "keep"
+	UnannotatedDrop = "drop"
+)
+
+// VersionGateConfig keeps the operations whose version annotation, a semantic
+// version in an extension, is within [Since, Until]. Either bound may be
+// unset. Versions may omit their minor and patch numbers and have a "v"
+// prefix, e.g. "v2.1".
+type VersionGateConfig struct {
+	Extension   string `koanf:"extension"`   // Extension holding the version an operation was added in, defaults to "x-api-version-added"
+	Since       string `koanf:"since"`       // Keep operations added in this version or later
+	Until       string `koanf:"until"`       // Keep operations added in this version or earlier
+	Unannotated string `koanf:"unannotated"` // Policy for operations without annotation: "keep" (default) or "drop"
+}
+
+// ExtensionOrDefault returns the configured extension or
+// DefaultVersionGateExtension.
+func (vg *VersionGateConfig) ExtensionOrDefault() string {
+	if vg.Extension == "" {
+		return DefaultVersionGateExtension
+	}
+	return vg.Extension
+}
+
+// KeepsUnannotated reports whether operations without a version annotation
+// are kept.
+func (vg *VersionGateConfig) KeepsUnannotated() bool {
+	return vg.Unannotated != UnannotatedDrop
+}
+
+// Validate reports malformed bounds and an unknown policy.
+func (vg *VersionGateConfig) Validate() error {
+	var errs []error
+	if vg.Since == "" && vg.Until == "" {
+		errs = append(errs, errors.New("since or until must be set"))
+	}
+	since, err := parseSemver(vg.Since)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("since: %w", err))
+	}
+	until, err := parseSemver(vg.Until)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("until: %w", err))
+	}
+	if since != nil && until != nil && since.compare(until) > 0 {
+		errs = append(errs, fmt.Errorf("since %s is after until %s", vg.Since, vg.Until))
+	}
+	switch vg.Unannotated {
+	case "", UnannotatedKeep, UnannotatedDrop:
+	default:
+		errs = append(errs, fmt.Errorf("unannotated: unsupported policy %q, expected %q or %q",
+			vg.Unannotated, UnannotatedKeep, UnannotatedDrop))
+	}
+	return errors.Join(errs...)
+}
+
+// Keeps reports whether an operation with the given extensions passes the
+// gate. It returns an error if the annotation is not a version; callers then
+// apply the policy of unannotated operations.
+func (vg *VersionGateConfig) Keeps(exts map[string]any) (bool, error) {
+	value, ok := exts[vg.ExtensionOrDefault()]
+	if !ok {
+		return vg.KeepsUnannotated(), nil
+	}
+	var version string
+	switch value := value.(type) {
+	case string:
+		version = value
+	case float64:
+		version = strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return false, fmt.Errorf("version must be a string, got %T", value)
+	}
+	if version == "" {
+		return false, errors.New("empty version")
+	}
+	added, err := parseSemver(version)
+	if err != nil {
+		return false, err
+	}
+	// Bounds are validated by Normalize
+	if since, _ := parseSemver(vg.Since); since != nil && added.compare(since) < 0 {
+		return false, nil
+	}
+	if until, _ := parseSemver(vg.Until); until != nil && added.compare(until) > 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// semver is a parsed semantic version. Build metadata is ignored.
+type semver struct {
+	numbers    [3]int
+	prerelease []string
+}
+
+// parseSemver parses a semantic version, allowing a "v" prefix and missing
+// minor and patch numbers. An empty version parses to nil.
+func parseSemver(version string) (*semver, error) {
+	if version == "" {
+		return nil, nil
+	}
+	rest, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "+")
+	core, prerelease, hasPrerelease := strings.Cut(rest, "-")
+
+	v := &semver{}
+	parts := strings.Split(core, ".")
+	if len(parts) > len(v.numbers) {
+		return nil, fmt.Errorf("invalid version %q: too many numbers", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part != strconv.Itoa(n) {
+			return nil, fmt.Errorf("invalid version %q: %q is not a number", version, part)
+		}
+		v.numbers[i] = n
+	}
+	if hasPrerelease {
+		if prerelease == "" {
+			return nil, fmt.Errorf("invalid version %q: empty prerelease", version)
+		}
+		v.prerelease = strings.Split(prerelease, ".")
+	}
+	return v, nil
+}
+
+// compare returns -1, 0 or +1 following the semantic versioning precedence:
+// a prerelease is lower than its release, and prerelease identifiers are
+// compared numerically when both are numbers.
+func (v *semver) compare(other *semver) int {
+	if c := slices.Compare(v.numbers[:], other.numbers[:]); c != 0 {
+		return c
+	}
+	switch {
+	case v.prerelease == nil && other.prerelease == nil:
+		return 0
+	case v.prerelease == nil:
+		return 1
+	case other.prerelease == nil:
+		return -1
+	}
+	return slices.CompareFunc(v.prerelease, other.prerelease, func(a, b string) int {
+		an, aErr := strconv.Atoi(a)
+		bn, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			return cmp.Compare(an, bn)
+		case aErr == nil:
+			return -1 // Numeric identifiers are lower
+		case bErr == nil:
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+}
@@ -0,0 +1,251 @@
+// Package diff compares a source OpenAPI spec with its filtered version and
+// reports what was removed or modified, both as a human-readable summary
+// and as a machine-readable JSON structure.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ChangeKind is the kind of a modification.
+type ChangeKind string
+
+const (
+	ChangeRemoved ChangeKind = "removed"
+	ChangeAdded   ChangeKind = "added"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Operation identifies an operation of the spec.
+type Operation struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// Component identifies a component of the spec.
+type Component struct {
+	Def  string `json:"def"`
+	Name string `json:"name"`
+}
+
+// Change is a modified field of a part of the spec that is kept.
+type Change struct {
+	Pointer string     `json:"pointer"` // JSON Pointer to the field
+	Kind    ChangeKind `json:"kind"`
+}
+
+// Diff is the structured difference between a source and a filtered spec.
+type Diff struct {
+	RemovedPaths      []string    `json:"removedPaths"`
+	RemovedOperations []Operation `json:"removedOperations"`
+	RemovedComponents []Component `json:"removedComponents"`
+	Modified          []Change    `json:"modified"`
+}
+
+var operationKeys = []string{
+	"connect", "delete", "get", "head", "options", "patch", "post", "put", "trace",
+}
+
+// Snapshot is a copy of a spec's content. Filtering may modify objects shared
+// between the source and filtered specs, so the source must be captured
+// before it is filtered.
+type Snapshot struct {
+	m    map[string]any
+	size int // length of the JSON encoding
+}
+
+// TakeSnapshot captures the current content of the spec.
+func TakeSnapshot(doc *openapi3.T) (*Snapshot, error) {
+	m, size, err := toMap(doc)
+	if err != nil {
+		return nil, fmt.Errorf("toMap: %w", err)
+	}
+	return &Snapshot{m: m, size: size}, nil
+}
+
+// Compare computes the difference between the source spec snapshot and the
+// filtered spec. All lists are sorted, so the result is deterministic.
+func Compare(source *Snapshot, filtered *openapi3.T) (*Diff, error) {
+	src := source.m
+	dst, _, err := toMap(filtered)
+	if err != nil {
+		return nil, fmt.Errorf("toMap(filtered): %w", err)
+	}
+
+	d := &Diff{
+		RemovedPaths:      []string{},
+		RemovedOperations: []Operation{},
+		RemovedComponents: []Component{},
+		Modified:          []Change{},
+	}
+	for _, key := range unionKeys(src, dst) {
+		switch key {
+		case "paths":
+			d.comparePaths(asMap(src[key]), asMap(dst[key]))
+		case "components":
+			d.compareComponents(asMap(src[key]), asMap(dst[key]))
+		default:
+			d.compareField("/"+escape(key), src[key], dst[key])
+		}
+	}
+	return d, nil
+}
+
+func (d *Diff) comparePaths(src, dst map[string]any) {
+	for _, path := range sortedKeys(src) {
+		srcItem := asMap(src[path])
+		dstItem, ok := dst[path]
+		if !ok {
+			d.RemovedPaths = append(d.RemovedPaths, path)
+			continue
+		}
+		ptr := "/paths/" + escape(path)
+		for _, key := range unionKeys(srcItem, asMap(dstItem)) {
+			srcVal, dstVal := srcItem[key], asMap(dstItem)[key]
+			if slices.Contains(operationKeys, key) && dstVal == nil {
+				d.RemovedOperations = append(d.RemovedOperations, Operation{
+					Method: strings.ToUpper(key),
+					Path:   path,
+				})
+				continue
+			}
+			if slices.Contains(operationKeys, key) && srcVal != nil {
+				d.compareObject(ptr+"/"+key, asMap(srcVal), asMap(dstVal))
+				continue
+			}
+			d.compareField(ptr+"/"+escape(key), srcVal, dstVal)
+		}
+	}
+	for _, path := range sortedKeys(dst) {
+		if _, ok := src[path]; !ok {
+			d.addChange("/paths/"+escape(path), ChangeAdded)
+		}
+	}
+}
+
+func (d *Diff) compareComponents(src, dst map[string]any) {
+	for _, def := range unionKeys(src, dst) {
+		srcComps, dstComps := asMap(src[def]), asMap(dst[def])
+		for _, name := range unionKeys(srcComps, dstComps) {
+			srcComp, inSrc := srcComps[name]
+			dstComp, inDst := dstComps[name]
+			switch {
+			case !inDst:
+				d.RemovedComponents = append(d.RemovedComponents, Component{Def: def, Name: name})
+			case !inSrc:
+				d.addChange("/components/"+escape(def)+"/"+escape(name), ChangeAdded)
+			default:
+				d.compareField("/components/"+escape(def)+"/"+escape(name), srcComp, dstComp)
+			}
+		}
+	}
+}
+
+// compareObject reports changes of the direct fields of an object.
+func (d *Diff) compareObject(ptr string, src, dst map[string]any) {
+	for _, key := range unionKeys(src, dst) {
+		d.compareField(ptr+"/"+escape(key), src[key], dst[key])
+	}
+}
+
+func (d *Diff) compareField(ptr string, src, dst any) {
+	switch {
+	case src == nil && dst == nil:
+	case dst == nil:
+		d.addChange(ptr, ChangeRemoved)
+	case src == nil:
+		d.addChange(ptr, ChangeAdded)
+	case !reflect.DeepEqual(src, dst):
+		d.addChange(ptr, ChangeChanged)
+	}
+}
+
+func (d *Diff) addChange(ptr string, kind ChangeKind) {
+	d.Modified = append(d.Modified, Change{Pointer: ptr, Kind: kind})
+}
+
+// WriteText writes a human-readable summary of the diff.
+func (d *Diff) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Removed paths (%d):\n", len(d.RemovedPaths))
+	for _, path := range d.RemovedPaths {
+		fmt.Fprintf(&b, "  %s\n", path)
+	}
+	fmt.Fprintf(&b, "Removed operations (%d):\n", len(d.RemovedOperations))
+	for _, op := range d.RemovedOperations {
+		fmt.Fprintf(&b, "  %s %s\n", op.Method, op.Path)
+	}
+	fmt.Fprintf(&b, "Removed components (%d):\n", len(d.RemovedComponents))
+	for _, comp := range d.RemovedComponents {
+		fmt.Fprintf(&b, "  %s/%s\n", comp.Def, comp.Name)
+	}
+	fmt.Fprintf(&b, "Modified (%d):\n", len(d.Modified))
+	for _, change := range d.Modified {
+		fmt.Fprintf(&b, "  %s: %s\n", change.Pointer, change.Kind)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("io.WriteString: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes the diff as indented JSON.
+func (d *Diff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		return fmt.Errorf("enc.Encode: %w", err)
+	}
+	return nil
+}
+
+// toMap returns the generic JSON form of the spec and the length of its
+// JSON encoding.
+func toMap(doc *openapi3.T) (map[string]any, int, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, 0, fmt.Errorf("json.Marshal: %w", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, 0, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return m, len(data), nil
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func unionKeys(a, b map[string]any) []string {
+	keys := sortedKeys(a)
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// escape escapes a JSON Pointer reference token.
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
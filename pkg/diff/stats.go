@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Stats are the size metrics of a source spec and its filtered version.
+// Sizes are measured on the compact JSON encoding of the specs, so they do
+// not depend on the output format.
+type Stats struct {
+	PathsBefore      int `json:"pathsBefore"`
+	PathsAfter       int `json:"pathsAfter"`
+	ComponentsBefore int `json:"componentsBefore"`
+	ComponentsAfter  int `json:"componentsAfter"`
+	BytesBefore      int `json:"bytesBefore"`
+	BytesAfter       int `json:"bytesAfter"`
+}
+
+// ComputeStats computes the size metrics of the source spec snapshot and the
+// filtered spec.
+func ComputeStats(source *Snapshot, filtered *openapi3.T) (*Stats, error) {
+	dst, size, err := toMap(filtered)
+	if err != nil {
+		return nil, fmt.Errorf("toMap(filtered): %w", err)
+	}
+	return &Stats{
+		PathsBefore:      len(asMap(source.m["paths"])),
+		PathsAfter:       len(asMap(dst["paths"])),
+		ComponentsBefore: countComponents(asMap(source.m["components"])),
+		ComponentsAfter:  countComponents(asMap(dst["components"])),
+		BytesBefore:      source.size,
+		BytesAfter:       size,
+	}, nil
+}
+
+// countComponents returns the number of components of all types.
+func countComponents(components map[string]any) int {
+	n := 0
+	for _, comps := range components {
+		n += len(asMap(comps))
+	}
+	return n
+}
+
+// WriteText writes a human-readable summary of the stats.
+func (s *Stats) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Paths: %d -> %d (%s)\n", s.PathsBefore, s.PathsAfter, reduction(s.PathsBefore, s.PathsAfter))
+	fmt.Fprintf(&b, "Components: %d -> %d (%s)\n",
+		s.ComponentsBefore, s.ComponentsAfter, reduction(s.ComponentsBefore, s.ComponentsAfter))
+	fmt.Fprintf(&b, "Bytes: %d -> %d (%s)\n", s.BytesBefore, s.BytesAfter, reduction(s.BytesBefore, s.BytesAfter))
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("io.WriteString: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes the stats as indented JSON.
+func (s *Stats) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("enc.Encode: %w", err)
+	}
+	return nil
+}
+
+// reduction formats the relative change from before to after.
+func reduction(before, after int) string {
+	if before == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", float64(after-before)*100/float64(before))
+}
@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"log/slog"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// filterCallbacks returns a copy of the operation whose inline callbacks only
+// keep the operations passing the operation-level filters: excluded
+// operationIds and extensions, parameters, servers and media types, and the
+// methods kept by ReadOnly. Callback expressions and callbacks left without
+// operations are dropped. Callbacks referencing a component are kept as is.
+// The source operation is left untouched, and returned when nothing is
+// filtered out.
+//
+// The paths config and excludePaths do not apply: callback expressions are
+// runtime expressions, e.g. "{$request.body#/callbackUrl}", not paths of the
+// spec, and callbacks are selected along with their operation.
+func (oaf *OpenAPISpecFilter) filterCallbacks(
+	op *openapi3.Operation,
+	method, path string,
+) *openapi3.Operation {
+	if len(op.Callbacks) == 0 {
+		return op
+	}
+
+	changed := false
+	callbacks := make(openapi3.Callbacks, len(op.Callbacks))
+	for name, cbr := range op.Callbacks {
+		if cbr == nil || cbr.Ref != "" || cbr.Value == nil {
+			callbacks[name] = cbr
+			continue
+		}
+		callback, ok := oaf.filterCallback(cbr.Value)
+		if !ok {
+			callbacks[name] = cbr
+			continue
+		}
+		changed = true
+		if callback.Len() == 0 {
+			oaf.logger.Debug("callback dropped: no operation kept",
+				slog.String("callback", name),
+				slog.String("method", method),
+				slog.String("path", path))
+			continue
+		}
+		filteredRef := *cbr
+		filteredRef.Value = callback
+		callbacks[name] = &filteredRef
+	}
+	if !changed {
+		return op
+	}
+
+	filteredOp := *op
+	filteredOp.Callbacks = callbacks
+	if len(callbacks) == 0 {
+		filteredOp.Callbacks = nil
+	}
+	return &filteredOp
+}
+
+// filterCallback filters the operations of the callback with KeepsMethod and
+// filterOperation. It returns false if every operation is kept unchanged.
+func (oaf *OpenAPISpecFilter) filterCallback(callback *openapi3.Callback) (*openapi3.Callback, bool) {
+	changed := false
+	filtered := openapi3.NewCallbackWithCapacity(callback.Len())
+	filtered.Extensions = callback.Extensions
+	filtered.Origin = callback.Origin
+	for expression, pathItem := range callback.Map() {
+		if pathItem == nil || pathItem.Ref != "" || len(pathItem.Operations()) == 0 {
+			filtered.Set(expression, pathItem)
+			continue
+		}
+		newPathItem := *pathItem
+		kept := 0
+		for method, op := range pathItem.Operations() {
+			if !oaf.cfg.KeepsMethod(method) {
+				oaf.logger.Debug("callback operation dropped: not a safe method, read-only",
+					slog.String("method", method),
+					slog.String("expression", expression))
+				changed = true
+				newPathItem.SetOperation(method, nil)
+				continue
+			}
+			filteredOp, ok := oaf.filterOperation(op, method, expression)
+			if filteredOp != op {
+				changed = true
+			}
+			if !ok {
+				newPathItem.SetOperation(method, nil)
+				continue
+			}
+			newPathItem.SetOperation(method, filteredOp)
+			kept++
+		}
+		if kept != 0 {
+			filtered.Set(expression, &newPathItem)
+		}
+	}
+	return filtered, changed
+}
@@ -0,0 +1,140 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// callbacksSpec has a webhook-style subscription, whose callback posts
+// events to the URL given by the subscriber.
+const callbacksSpec = `
+openapi: 3.0.3
+info: {title: Webhooks, version: 1.0.0}
+paths:
+  /subscriptions:
+    post:
+      operationId: subscribe
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                callbackUrl: {type: string}
+      responses:
+        '201': {description: Subscribed}
+      callbacks:
+        onEvent:
+          '{$request.body#/callbackUrl}':
+            post:
+              operationId: postEvent
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      $ref: '#/components/schemas/Event'
+              responses:
+                '200': {description: Received}
+            get:
+              operationId: checkEvent
+              responses:
+                '200': {description: Alive}
+components:
+  schemas:
+    Event:
+      type: object
+      properties:
+        id: {type: string}
+`
+
+func callbackOperations(t *testing.T, spec *openapi3.T) []string {
+	t.Helper()
+	op := spec.Paths.Value("/subscriptions").Post
+	if op == nil {
+		t.Fatalf("operation POST /subscriptions not kept")
+	}
+	var ids []string
+	for _, cbr := range op.Callbacks {
+		for _, pathItem := range cbr.Value.Map() {
+			for _, cbOp := range pathItem.Operations() {
+				ids = append(ids, cbOp.OperationID)
+			}
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestFilterCallbacks(t *testing.T) {
+	filtered, _ := filterTestSpec(t, callbacksSpec, "paths: {/subscriptions: [post]}\ncomponents: {schemas: []}\n")
+	if got, want := callbackOperations(t, filtered), []string{"checkEvent", "postEvent"}; !slices.Equal(got, want) {
+		t.Errorf("got callback operations %v, want %v", got, want)
+	}
+	if filtered.Components == nil || filtered.Components.Schemas["Event"] == nil {
+		t.Errorf("schema referenced by a callback operation not kept")
+	}
+
+	filtered, _ = filterTestSpec(t, callbacksSpec, "paths: {/subscriptions: [post]}\ncomponents: {schemas: []}\nexcludeOperationIds: [postEvent]\n")
+	if got, want := callbackOperations(t, filtered), []string{"checkEvent"}; !slices.Equal(got, want) {
+		t.Errorf("excluded operationId: got callback operations %v, want %v", got, want)
+	}
+	if filtered.Components != nil && filtered.Components.Schemas["Event"] != nil {
+		t.Errorf("schema only referenced by a dropped callback operation kept")
+	}
+}
+
+func TestFilterCallbacksReadOnly(t *testing.T) {
+	spec := loadTestSpec(t, callbacksSpec)
+	oaf := newTestFilter(loadTestConfig(t, "paths: {/subscriptions: [post]}\n"))
+	filtered, err := oaf.Filter(spec)
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	if got := callbackOperations(t, filtered); len(got) != 2 {
+		t.Fatalf("got callback operations %v, want both", got)
+	}
+
+	// A safe operation with the same callback, as readOnly drops the POST
+	// subscription itself
+	cb := spec.Paths.Value("/subscriptions").Post.Callbacks
+	spec.Paths.Value("/subscriptions").Get = &openapi3.Operation{
+		OperationID: "listSubscriptions",
+		Responses:   spec.Paths.Value("/subscriptions").Post.Responses,
+		Callbacks:   cb,
+	}
+	filtered, err = newTestFilter(loadTestConfig(t, "paths: {/subscriptions: [get]}\nreadOnly: true\n")).Filter(spec)
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	op := filtered.Paths.Value("/subscriptions").Get
+	var ids []string
+	for _, cbr := range op.Callbacks {
+		for _, pathItem := range cbr.Value.Map() {
+			for method := range pathItem.Operations() {
+				ids = append(ids, method)
+			}
+		}
+	}
+	if !slices.Equal(ids, []string{"GET"}) {
+		t.Errorf("readOnly: got callback methods %v, want [GET]", ids)
+	}
+}
+
+func TestFilterCallbacksDanglingRefs(t *testing.T) {
+	cfg := loadTestConfig(t, `
+paths: {/subscriptions: [post]}
+removePointers: [/components/schemas/Event]
+failOnDanglingRefs: true
+`)
+	_, diagnostics, err := newTestFilter(cfg).FilterWithDiagnostics(context.Background(), loadTestSpec(t, callbacksSpec))
+	if !errors.Is(err, ErrDanglingRefs) {
+		t.Fatalf("got error %v, want %v", err, ErrDanglingRefs)
+	}
+	if !slices.ContainsFunc(diagnostics, func(diag Diagnostic) bool { return diag.Code == CodeDanglingRef }) {
+		t.Errorf("dangling ref of a callback operation not reported: %v", diagnostics)
+	}
+}
@@ -0,0 +1,188 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/components"
+	"github.com/zguydev/openapi-filter/internal/oas31"
+	"github.com/zguydev/openapi-filter/internal/refs"
+	"github.com/zguydev/openapi-filter/internal/walk"
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// ErrComponentRenameCollision is returned by Filter when ComponentRenames
+// gives a component the name of another component of the filtered spec.
+var ErrComponentRenameCollision = errors.New("component rename collision")
+
+// renameComponents renames the components of the filtered spec listed in
+// ComponentRenames and rewrites the refs to them, including discriminator
+// mappings, the security requirements naming renamed security schemes and
+// the refs of path items. Renamed components not kept are reported with a
+// warning.
+func (oaf *OpenAPISpecFilter) renameComponents() error {
+	comps := oaf.filtered.Components
+	if len(oaf.cfg.ComponentRenames) == 0 || comps == nil {
+		return nil
+	}
+
+	renames := make(map[string]map[string]string) // New names by family and name
+	for _, key := range slices.Sorted(maps.Keys(oaf.cfg.ComponentRenames)) {
+		def, name, err := config.ParseComponentRename(key)
+		if err != nil {
+			continue // Reported by Normalize
+		}
+		typ, _ := components.ComponentDefToType(def)
+		if !slices.Contains(components.ComponentNames(comps, typ), name) {
+			oaf.warn(CodeComponentNotFound, "", "renamed component not in filtered spec",
+				slog.String("def", def),
+				slog.String("name", name))
+			continue
+		}
+		if renames[def] == nil {
+			renames[def] = make(map[string]string)
+		}
+		renames[def][name] = oaf.cfg.ComponentRenames[key]
+	}
+
+	var errs []error
+	for _, def := range slices.Sorted(maps.Keys(renames)) {
+		typ, _ := components.ComponentDefToType(def)
+		named := make(map[string]string) // Source name by new name
+		for _, name := range components.ComponentNames(comps, typ) {
+			newName, ok := renames[def][name]
+			if !ok {
+				newName = name
+			}
+			if other, ok := named[newName]; ok {
+				errs = append(errs, fmt.Errorf("%w: %s/%s and %s/%s would both be named %s",
+					ErrComponentRenameCollision, def, other, def, name, newName))
+				continue
+			}
+			named[newName] = name
+		}
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, def := range slices.Sorted(maps.Keys(renames)) {
+		compMap, _ := jsonField(reflect.ValueOf(comps).Elem(), def)
+		renamed := reflect.MakeMapWithSize(compMap.Type(), compMap.Len())
+		iter := compMap.MapRange()
+		for iter.Next() {
+			name := iter.Key().String()
+			if newName, ok := renames[def][name]; ok {
+				oaf.logger.Debug("component renamed",
+					slog.String("def", def),
+					slog.String("name", name),
+					slog.String("to", newName))
+				name = newName
+			}
+			renamed.SetMapIndex(reflect.ValueOf(name), iter.Value())
+		}
+		compMap.Set(renamed)
+	}
+
+	rename := func(ref string) (string, bool) {
+		def, name, ok := refs.ParseRef(ref)
+		if !ok {
+			return "", false
+		}
+		newName, ok := renames[def][name]
+		return refs.FormatRef(def, newName), ok
+	}
+	walk.Walk(oaf.filtered, func(v reflect.Value) bool {
+		switch x := v.Interface().(type) {
+		case *openapi3.Discriminator:
+			x.Mapping = renameMapping(x.Mapping, renames["schemas"], rename)
+		case *openapi3.Operation:
+			if x.Security != nil {
+				security := renameSecurity(*x.Security, renames["securitySchemes"])
+				x.Security = &security
+			}
+		}
+		if v.Kind() == reflect.Struct {
+			if ref := v.FieldByName("Ref"); ref.IsValid() && ref.Kind() == reflect.String && ref.CanSet() {
+				if newRef, ok := rename(ref.String()); ok {
+					ref.SetString(newRef)
+				}
+			}
+		}
+		return true
+	})
+	oaf.filtered.Security = renameSecurity(oaf.filtered.Security, renames["securitySchemes"])
+	for _, item := range oas31.PathItems(comps) {
+		renameRawRefs(item, rename)
+	}
+	renameRawRefs(oas31.Webhooks(oaf.filtered), rename)
+	return nil
+}
+
+// renameMapping returns a copy of the discriminator mapping with the refs to
+// renamed schemas, and the names of renamed schemas, rewritten.
+func renameMapping(
+	mapping openapi3.StringMap,
+	schemas map[string]string,
+	rename func(string) (string, bool),
+) openapi3.StringMap {
+	if len(mapping) == 0 {
+		return mapping
+	}
+	renamed := make(openapi3.StringMap, len(mapping))
+	for key, target := range mapping {
+		if newRef, ok := rename(target); ok {
+			target = newRef
+		} else if newName, ok := schemas[target]; ok {
+			target = newName
+		}
+		renamed[key] = target
+	}
+	return renamed
+}
+
+// renameSecurity returns a copy of the security requirements with renamed
+// security schemes.
+func renameSecurity(security openapi3.SecurityRequirements, schemes map[string]string) openapi3.SecurityRequirements {
+	if len(security) == 0 || len(schemes) == 0 {
+		return security
+	}
+	renamed := make(openapi3.SecurityRequirements, len(security))
+	for i, requirement := range security {
+		renamed[i] = make(openapi3.SecurityRequirement, len(requirement))
+		for name, scopes := range requirement {
+			if newName, ok := schemes[name]; ok {
+				name = newName
+			}
+			renamed[i][name] = scopes
+		}
+	}
+	return renamed
+}
+
+// renameRawRefs rewrites the "$ref" values of a raw value, decoded from JSON
+// or YAML, in place.
+func renameRawRefs(v any, rename func(string) (string, bool)) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				if newRef, ok := rename(ref); ok {
+					v[key] = newRef
+				}
+				continue
+			}
+			renameRawRefs(value, rename)
+		}
+	case []any:
+		for _, value := range v {
+			renameRawRefs(value, rename)
+		}
+	}
+}
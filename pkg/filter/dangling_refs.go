@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/zguydev/openapi-filter/internal/components"
+	"github.com/zguydev/openapi-filter/internal/oas31"
+	"github.com/zguydev/openapi-filter/internal/refs"
+)
+
+// ErrDanglingRefs is returned by Filter when FailOnDanglingRefs is set and a
+// kept operation references a component missing from the filtered spec.
+var ErrDanglingRefs = errors.New("kept operations reference components missing from the filtered spec")
+
+// checkDanglingRefs warns about each kept operation or webhook referencing a
+// component missing from the filtered spec, e.g. a schema excluded by
+// extension or removed by removePointers, as the filtered spec is then
+// invalid. With FailOnDanglingRefs, it returns ErrDanglingRefs instead.
+// Operations are only walked if a collected ref is missing.
+func (oaf *OpenAPISpecFilter) checkDanglingRefs() error {
+	present := make(map[string]struct{})
+	for _, typ := range components.ComponentTypes() {
+		def := components.ComponentTypeToDef(typ)
+		for _, name := range components.ComponentNames(oaf.filtered.Components, typ) {
+			present[refs.FormatRef(def, name)] = struct{}{}
+		}
+	}
+	for name := range oas31.PathItems(oaf.filtered.Components) {
+		present[refs.FormatRef(oas31.PathItemsDef, name)] = struct{}{}
+	}
+	isDangling := func(ref string) bool {
+		if _, _, ok := refs.ParseRef(ref); !ok {
+			return false // Refs to paths are checked by checkPathRef
+		}
+		_, ok := present[ref]
+		return !ok
+	}
+	if !slices.ContainsFunc(slices.Collect(maps.Keys(oaf.collector.Refs())), isDangling) {
+		return nil
+	}
+
+	severity := SeverityWarning
+	if oaf.cfg.FailOnDanglingRefs {
+		severity = SeverityError
+	}
+	dangling := false
+	for _, path := range oaf.filtered.Paths.InMatchingOrder() {
+		ops := oaf.filtered.Paths.Value(path).Operations()
+		for _, method := range slices.Sorted(maps.Keys(ops)) {
+			collector := refs.NewRefsCollector()
+			collector.CollectOperation(ops[method])
+			for _, ref := range slices.Sorted(maps.Keys(collector.Refs())) {
+				if !isDangling(ref) {
+					continue
+				}
+				dangling = true
+				oaf.report(severity, CodeDanglingRef, "",
+					"kept operation references a component missing from the filtered spec",
+					slog.String("method", method),
+					slog.String("path", path),
+					slog.String("ref", ref))
+			}
+		}
+	}
+	webhooks := oas31.Webhooks(oaf.filtered)
+	for _, name := range slices.Sorted(maps.Keys(webhooks)) {
+		for _, ref := range rawRefs(webhooks[name]) {
+			if !isDangling(ref) {
+				continue
+			}
+			dangling = true
+			oaf.report(severity, CodeDanglingRef, "",
+				"kept webhook references a component missing from the filtered spec",
+				slog.String("webhook", name),
+				slog.String("ref", ref))
+		}
+	}
+	if dangling && oaf.cfg.FailOnDanglingRefs {
+		return ErrDanglingRefs
+	}
+	return nil
+}
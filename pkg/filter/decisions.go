@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/zguydev/openapi-filter/internal/components"
+)
+
+// debugEnabled reports whether filtering decisions are logged.
+func (oaf *OpenAPISpecFilter) debugEnabled() bool {
+	return oaf.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// logDroppedOperations logs the paths and operations of the source spec that
+// are neither listed in the paths configuration nor selected by a rule.
+func (oaf *OpenAPISpecFilter) logDroppedOperations() {
+	if !oaf.debugEnabled() {
+		return
+	}
+	for _, path := range slices.Sorted(maps.Keys(oaf.doc.Paths.Map())) {
+		newPathItem := oaf.filtered.Paths.Value(path)
+		if newPathItem == nil {
+			oaf.logger.Debug("dropping path: not listed and not selected by rules",
+				slog.String("path", path))
+			continue
+		}
+		for method := range oaf.doc.Paths.Value(path).Operations() {
+			if newPathItem.GetOperation(method) == nil {
+				oaf.logger.Debug("dropping operation: method not listed and not selected by rules",
+					slog.String("method", method),
+					slog.String("path", path))
+			}
+		}
+	}
+}
+
+// logDroppedComponents logs the components of the source spec that are not
+// copied to the filtered spec, as neither listed nor referenced.
+func (oaf *OpenAPISpecFilter) logDroppedComponents() {
+	if !oaf.debugEnabled() || oaf.doc.Components == nil {
+		return
+	}
+	for _, compTyp := range components.ComponentTypes() {
+		kept := components.ComponentNames(oaf.filtered.Components, compTyp)
+		for _, name := range components.ComponentNames(oaf.doc.Components, compTyp) {
+			if !slices.Contains(kept, name) {
+				oaf.logger.Debug("pruning component: unreferenced",
+					slog.String("def", components.ComponentTypeToDef(compTyp)),
+					slog.String("name", name))
+			}
+		}
+	}
+}
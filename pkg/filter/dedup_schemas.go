@@ -0,0 +1,162 @@
+package filter
+
+import (
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/refs"
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// ignoredSchemaKeys are not part of the structure of a schema.
+var ignoredSchemaKeys = []string{"title", "description"}
+
+// dedupSchemas merges the structurally equal schemas of the filtered
+// components, ignoring titles and descriptions at every level of the schemas.
+// The first name in sorted order is kept, and refs to the others are
+// rewritten to it. Schemas are compared with their refs rewritten, so schemas
+// only differing by refs to merged schemas are merged too. Schemas used by
+// discriminator mappings are never merged, as their names carry meaning.
+func (oaf *OpenAPISpecFilter) dedupSchemas() {
+	if !oaf.cfg.DedupSchemas || oaf.filtered.Components == nil {
+		return
+	}
+	schemas := oaf.filtered.Components.Schemas
+	pinned := discriminatorSchemas(oaf.filtered)
+
+	merged := make(map[string]string) // Merged name to kept name
+	for {
+		byShape := make(map[string]string)
+		found := false
+		for _, name := range slices.Sorted(maps.Keys(schemas)) {
+			schemaRef := schemas[name]
+			if _, ok := merged[name]; ok || schemaRef == nil || schemaRef.Ref != "" || schemaRef.Value == nil {
+				continue
+			}
+			if _, ok := pinned[name]; ok {
+				continue
+			}
+			shape, ok := schemaShape(schemaRef.Value, merged)
+			if !ok {
+				continue
+			}
+			kept, ok := byShape[shape]
+			if !ok {
+				byShape[shape] = name
+				continue
+			}
+			merged[name] = kept
+			found = true
+		}
+		if !found {
+			break
+		}
+	}
+	if len(merged) == 0 {
+		return
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(merged)) {
+		oaf.logger.Debug("schema merged into an equal schema",
+			slog.String("name", name),
+			slog.String("into", merged[name]))
+		delete(schemas, name)
+	}
+	walk.Walk(oaf.filtered, func(v reflect.Value) bool {
+		if schemaRef, ok := v.Interface().(*openapi3.SchemaRef); ok {
+			if kept, ok := mergedSchema(schemaRef.Ref, merged); ok {
+				schemaRef.Ref = refs.FormatRef("schemas", kept)
+				schemaRef.Value = schemas[kept].Value
+			}
+		}
+		return true
+	})
+}
+
+// mergedSchema returns the name of the schema kept in place of the schema
+// referenced by ref, if it was merged.
+func mergedSchema(ref string, merged map[string]string) (string, bool) {
+	def, name, ok := refs.ParseRef(ref)
+	if !ok || def != "schemas" {
+		return "", false
+	}
+	kept, ok := merged[name]
+	return kept, ok
+}
+
+// discriminatorSchemas returns the names of the schemas referenced by the
+// discriminator mappings of the spec.
+func discriminatorSchemas(doc *openapi3.T) map[string]struct{} {
+	pinned := make(map[string]struct{})
+	walk.Walk(doc, func(v reflect.Value) bool {
+		if d, ok := v.Interface().(*openapi3.Discriminator); ok {
+			for _, ref := range d.Mapping {
+				if def, name, ok := refs.ParseRef(ref); ok && def == "schemas" {
+					pinned[name] = struct{}{}
+				}
+			}
+		}
+		return true
+	})
+	return pinned
+}
+
+// schemaShape returns the structure of a schema as a comparable string: its
+// JSON encoding without titles and descriptions, and with refs to merged
+// schemas rewritten.
+func schemaShape(sc *openapi3.Schema, merged map[string]string) (string, bool) {
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return "", false
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return "", false
+	}
+	normalizeShape(m, merged)
+	// Map keys are sorted by json.Marshal
+	data, err = json.Marshal(m)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// normalizeShape normalizes a schema in its JSON form, and its subschemas.
+// Example and default values are left untouched.
+func normalizeShape(m map[string]any, merged map[string]string) {
+	for _, key := range ignoredSchemaKeys {
+		delete(m, key)
+	}
+	if ref, ok := m["$ref"].(string); ok {
+		if kept, ok := mergedSchema(ref, merged); ok {
+			m["$ref"] = refs.FormatRef("schemas", kept)
+		}
+	}
+	for _, key := range []string{"items", "not", "additionalProperties"} {
+		if sub, ok := m[key].(map[string]any); ok {
+			normalizeShape(sub, merged)
+		}
+	}
+	if props, ok := m["properties"].(map[string]any); ok {
+		for _, prop := range props {
+			if sub, ok := prop.(map[string]any); ok {
+				normalizeShape(sub, merged)
+			}
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if branches, ok := m[key].([]any); ok {
+			for _, branch := range branches {
+				if sub, ok := branch.(map[string]any); ok {
+					normalizeShape(sub, merged)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/components"
+	"github.com/zguydev/openapi-filter/internal/refs"
+)
+
+// ErrComponentNotFound is returned by Dependencies when the spec has no
+// component with the given name.
+var ErrComponentNotFound = errors.New("component not found")
+
+// Dependencies returns the components of spec reachable through refs from
+// the component with the qualified name component, e.g. "schemas/User",
+// transitively. They are returned sorted, as qualified names, e.g.
+// "schemas/Address" or "responses/Error". The component itself is only
+// returned if it is part of a cycle, e.g. a recursive schema. Refs to
+// components missing from the spec are returned but not followed, and refs
+// to other documents or to paths are ignored.
+func Dependencies(spec *openapi3.T, component string) ([]string, error) {
+	def, name, ok := strings.Cut(component, "/")
+	if _, known := components.ComponentDefToType(def); !ok || !known || name == "" {
+		return nil, fmt.Errorf("invalid component %q, expected family/name, e.g. schemas/User", component)
+	}
+
+	present := make(map[string]struct{})
+	if spec.Components != nil {
+		for _, typ := range components.ComponentTypes() {
+			for _, name := range components.ComponentNames(spec.Components, typ) {
+				present[refs.FormatRef(components.ComponentTypeToDef(typ), name)] = struct{}{}
+			}
+		}
+	}
+	root := refs.FormatRef(def, name)
+	if _, ok := present[root]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrComponentNotFound, component)
+	}
+
+	deps := make(map[string]struct{})
+	queue := []string{root}
+	for len(queue) != 0 {
+		ref := queue[0]
+		queue = queue[1:]
+		if _, ok := present[ref]; !ok {
+			continue // Missing, nothing to follow
+		}
+		def, name, _ := refs.ParseRef(ref)
+		typ, _ := components.ComponentDefToType(def)
+		collector := refs.NewRefsCollector()
+		collector.CollectComponent(spec.Components, typ, name)
+		for _, dep := range slices.Sorted(maps.Keys(collector.Refs())) {
+			if _, _, ok := refs.ParseRef(dep); !ok || !strings.HasPrefix(dep, "#") {
+				continue
+			}
+			if _, ok := deps[dep]; !ok {
+				deps[dep] = struct{}{}
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	qualified := make([]string, 0, len(deps))
+	for ref := range deps {
+		def, name, _ := refs.ParseRef(ref)
+		qualified = append(qualified, def+"/"+name)
+	}
+	slices.Sort(qualified)
+	return qualified, nil
+}
@@ -0,0 +1,106 @@
+package filter
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const dependenciesSpec = `
+openapi: 3.0.3
+info: {title: Deps, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        address: {$ref: '#/components/schemas/Address'}
+    Address:
+      type: object
+      properties:
+        city: {$ref: '#/components/schemas/City'}
+    City:
+      type: object
+      properties:
+        country: {$ref: '#/components/schemas/Country'}
+    Country:
+      type: object
+      properties:
+        region: {$ref: '#/components/schemas/Region'}
+    Region:
+      type: object
+      properties:
+        name: {type: string}
+    Node:
+      type: object
+      properties:
+        children:
+          type: array
+          items: {$ref: '#/components/schemas/Node'}
+    Parent:
+      type: object
+      properties:
+        child: {$ref: '#/components/schemas/Child'}
+    Child:
+      type: object
+      properties:
+        parent: {$ref: '#/components/schemas/Parent'}
+        user: {$ref: '#/components/schemas/User'}
+    ErrorBody:
+      type: object
+      properties:
+        message: {type: string}
+  responses:
+    Error:
+      description: Error
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/ErrorBody'}
+  parameters:
+    Page:
+      name: page
+      in: query
+      schema: {type: integer}
+`
+
+func TestDependencies(t *testing.T) {
+	spec := loadTestSpec(t, dependenciesSpec)
+	tests := []struct {
+		component string
+		want      []string
+	}{
+		{"schemas/User", []string{"schemas/Address", "schemas/City", "schemas/Country", "schemas/Region"}},
+		{"schemas/City", []string{"schemas/Country", "schemas/Region"}},
+		{"schemas/Region", []string{}},
+		{"schemas/Node", []string{"schemas/Node"}},
+		{"schemas/Parent", []string{
+			"schemas/Address", "schemas/Child", "schemas/City", "schemas/Country",
+			"schemas/Parent", "schemas/Region", "schemas/User",
+		}},
+		{"responses/Error", []string{"schemas/ErrorBody"}},
+		{"parameters/Page", []string{}},
+	}
+	for _, tt := range tests {
+		got, err := Dependencies(spec, tt.component)
+		if err != nil {
+			t.Errorf("Dependencies(%q): %v", tt.component, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Dependencies(%q) = %v, want %v", tt.component, got, tt.want)
+		}
+	}
+}
+
+func TestDependenciesErrors(t *testing.T) {
+	spec := loadTestSpec(t, dependenciesSpec)
+	if _, err := Dependencies(spec, "schemas/Missing"); !errors.Is(err, ErrComponentNotFound) {
+		t.Errorf("Dependencies(schemas/Missing) = %v, want ErrComponentNotFound", err)
+	}
+	for _, component := range []string{"User", "widgets/User", "schemas/"} {
+		if _, err := Dependencies(spec, component); err == nil || errors.Is(err, ErrComponentNotFound) {
+			t.Errorf("Dependencies(%q) = %v, want an invalid component error", component, err)
+		}
+	}
+}
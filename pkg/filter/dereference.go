@@ -0,0 +1,272 @@
+package filter
+
+import (
+	"cmp"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/components"
+	"github.com/zguydev/openapi-filter/internal/oas31"
+	"github.com/zguydev/openapi-filter/internal/refs"
+)
+
+// dereferenceOutput inlines the local component refs of the filtered spec
+// when DereferenceOutput is set, then drops the components no longer
+// referenced. A ref closing a cycle, e.g. in a recursive schema, cannot be
+// inlined: it is kept along with its component and reported with a warning.
+// Components targeted by discriminator mappings are kept too, and security
+// schemes, referenced by name, are always kept.
+func (oaf *OpenAPISpecFilter) dereferenceOutput() {
+	if !oaf.cfg.DereferenceOutput {
+		return
+	}
+
+	d := &dereferencer{
+		state:   make(map[pointerKey]visitState),
+		cleared: make(map[pointerKey]clearedRef),
+		kept:    make(map[string]struct{}),
+	}
+	comps := oaf.filtered.Components
+	oaf.filtered.Components = nil
+	d.visit(reflect.ValueOf(oaf.filtered))
+	oaf.filtered.Components = comps
+	if comps == nil {
+		return
+	}
+	// Raw webhooks cannot be dereferenced, their targets are kept
+	for _, target := range rawRefs(oas31.Webhooks(oaf.filtered)) {
+		d.keep(target)
+	}
+
+	// Kept components are dereferenced in turn, which can keep others
+	done := make(map[string]struct{})
+	for len(done) < len(d.kept) {
+		for _, ref := range slices.Sorted(maps.Keys(d.kept)) {
+			if _, ok := done[ref]; ok {
+				continue
+			}
+			done[ref] = struct{}{}
+			def, name, _ := refs.ParseRef(ref)
+			if def == oas31.PathItemsDef {
+				// Raw path items cannot be dereferenced, their targets are kept
+				for _, target := range rawRefs(oas31.PathItems(comps)[name]) {
+					d.keep(target)
+				}
+				continue
+			}
+			if m, ok := jsonField(reflect.ValueOf(comps).Elem(), def); ok && m.Kind() == reflect.Map {
+				if comp := m.MapIndex(reflect.ValueOf(name)); comp.IsValid() {
+					d.visit(comp)
+				}
+			}
+		}
+	}
+
+	for _, ref := range slices.Sorted(maps.Keys(d.cyclic)) {
+		oaf.warn(CodeCyclicRef, "", "ref closes a cycle, kept with its component",
+			slog.String("ref", ref))
+	}
+	oaf.pruneDereferencedComponents(d.kept)
+}
+
+// pruneDereferencedComponents drops the components of the filtered spec that
+// are not kept, except security schemes.
+func (oaf *OpenAPISpecFilter) pruneDereferencedComponents(kept map[string]struct{}) {
+	comps := oaf.filtered.Components
+	isKept := func(def, name string) bool {
+		_, ok := kept[refs.FormatRef(def, name)]
+		return ok
+	}
+	for _, typ := range components.ComponentTypes() {
+		def := components.ComponentTypeToDef(typ)
+		if typ == components.ContentTypeSecuritySchema {
+			continue
+		}
+		m, _ := jsonField(reflect.ValueOf(comps).Elem(), def)
+		for _, name := range components.ComponentNames(comps, typ) {
+			if !isKept(def, name) {
+				oaf.logger.Debug("component dereferenced", slog.String("def", def), slog.String("name", name))
+				m.SetMapIndex(reflect.ValueOf(name), reflect.Value{})
+			}
+		}
+	}
+	if items := oas31.PathItems(comps); items != nil {
+		for name := range items {
+			if !isKept(oas31.PathItemsDef, name) {
+				delete(items, name)
+			}
+		}
+		if len(items) == 0 {
+			delete(comps.Extensions, oas31.PathItemsDef)
+		}
+	}
+}
+
+type visitState int
+
+const (
+	visiting visitState = iota + 1
+	visited
+)
+
+// dereferencer clears the local component refs reachable from the visited
+// values, in a depth-first walk. A ref whose target is still being visited
+// closes a cycle and is kept, so the spec stays finite.
+type dereferencer struct {
+	state   map[pointerKey]visitState
+	cleared map[pointerKey]clearedRef // Refs cleared by the objects being visited
+	kept    map[string]struct{}       // Components still referenced
+	cyclic  map[string]struct{}       // Refs kept at a cycle boundary
+}
+
+// clearedRef is a cleared Ref field, restored if its object is found again
+// within its own value.
+type clearedRef struct {
+	field reflect.Value
+	ref   string
+}
+
+// pointerKey identifies a visited pointer. The type is part of the key, as a
+// struct and its first field share the same address.
+type pointerKey struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+func (d *dereferencer) visit(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		key := pointerKey{v.Type(), v.Pointer()}
+		switch d.state[key] {
+		case visiting:
+			if c, ok := d.cleared[key]; ok {
+				c.field.SetString(c.ref)
+				d.keepCyclic(c.ref)
+				delete(d.cleared, key)
+			}
+			return
+		case visited:
+			return
+		}
+		d.state[key] = visiting
+		defer func() {
+			d.state[key] = visited
+			delete(d.cleared, key)
+		}()
+
+		switch x := v.Interface().(type) {
+		case *openapi3.PathItem:
+			// Resolved by the loader, the path item holds its target
+			if def, _, ok := refs.ParseRef(x.Ref); ok && def == oas31.PathItemsDef && len(x.Operations()) != 0 {
+				d.cleared[key] = clearedRef{v.Elem().FieldByName("Ref"), x.Ref}
+				x.Ref = ""
+			}
+		case *openapi3.Discriminator:
+			for _, ref := range x.Mapping {
+				d.keep(ref)
+			}
+		}
+		if m := v.MethodByName("Map"); m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() == 1 {
+			d.visit(m.Call(nil)[0])
+		}
+		elem := v.Elem()
+		if ref, value, ok := refFields(elem); ok {
+			d.visitRef(key, ref, value)
+			return
+		}
+		d.visit(elem)
+
+	case reflect.Interface:
+		if !v.IsNil() {
+			d.visit(v.Elem())
+		}
+
+	case reflect.Struct:
+		if ref, value, ok := refFields(v); ok {
+			d.visitRef(pointerKey{}, ref, value)
+			return
+		}
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				d.visit(v.Field(i))
+			}
+		}
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(a.String(), b.String())
+		})
+		for _, key := range keys {
+			d.visit(v.MapIndex(key))
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			d.visit(v.Index(i))
+		}
+	}
+}
+
+// refFields returns the Ref and Value fields of a ref object, such as a
+// SchemaRef.
+func refFields(v reflect.Value) (ref, value reflect.Value, ok bool) {
+	if v.Kind() != reflect.Struct {
+		return ref, value, false
+	}
+	ref, value = v.FieldByName("Ref"), v.FieldByName("Value")
+	ok = ref.IsValid() && value.IsValid() && ref.Kind() == reflect.String && value.Kind() == reflect.Pointer
+	return ref, value, ok
+}
+
+// visitRef visits the value of the ref object identified by key, clearing the
+// ref if it is a local component ref that does not close a cycle.
+func (d *dereferencer) visitRef(key pointerKey, ref, value reflect.Value) {
+	target := ref.String()
+	if _, _, ok := refs.ParseRef(target); !ok || !strings.HasPrefix(target, "#") || value.IsNil() {
+		if target != "" {
+			d.keep(target) // Not inlined
+		}
+		d.visit(value)
+		return
+	}
+	if d.state[pointerKey{value.Type(), value.Pointer()}] == visiting {
+		d.keepCyclic(target)
+		return
+	}
+	if !ref.CanSet() {
+		d.keep(target)
+		d.visit(value)
+		return
+	}
+	ref.SetString("")
+	if key != (pointerKey{}) {
+		d.cleared[key] = clearedRef{ref, target}
+	}
+	d.visit(value)
+}
+
+// keep marks the component targeted by ref as still referenced. Refs to
+// other documents or to paths are ignored.
+func (d *dereferencer) keep(ref string) {
+	if _, _, ok := refs.ParseRef(ref); ok && strings.HasPrefix(ref, "#") {
+		d.kept[refs.NormalizeRef(ref)] = struct{}{}
+	}
+}
+
+// keepCyclic keeps the component targeted by a ref closing a cycle.
+func (d *dereferencer) keepCyclic(ref string) {
+	d.keep(ref)
+	if d.cyclic == nil {
+		d.cyclic = make(map[string]struct{})
+	}
+	d.cyclic[refs.NormalizeRef(ref)] = struct{}{}
+}
@@ -0,0 +1,159 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/zguydev/openapi-filter/internal/refs"
+)
+
+// ErrWarnings is returned by Filter when FailOnWarnings is set and warnings
+// were reported while filtering. The error lists them all.
+var ErrWarnings = errors.New("filtering reported warnings")
+
+// Severity is the severity of a diagnostic.
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error" // The problem fails the filtering
+)
+
+// Diagnostic codes, stable identifiers of the kinds of problems found while
+// filtering.
+const (
+	CodePathNotFound             = "path-not-found"
+	CodeMethodNotFound           = "method-not-found"
+	CodeResponseNotFound         = "response-not-found"
+	CodeTagNotFound              = "tag-not-found"
+	CodeUnknownMethod            = "unknown-method"
+	CodeInvalidVersionAnnotation = "invalid-version-annotation"
+	CodeAmbiguousOperationID     = "ambiguous-operation-id"
+	CodeInvalidRef               = "invalid-ref"
+	CodeUnknownComponentType     = "unknown-component-type"
+	CodeComponentNotFound        = "component-not-found"
+	CodeExcludedSchemaReferenced = "excluded-schema-referenced"
+	CodeRefTargetNotKept         = "ref-target-not-kept"
+	CodeLinkTargetNotKept        = "link-target-not-kept"
+	CodeDanglingRef              = "dangling-ref"
+	CodeNoSuccessResponse        = "no-success-response"
+	CodeNoEnum                   = "no-enum"
+	CodeLossyUnionCollapse       = "lossy-union-collapse"
+	CodePointerNotRemoved        = "pointer-not-removed"
+	CodeEmptyOutput              = "empty-output"
+	CodePathItemsUnsupported     = "path-items-unsupported"
+	CodeCyclicRef                = "cyclic-ref"
+	CodeTagOperationLimit        = "tag-operation-limit"
+)
+
+// Diagnostic is a problem found while filtering, in a machine-readable form.
+// Every diagnostic is also logged, with its message and details.
+type Diagnostic struct {
+	Severity Severity       `json:"severity"`
+	Code     string         `json:"code"`              // One of the Code* constants
+	Message  string         `json:"message"`           // Human-readable message, as logged
+	Pointer  string         `json:"pointer,omitempty"` // JSON Pointer to the location in the source spec, if any
+	Key      string         `json:"key,omitempty"`     // Key of the config entry, e.g. "paths./users", for LintConfig
+	Details  map[string]any `json:"details,omitempty"` // Attributes of the problem, as logged
+}
+
+// Diagnostics are the problems found while filtering a spec, in the order
+// they were found.
+type Diagnostics []Diagnostic
+
+// String formats the diagnostic on a single line, with its code and sorted
+// details, e.g. "path-not-found: path not found in spec (path=/users)". The
+// config key, if any, comes first.
+func (d Diagnostic) String() string {
+	s := d.Code + ": " + d.Message
+	if d.Key != "" {
+		s = d.Key + ": " + s
+	}
+	if len(d.Details) == 0 {
+		return s
+	}
+	details := make([]string, 0, len(d.Details))
+	for _, key := range slices.Sorted(maps.Keys(d.Details)) {
+		details = append(details, fmt.Sprintf("%s=%v", key, d.Details[key]))
+	}
+	return s + " (" + strings.Join(details, ", ") + ")"
+}
+
+// HasErrors reports whether any diagnostic has the error severity.
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// warningsError returns ErrWarnings listing the warnings of d, sorted, or nil
+// if there are none.
+func (d Diagnostics) warningsError() error {
+	var warnings []string
+	for _, diag := range d {
+		if diag.Severity == SeverityWarning {
+			warnings = append(warnings, diag.String())
+		}
+	}
+	if len(warnings) == 0 {
+		return nil
+	}
+	slices.Sort(warnings)
+	return fmt.Errorf("%w: %s", ErrWarnings, strings.Join(warnings, "; "))
+}
+
+// warn records a warning diagnostic and logs it, see report.
+func (oaf *OpenAPISpecFilter) warn(code, pointer, msg string, attrs ...slog.Attr) {
+	oaf.report(SeverityWarning, code, pointer, msg, attrs...)
+}
+
+// report records a diagnostic of the given severity and logs it, at the
+// error level for errors and the warning level otherwise.
+func (oaf *OpenAPISpecFilter) report(severity Severity, code, pointer, msg string, attrs ...slog.Attr) {
+	diag := Diagnostic{Severity: severity, Code: code, Message: msg, Pointer: pointer}
+	if len(attrs) != 0 {
+		diag.Details = make(map[string]any, len(attrs))
+		for _, attr := range attrs {
+			value := attr.Value.Resolve().Any()
+			if err, ok := value.(error); ok {
+				value = err.Error() // Errors do not marshal to JSON
+			}
+			diag.Details[attr.Key] = value
+		}
+	}
+	oaf.diagnostics = append(oaf.diagnostics, diag)
+
+	level := slog.LevelWarn
+	if severity == SeverityError {
+		level = slog.LevelError
+	}
+	oaf.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// operationPointer returns the JSON Pointer to the operation of the source
+// spec at path and method, or to the path item if method is empty.
+func operationPointer(path, method string) string {
+	if method == "" {
+		return refs.FormatPointer("paths", path)
+	}
+	return refs.FormatPointer("paths", path, strings.ToLower(method))
+}
+
+// refPointer returns the JSON Pointer of a local ref, e.g.
+// "/components/schemas/Pet" for "#/components/schemas/Pet", or an empty
+// pointer for a ref to another document.
+func refPointer(ref string) string {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return ""
+	}
+	return pointer
+}
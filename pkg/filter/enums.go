@@ -0,0 +1,44 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/zguydev/openapi-filter/internal/refs"
+)
+
+// excludeEnumValues removes the configured ExcludeEnumValues from the enums
+// of the kept component schemas. A schema left with an empty enum, which is
+// invalid, is an error.
+func (oaf *OpenAPISpecFilter) excludeEnumValues() error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(oaf.cfg.ExcludeEnumValues)) {
+		schemaRef := oaf.filtered.Components.Schemas[name]
+		if schemaRef == nil || schemaRef.Value == nil {
+			oaf.logger.Debug("enum values not excluded: schema not kept", slog.String("schema", name))
+			continue
+		}
+		sc := schemaRef.Value
+		if len(sc.Enum) == 0 {
+			oaf.warn(CodeNoEnum, refs.FormatPointer("components", "schemas", name),
+				"enum values not excluded: schema has no enum", slog.String("schema", name))
+			continue
+		}
+
+		kept := slices.DeleteFunc(slices.Clone(sc.Enum), func(value any) bool {
+			return oaf.cfg.IsEnumValueExcluded(name, value)
+		})
+		if len(kept) == 0 {
+			errs = append(errs, fmt.Errorf("schema %s: every enum value excluded", name))
+			continue
+		}
+		oaf.logger.Debug("enum values excluded",
+			slog.String("schema", name),
+			slog.Int("count", len(sc.Enum)-len(kept)))
+		sc.Enum = kept
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// trimsExamples reports whether examples are stripped or capped.
+func (oaf *OpenAPISpecFilter) trimsExamples() bool {
+	return oaf.cfg.StripExamples || oaf.cfg.MaxExamplesPerMediaType > 0
+}
+
+// trimExamples strips or caps the examples of every media type, parameter and
+// header reachable from root. Like collapseUnions, it must run before refs of
+// root are collected, so example components only used by dropped examples
+// are not copied into the filtered spec.
+func (oaf *OpenAPISpecFilter) trimExamples(root any) {
+	if !oaf.trimsExamples() {
+		return
+	}
+	walk.Walk(root, func(v reflect.Value) bool {
+		if v.Kind() != reflect.Struct || !v.CanAddr() {
+			return true
+		}
+		switch x := v.Addr().Interface().(type) {
+		case *openapi3.MediaType:
+			oaf.trimMediaTypeExamples(x)
+		case *openapi3.Parameter: // Also reached through the Header type
+			if oaf.cfg.StripExamples {
+				x.Example, x.Examples = nil, nil
+			}
+		}
+		return true
+	})
+}
+
+// trimMediaTypeExamples drops the examples of a media type, or keeps the
+// first MaxExamplesPerMediaType of them in name order. A single example
+// counts as one.
+func (oaf *OpenAPISpecFilter) trimMediaTypeExamples(mt *openapi3.MediaType) {
+	if oaf.cfg.StripExamples {
+		mt.Example, mt.Examples = nil, nil
+		return
+	}
+	limit := oaf.cfg.MaxExamplesPerMediaType
+	if len(mt.Examples) <= limit {
+		return
+	}
+	names := slices.Sorted(maps.Keys(mt.Examples))
+	kept := make(openapi3.Examples, limit)
+	for _, name := range names[:limit] {
+		kept[name] = mt.Examples[name]
+	}
+	oaf.logger.Debug("examples capped",
+		slog.Int("kept", limit),
+		slog.Int("dropped", len(names)-limit))
+	mt.Examples = kept
+}
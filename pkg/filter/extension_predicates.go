@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"log/slog"
+	"reflect"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// isSchemaExcluded reports whether a schema carries an extension value listed
+// in ExcludeByExtension, when schemas are excluded too.
+func (oaf *OpenAPISpecFilter) isSchemaExcluded(scr *openapi3.SchemaRef) bool {
+	if !oaf.cfg.ExcludeSchemasByExtension || scr == nil || scr.Value == nil {
+		return false
+	}
+	_, ok := oaf.cfg.MatchExcludedExtension(scr.Value.Extensions)
+	return ok
+}
+
+// excludeSchemas removes the properties and the allOf/anyOf/oneOf branches
+// that point to excluded schemas, in every schema reachable from root. Like
+// collapseUnions, it must run before refs of root are collected, so excluded
+// schemas are not copied into the filtered spec. Other uses of an excluded
+// schema, e.g. as a response schema, are reported when its ref is resolved.
+func (oaf *OpenAPISpecFilter) excludeSchemas(root any) {
+	if !oaf.cfg.ExcludeSchemasByExtension || len(oaf.cfg.ExcludeByExtension) == 0 {
+		return
+	}
+	walk.Walk(root, func(v reflect.Value) bool {
+		if sc, ok := v.Interface().(*openapi3.Schema); ok {
+			oaf.excludeSchemaMembers(sc)
+		}
+		return true
+	})
+}
+
+// excludeSchemaMembers removes the excluded members of a single schema in
+// place. Removed properties are also removed from the required list.
+func (oaf *OpenAPISpecFilter) excludeSchemaMembers(sc *openapi3.Schema) {
+	for name, prop := range sc.Properties {
+		if oaf.isSchemaExcluded(prop) {
+			oaf.logger.Debug("property dropped: schema excluded by extension",
+				slog.String("property", name),
+				slog.String("ref", prop.Ref))
+			delete(sc.Properties, name)
+			sc.Required = slices.DeleteFunc(sc.Required, func(required string) bool {
+				return required == name
+			})
+		}
+	}
+	for _, branches := range []*openapi3.SchemaRefs{&sc.AllOf, &sc.AnyOf, &sc.OneOf} {
+		*branches = slices.DeleteFunc(*branches, func(branch *openapi3.SchemaRef) bool {
+			if !oaf.isSchemaExcluded(branch) {
+				return false
+			}
+			oaf.logger.Debug("schema branch dropped: schema excluded by extension",
+				slog.String("ref", branch.Ref))
+			return true
+		})
+	}
+}
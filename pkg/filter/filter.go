@@ -4,22 +4,48 @@
 package filter
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
-	"go.uber.org/zap"
 
 	"github.com/zguydev/openapi-filter/internal/components"
+	"github.com/zguydev/openapi-filter/internal/extensions"
+	"github.com/zguydev/openapi-filter/internal/oas31"
 	"github.com/zguydev/openapi-filter/internal/refs"
+	"github.com/zguydev/openapi-filter/internal/walk"
 	"github.com/zguydev/openapi-filter/pkg/config"
 )
 
+// ErrInvalidOutput is returned by Filter when ValidateOutput is set and the
+// filtered spec does not pass validation.
+var ErrInvalidOutput = errors.New("filtered spec is invalid")
+
+// ErrEmptyOutput is returned by Filter when FailOnEmpty is set and the
+// filtered spec has neither operations nor components.
+var ErrEmptyOutput = errors.New("filtered spec has no paths and no components")
+
 // OpenAPISpecFilter is the main type that handles filtering of OpenAPI specs.
 type OpenAPISpecFilter struct {
-	cfg       *config.FilterConfig
-	logger    *zap.Logger
+	config    *config.Config       // Config as given, left unchanged
+	cfg       *config.FilterConfig // Normalized copy of the filter configuration, for the current filtering
+	logger    *slog.Logger
 	collector *refs.RefsCollector
 
+	opFilters  []OperationFilter   // Custom filters, see AddOperationFilters
+	superseded map[string]struct{} // Paths dropped by LatestVersionOnly
+	prepared   *PreparedSpec       // Index of the source spec, if filtered with FilterPrepared
+
+	composedRequired map[*openapi3.Schema]map[string]struct{} // Properties required by allOf compositions
+
+	diagnostics Diagnostics // Problems found by the current filtering
+
 	doc, filtered *openapi3.T
 }
 
@@ -27,10 +53,10 @@ type OpenAPISpecFilter struct {
 // provided configuration and logger.
 func NewOpenAPISpecFilter(
 	cfg *config.Config,
-	logger *zap.Logger,
+	logger *slog.Logger,
 ) *OpenAPISpecFilter {
 	return &OpenAPISpecFilter{
-		cfg:       &cfg.FilterConfig,
+		config:    cfg,
 		logger:    logger,
 		collector: refs.NewRefsCollector(),
 	}
@@ -38,9 +64,57 @@ func NewOpenAPISpecFilter(
 
 // Filter processes an OpenAPI spec according to the configured
 // filters and returns a filtered spec.
-// Returns an error if any step of the filtering process fails.
+// A copy of the configuration is normalized against the spec first, see
+// [config.Config.NormalizedFilterConfig]. Neither the spec nor the
+// configuration is modified, so both can be filtered again.
+// The tool's own x-openapi-filter extension is never kept in the filtered
+// spec, regardless of the configuration.
+// Returns an error if any step of the filtering process fails,
+// [ErrEmptyOutput] if nothing is kept and FailOnEmpty is set,
+// [ErrDanglingRefs] if a kept operation references a missing component and
+// FailOnDanglingRefs is set,
+// [ErrTagOperationLimit] if a tag has more operations than its
+// MaxOperationsPerTag limit,
+// [ErrInvalidOutput] if the filtered spec is validated and invalid, or
+// [ErrWarnings] if warnings were reported and FailOnWarnings is set.
 func (oaf *OpenAPISpecFilter) Filter(doc *openapi3.T) (filtered *openapi3.T, err error) {
-	oaf.doc = doc
+	return oaf.FilterContext(context.Background(), doc)
+}
+
+// FilterContext is Filter, returning the error of ctx once it is done. The
+// context is checked between paths and between referenced components.
+func (oaf *OpenAPISpecFilter) FilterContext(ctx context.Context, doc *openapi3.T) (*openapi3.T, error) {
+	filtered, _, err := oaf.FilterWithDiagnostics(ctx, doc)
+	return filtered, err
+}
+
+// FilterWithDiagnostics is FilterContext, also returning the problems found
+// while filtering, which are logged as well. Diagnostics are returned on
+// error too, e.g. with the dangling refs failing the filtering.
+func (oaf *OpenAPISpecFilter) FilterWithDiagnostics(
+	ctx context.Context,
+	doc *openapi3.T,
+) (*openapi3.T, Diagnostics, error) {
+	oaf.diagnostics = nil
+	filtered, err := oaf.filter(ctx, doc)
+	return filtered, oaf.diagnostics, err
+}
+
+// filter runs the filtering steps, see Filter.
+func (oaf *OpenAPISpecFilter) filter(ctx context.Context, doc *openapi3.T) (*openapi3.T, error) {
+	cfg, err := oaf.config.NormalizedFilterConfig(doc)
+	if err != nil {
+		return nil, fmt.Errorf("oaf.config.NormalizedFilterConfig: %w", err)
+	}
+	oaf.cfg = cfg
+
+	// The kept objects are transformed in place, so a copy of the spec is
+	// filtered.
+	oaf.doc = walk.Clone(doc)
+	oaf.prepared = oaf.prepared.indexCopy(oaf.doc)
+	if oaf.transformsOperations() {
+		oaf.prepared = nil // The transforms change the refs of kept operations
+	}
 
 	oaf.filtered = &openapi3.T{
 		OpenAPI:    oaf.doc.OpenAPI,
@@ -49,53 +123,303 @@ func (oaf *OpenAPISpecFilter) Filter(doc *openapi3.T) (filtered *openapi3.T, err
 		Paths:      &openapi3.Paths{},
 	}
 
-	oaf.filterPaths()
+	oaf.validateOperationIDs()
+	oaf.indexComposedRequired()
+	if err := oaf.findSupersededPaths(); err != nil {
+		return nil, fmt.Errorf("oaf.findSupersededPaths: %w", err)
+	}
+	if err := oaf.filterPaths(ctx); err != nil {
+		return nil, fmt.Errorf("oaf.filterPaths: %w", err)
+	}
+	if err := oaf.filterRules(ctx); err != nil {
+		return nil, fmt.Errorf("oaf.filterRules: %w", err)
+	}
+	if err := oaf.includeLinkTargets(ctx); err != nil {
+		return nil, fmt.Errorf("oaf.includeLinkTargets: %w", err)
+	}
+	oaf.filterPathParameters()
+	oaf.logDroppedOperations()
 	oaf.filterComponents()
-	oaf.filterOther()
-	oaf.filterRefs()
+	if err := oaf.filterOther(); err != nil {
+		return nil, fmt.Errorf("oaf.filterOther: %w", err)
+	}
+	if err := oaf.filterRefs(ctx); err != nil {
+		return nil, fmt.Errorf("oaf.filterRefs: %w", err)
+	}
+	oaf.pruneSecuritySchemes()
+	oaf.pruneDanglingLinks()
+	oaf.logDroppedComponents()
+	if err := oaf.excludeEnumValues(); err != nil {
+		return nil, fmt.Errorf("oaf.excludeEnumValues: %w", err)
+	}
+	oaf.dedupSchemas()
+	oaf.filterExtensions()
+	oaf.stripProse()
+	if err := oaf.rewriteMediaTypes(); err != nil {
+		return nil, fmt.Errorf("oaf.rewriteMediaTypes: %w", err)
+	}
+	oaf.stampProvenance()
+	oaf.prefixPaths()
+	if err := oaf.convertVersion(); err != nil {
+		return nil, fmt.Errorf("oaf.convertVersion: %w", err)
+	}
+	oaf.removePointers()
+	if err := oaf.checkDanglingRefs(); err != nil {
+		return nil, err
+	}
+	oaf.dereferenceOutput()
+	if err := oaf.renameComponents(); err != nil {
+		return nil, fmt.Errorf("oaf.renameComponents: %w", err)
+	}
 	if components.IsEmptyComponents(oaf.filtered.Components) {
 		oaf.filtered.Components = nil
 	}
+	if oaf.isEmpty() {
+		severity := SeverityWarning
+		if oaf.cfg.FailOnEmpty {
+			severity = SeverityError
+		}
+		oaf.report(severity, CodeEmptyOutput, "",
+			"filtered spec is empty: no paths and no components kept, check the filter config")
+		if oaf.cfg.FailOnEmpty {
+			return nil, ErrEmptyOutput
+		}
+	}
+	if err := oaf.checkTagOperationLimits(); err != nil {
+		return nil, err
+	}
+	if oaf.cfg.ValidateOutput {
+		if err := oaf.filtered.Validate(ctx); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidOutput, err)
+		}
+	}
+	if oaf.cfg.FailOnWarnings {
+		if err := oaf.diagnostics.warningsError(); err != nil {
+			return nil, err
+		}
+	}
 	return oaf.filtered, nil
 }
 
+// isEmpty reports whether the filtered spec has neither operations, webhooks
+// nor components, reusable path items included, which usually means the
+// config selects nothing by mistake.
+func (oaf *OpenAPISpecFilter) isEmpty() bool {
+	if len(oas31.Webhooks(oaf.filtered)) != 0 {
+		return false
+	}
+	if comps := oaf.filtered.Components; comps != nil && len(comps.Schemas)+len(comps.Parameters)+
+		len(comps.Headers)+len(comps.RequestBodies)+len(comps.Responses)+len(comps.SecuritySchemes)+
+		len(comps.Examples)+len(comps.Links)+len(comps.Callbacks)+len(oas31.PathItems(comps)) != 0 {
+		return false
+	}
+	for _, pathItem := range oaf.filtered.Paths.Map() {
+		if len(pathItem.Operations()) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // filterPaths processes the paths specified in the configuration and filters them
 // according to the allowed methods. It also collects all references used in the
 // filtered paths.
-func (oaf *OpenAPISpecFilter) filterPaths() {
+func (oaf *OpenAPISpecFilter) filterPaths(ctx context.Context) error {
 	for path, pathConfig := range oaf.cfg.Paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		pathItem := oaf.doc.Paths.Find(path)
 		if pathItem == nil {
-			oaf.logger.Warn("path not found in spec", zap.String("path", path))
+			oaf.warn(CodePathNotFound, "", "path not found in spec", slog.String("path", path))
+			continue
+		}
+		if oaf.isSuperseded(path) {
+			oaf.logger.Debug("listed path dropped, superseded by a newer version",
+				slog.String("path", path))
 			continue
 		}
 
+		oaf.logger.Debug("keeping path",
+			slog.String("path", path),
+			slog.Any("methods", pathConfig.Methods))
 		newPathItem := &openapi3.PathItem{}
 		for _, method := range pathConfig.Methods {
 			op := oaf.getOperation(pathItem, method, path)
 			if op == nil {
-				oaf.logger.Warn("method not exists for specified path",
-					zap.String("method", method),
-					zap.String("path", path))
+				oaf.warn(CodeMethodNotFound, operationPointer(path, ""), "method not exists for specified path",
+					slog.String("method", method),
+					slog.String("path", path))
+				continue
+			}
+			op, ok := oaf.prepareOperation(op, method, path, pathConfig)
+			if !ok {
 				continue
 			}
 			if !oaf.setOperation(newPathItem, method, path, op) {
 				continue
 			}
-			oaf.collector.CollectOperation(op)
+			oaf.collectOperation(op)
 		}
 
 		// Preserve path-level servers if configured
-		preserveServers := pathConfig.PreserveServers
-		if !preserveServers {
-			preserveServers = oaf.cfg.PreservePathServers
-		}
-		if preserveServers && pathItem.Servers != nil && len(pathItem.Servers) > 0 {
-			newPathItem.Servers = pathItem.Servers
-		}
+		oaf.preservePathServers(pathItem, newPathItem, pathConfig)
 
 		oaf.filtered.Paths.Set(path, newPathItem)
 	}
+	return nil
+}
+
+// prepareOperation applies the operation-level filters to a selected
+// operation. It returns false if the operation must be dropped.
+func (oaf *OpenAPISpecFilter) prepareOperation(
+	op *openapi3.Operation,
+	method, path string,
+	pathConfig config.PathConfig,
+) (*openapi3.Operation, bool) {
+	if !oaf.cfg.KeepsMethod(method) {
+		oaf.logger.Debug("operation dropped: not a safe method, read-only",
+			slog.String("method", method),
+			slog.String("path", path))
+		return nil, false
+	}
+	op, ok := oaf.filterOperation(op, method, path)
+	if !ok {
+		return nil, false
+	}
+	if oaf.cfg.ReadOnly && op.RequestBody != nil {
+		readOnlyOp := *op
+		readOnlyOp.RequestBody = nil
+		op = &readOnlyOp
+	}
+	if codes, ok := pathConfig.ResponseCodes(method); ok {
+		op = oaf.filterResponses(op, codes, method, path)
+	}
+	if oaf.cfg.KeepOnlyPrimaryResponse {
+		op = oaf.keepPrimaryResponse(op, method, path)
+	}
+	if !oaf.hasRequiredSecurityScheme(op) {
+		oaf.logger.Debug("operation dropped: not secured by a required security scheme",
+			slog.String("method", method),
+			slog.String("path", path))
+		return nil, false
+	}
+	if !oaf.hasRequiredResponseMediaType(op) {
+		oaf.logger.Debug("operation dropped: no success response with required media type",
+			slog.String("mediaType", oaf.cfg.RequireResponseMediaType),
+			slog.String("method", method),
+			slog.String("path", path))
+		return nil, false
+	}
+	if !oaf.keptByOperationFilters(path, method, op) {
+		oaf.logger.Debug("operation dropped: rejected by operation filter",
+			slog.String("method", method),
+			slog.String("path", path))
+		return nil, false
+	}
+	return op, true
+}
+
+// filterOperation applies the filters shared by selected operations and the
+// operations of their callbacks. It returns false if the operation must be
+// dropped.
+func (oaf *OpenAPISpecFilter) filterOperation(
+	op *openapi3.Operation,
+	method, path string,
+) (*openapi3.Operation, bool) {
+	if oaf.cfg.IsOperationIDExcluded(op.OperationID) {
+		oaf.logger.Debug("operation dropped: operationId excluded",
+			slog.String("operationId", op.OperationID),
+			slog.String("method", method),
+			slog.String("path", path))
+		return nil, false
+	}
+	if key, ok := oaf.cfg.MatchExcludedExtension(op.Extensions); ok {
+		oaf.logger.Debug("operation dropped: excluded by extension",
+			slog.String("extension", key),
+			slog.String("method", method),
+			slog.String("path", path))
+		return nil, false
+	}
+	if key, ok := oaf.cfg.MatchExcludedOverride(op.Extensions); ok {
+		oaf.logger.Debug("operation dropped: excluded by its own filter settings",
+			slog.String("setting", key),
+			slog.String("method", method),
+			slog.String("path", path))
+		return nil, false
+	}
+	if !oaf.keptByVersionGate(op, method, path) {
+		return nil, false
+	}
+	op = oaf.filterParameters(op, method, path)
+	op = oaf.filterOperationServers(op)
+	op = oaf.filterMediaTypes(op, method, path)
+	op = oaf.filterCallbacks(op, method, path)
+	return op, true
+}
+
+// keptByVersionGate reports whether the operation passes the configured
+// version gate. An invalid version annotation is reported with a warning, and
+// the operation is treated as unannotated.
+func (oaf *OpenAPISpecFilter) keptByVersionGate(op *openapi3.Operation, method, path string) bool {
+	vg := oaf.cfg.VersionGate
+	if vg == nil {
+		return true
+	}
+	keep, err := vg.Keeps(op.Extensions)
+	if err != nil {
+		oaf.warn(CodeInvalidVersionAnnotation, operationPointer(path, method),
+			"invalid version annotation, operation treated as unannotated",
+			slog.String("extension", vg.ExtensionOrDefault()),
+			slog.String("method", method),
+			slog.String("path", path),
+			slog.Any("error", err))
+		keep = vg.KeepsUnannotated()
+	}
+	if !keep {
+		oaf.logger.Debug("operation dropped: outside version gate",
+			slog.String("method", method),
+			slog.String("path", path))
+	}
+	return keep
+}
+
+// collectOperation collects the references of a kept operation, once its
+// schemas are transformed.
+func (oaf *OpenAPISpecFilter) collectOperation(op *openapi3.Operation) {
+	if refs, ok := oaf.prepared.operationRefs(op); ok {
+		oaf.collector.AddRefs(refs)
+		return
+	}
+	oaf.excludeSchemas(op)
+	oaf.trimExamples(op)
+	oaf.collapseUnions(op)
+	oaf.keepRequiredProperties(op)
+	oaf.collector.CollectOperation(op)
+}
+
+// transformsOperations reports whether the schemas or examples of kept
+// operations are transformed before their refs are collected.
+func (oaf *OpenAPISpecFilter) transformsOperations() bool {
+	return (oaf.cfg.ExcludeSchemasByExtension && len(oaf.cfg.ExcludeByExtension) != 0) ||
+		oaf.trimsExamples() ||
+		oaf.cfg.CollapseUnions != "" ||
+		oaf.cfg.OnlyRequiredProperties
+}
+
+// preservePathServers copies path-level servers to the filtered path item
+// according to the path's PreserveServers, defaulting to the global
+// PreservePathServers flag. It does not depend on the root Servers flag, but
+// only servers matching the configured server patterns and extension values
+// are copied.
+func (oaf *OpenAPISpecFilter) preservePathServers(
+	pathItem, newPathItem *openapi3.PathItem,
+	pathConfig config.PathConfig,
+) {
+	preserve := pathConfig.ShouldPreserveServers(oaf.cfg.PreservePathServers)
+	if preserve && len(pathItem.Servers) > 0 {
+		newPathItem.Servers = oaf.filterServers(pathItem.Servers)
+	}
 }
 
 // getOperation safely retrieves an operation from [openapi3.PathItem] for the specified
@@ -107,9 +431,9 @@ func (oaf *OpenAPISpecFilter) getOperation(
 ) (op *openapi3.Operation) {
 	defer func() {
 		if r := recover(); r != nil {
-			oaf.logger.Warn("unknown HTTP method in filter config",
-				zap.String("method", method),
-				zap.String("path", path))
+			oaf.warn(CodeUnknownMethod, "", "unknown HTTP method in filter config",
+				slog.String("method", method),
+				slog.String("path", path))
 			op = nil
 		}
 	}()
@@ -126,9 +450,9 @@ func (oaf *OpenAPISpecFilter) setOperation(
 ) (ok bool) {
 	defer func() {
 		if r := recover(); r != nil {
-			oaf.logger.Warn("unknown HTTP method in spec",
-				zap.String("method", method),
-				zap.String("path", path))
+			oaf.warn(CodeUnknownMethod, operationPointer(path, ""), "unknown HTTP method in spec",
+				slog.String("method", method),
+				slog.String("path", path))
 			ok = false
 		}
 	}()
@@ -136,45 +460,118 @@ func (oaf *OpenAPISpecFilter) setOperation(
 	return true
 }
 
+// filterResponses returns a copy of the operation that only contains responses
+// with the specified status codes. The source operation is left untouched.
+func (oaf *OpenAPISpecFilter) filterResponses(
+	op *openapi3.Operation,
+	codes []string,
+	method, path string,
+) *openapi3.Operation {
+	filteredOp := *op
+	filteredOp.Responses = openapi3.NewResponsesWithCapacity(len(codes))
+	if op.Responses == nil {
+		return &filteredOp
+	}
+	filteredOp.Responses.Extensions = op.Responses.Extensions
+
+	for _, code := range codes {
+		resp := op.Responses.Value(code)
+		if resp == nil {
+			oaf.warn(CodeResponseNotFound, operationPointer(path, method), "response not exists for specified operation",
+				slog.String("code", code),
+				slog.String("method", method),
+				slog.String("path", path))
+			continue
+		}
+		filteredOp.Responses.Set(code, resp)
+	}
+	return &filteredOp
+}
+
 // filterRefs processes all collected references and ensures they are properly
 // included in the filtered spec.
-func (oaf *OpenAPISpecFilter) filterRefs() {
+func (oaf *OpenAPISpecFilter) filterRefs(ctx context.Context) error {
 	for ref := range oaf.collector.Refs() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		oaf.filterRef(ref)
 	}
+	return nil
 }
 
 // filterRef processes a single reference and copies the referenced component
-// to the filtered spec.
+// to the filtered spec. Refs to paths are only checked to resolve in the
+// filtered spec.
 func (oaf *OpenAPISpecFilter) filterRef(ref string) {
+	if path, method, ok := refs.ParsePathRef(ref); ok {
+		oaf.checkPathRef(ref, path, method)
+		return
+	}
 	if oaf.doc.Components == nil {
 		return
 	}
 
 	def, name, ok := refs.ParseRef(ref)
 	if !ok {
-		oaf.logger.Warn("incorrect ref", zap.String("ref", ref))
+		oaf.warn(CodeInvalidRef, "", "incorrect ref", slog.String("ref", ref))
 		return
 	}
 
+	if def == oas31.PathItemsDef {
+		return // Copied by filterPathItems
+	}
 	compType, ok := components.ComponentDefToType(def)
 	if !ok {
-		oaf.logger.Warn("unknown component definition",
-			zap.String("def", def),
-			zap.String("name", name),
-			zap.String("ref", ref))
+		oaf.warn(CodeUnknownComponentType, "", "unknown component definition",
+			slog.String("def", def),
+			slog.String("name", name),
+			slog.String("ref", ref))
 		return
 	}
+	if compType == components.ComponentTypeSchema && oaf.isSchemaExcluded(oaf.doc.Components.Schemas[name]) {
+		oaf.warn(CodeExcludedSchemaReferenced, refPointer(ref), "schema excluded by extension is still referenced",
+			slog.String("name", name),
+			slog.String("ref", ref))
+		return
+	}
+	isNew := oaf.debugEnabled() &&
+		!slices.Contains(components.ComponentNames(oaf.filtered.Components, compType), name)
 	if !components.ProcessCopyComponent(
 		oaf.doc.Components,
 		oaf.filtered.Components,
 		compType,
 		name,
 	) {
-		oaf.logger.Warn("component not found",
-			zap.String("def", def),
-			zap.String("name", name),
-			zap.String("ref", ref))
+		oaf.warn(CodeComponentNotFound, "", "component not found",
+			slog.String("def", def),
+			slog.String("name", name),
+			slog.String("ref", ref))
+		return
+	}
+	if isNew {
+		oaf.logger.Debug("auto-including component via ref resolution",
+			slog.String("def", def),
+			slog.String("name", name),
+			slog.String("ref", ref))
+	}
+}
+
+// checkPathRef warns if a ref to a path item or operation does not resolve in
+// the filtered spec, as paths are only kept by the configuration.
+func (oaf *OpenAPISpecFilter) checkPathRef(ref, path, method string) {
+	pathItem := oaf.filtered.Paths.Value(path)
+	if pathItem == nil {
+		oaf.warn(CodeRefTargetNotKept, operationPointer(path, ""), "ref to path not kept in filtered spec",
+			slog.String("path", path),
+			slog.String("ref", ref))
+		return
+	}
+	if method != "" && oaf.getOperation(pathItem, method, path) == nil {
+		oaf.warn(CodeRefTargetNotKept, operationPointer(path, method), "ref to operation not kept in filtered spec",
+			slog.String("method", method),
+			slog.String("path", path),
+			slog.String("ref", ref))
 	}
 }
 
@@ -185,37 +582,192 @@ func (oaf *OpenAPISpecFilter) filterComponents() {
 		return
 	}
 
+	var prunedRefs map[string]struct{}
+	if oaf.cfg.Components.PruneExplicitlyListedIfUnreferenced {
+		prunedRefs = oaf.excludedOnlyRefs()
+	}
+
+	type listedComponent struct {
+		typ  components.ComponentType
+		name string
+	}
+	listed := oaf.listedComponents()
+	var copied []listedComponent
 	for _, compTyp := range components.ComponentTypes() {
-		for _, name := range components.ComponentTypeToCfgNames(oaf.cfg.Components, compTyp) {
+		def := components.ComponentTypeToDef(compTyp)
+		for _, name := range components.ComponentTypeToCfgNames(listed, compTyp) {
+			if _, ok := prunedRefs[refs.FormatRef(def, name)]; ok {
+				oaf.logger.Debug("listed component referenced only by excluded operations, pruned",
+					slog.String("def", def),
+					slog.String("name", name))
+				continue
+			}
+			if compTyp == components.ComponentTypeSchema && oaf.isSchemaExcluded(oaf.doc.Components.Schemas[name]) {
+				oaf.logger.Debug("listed schema dropped: excluded by extension",
+					slog.String("name", name))
+				continue
+			}
+			if compTyp == components.ComponentTypeParameter && oaf.isParameterLocationExcluded(oaf.doc.Components.Parameters[name]) {
+				oaf.logger.Debug("listed parameter dropped: location excluded",
+					slog.String("name", name))
+				continue
+			}
 			if !components.ProcessCopyComponent(
 				oaf.doc.Components,
 				oaf.filtered.Components,
 				compTyp,
 				name,
 			) {
-				oaf.logger.Warn("component not found",
-					zap.String("def", components.ComponentTypeToDef(compTyp)),
-					zap.String("name", name))
+				oaf.warn(CodeComponentNotFound, "", "component not found",
+					slog.String("def", def),
+					slog.String("name", name))
 				continue
 			}
-			oaf.collector.CollectComponent(oaf.doc.Components, compTyp, name)
+			oaf.logger.Debug("keeping listed component",
+				slog.String("def", def),
+				slog.String("name", name))
+			copied = append(copied, listedComponent{compTyp, name})
+		}
+	}
+
+	// Only listed components are copied at this point, refs are added later.
+	oaf.filterComponentMediaTypes(oaf.filtered.Components)
+	oaf.excludeSchemas(oaf.filtered.Components)
+	oaf.trimExamples(oaf.filtered.Components)
+	oaf.collapseUnions(oaf.filtered.Components)
+	oaf.keepRequiredProperties(oaf.filtered.Components)
+	for _, comp := range copied {
+		oaf.collector.CollectComponent(oaf.doc.Components, comp.typ, comp.name)
+	}
+	oaf.filterPathItems(listed.PathItems)
+}
+
+// listedComponents returns the components listed by the config, with those
+// listed by the path configs of paths with kept operations added.
+func (oaf *OpenAPISpecFilter) listedComponents() *config.FilterComponentsConfig {
+	listed := *oaf.cfg.Components
+	for _, path := range slices.Sorted(maps.Keys(oaf.cfg.Paths)) {
+		cc := oaf.cfg.Paths[path].Components
+		pathItem := oaf.filtered.Paths.Value(path)
+		if cc == nil || pathItem == nil || len(pathItem.Operations()) == 0 {
+			continue
+		}
+		for _, list := range []struct {
+			names *[]string
+			add   []string
+		}{
+			{&listed.Schemas, cc.Schemas},
+			{&listed.Parameters, cc.Parameters},
+			{&listed.SecuritySchemes, cc.SecuritySchemes},
+			{&listed.RequestBodies, cc.RequestBodies},
+			{&listed.Responses, cc.Responses},
+			{&listed.Headers, cc.Headers},
+			{&listed.Examples, cc.Examples},
+			{&listed.Links, cc.Links},
+			{&listed.Callbacks, cc.Callbacks},
+			{&listed.PathItems, cc.PathItems},
+		} {
+			for _, name := range list.add {
+				if !slices.Contains(*list.names, name) {
+					// Appended to a copy, as the config lists are shared
+					*list.names = append(slices.Clip(*list.names), name)
+				}
+			}
 		}
+		oaf.logger.Debug("keeping components listed by path", slog.String("path", path))
 	}
+	return &listed
+}
+
+// excludedOnlyRefs returns the refs used by operations of the source spec that
+// are not used by any operation kept in the filtered spec.
+func (oaf *OpenAPISpecFilter) excludedOnlyRefs() map[string]struct{} {
+	all, ok := oaf.prepared.allOperationRefs()
+	if !ok {
+		collector := refs.NewRefsCollector()
+		for _, pathItem := range oaf.doc.Paths.Map() {
+			for _, op := range pathItem.Operations() {
+				collector.CollectOperation(op)
+			}
+		}
+		all = collector.Refs()
+	}
+
+	kept := oaf.collector.Refs()
+	excluded := make(map[string]struct{})
+	for ref := range all {
+		if _, ok := kept[ref]; !ok {
+			excluded[ref] = struct{}{}
+		}
+	}
+	return excluded
 }
 
 // filterOther processes additional OpenAPI elements specified in the configuration,
 // including servers, security requirements, tags, and external documentation.
-func (oaf *OpenAPISpecFilter) filterOther() {
-	if oaf.cfg.Servers {
-		oaf.filtered.Servers = oaf.doc.Servers
+// An explicitly configured externalDocs object overrides the one from the source
+// spec, regardless of the ExternalDocs flag. The info object is always kept,
+// stripped and overridden as configured.
+func (oaf *OpenAPISpecFilter) filterOther() error {
+	if oaf.cfg.Servers.KeepsRoot() {
+		oaf.filtered.Servers = oaf.filterServers(oaf.doc.Servers)
 	}
 	if oaf.cfg.Security {
 		oaf.filtered.Security = oaf.doc.Security
 	}
-	if oaf.cfg.Tags {
-		oaf.filtered.Tags = oaf.doc.Tags
+	if oaf.cfg.Tags.Enabled {
+		oaf.filtered.Tags = oaf.filterTags(oaf.doc.Tags)
 	}
 	if oaf.cfg.ExternalDocs {
 		oaf.filtered.ExternalDocs = oaf.doc.ExternalDocs
 	}
+	if ed := oaf.cfg.SetExternalDocs; ed != nil {
+		oaf.filtered.ExternalDocs = &openapi3.ExternalDocs{
+			URL:         ed.URL,
+			Description: ed.Description,
+		}
+	}
+	if err := oaf.filterInfo(); err != nil {
+		return fmt.Errorf("oaf.filterInfo: %w", err)
+	}
+	return nil
+}
+
+// filterExtensions removes vendor extensions from the whole filtered spec
+// according to the configured strip and keep lists. The tool's own
+// x-openapi-filter extension is always removed. The OpenAPI 3.1 objects the
+// loader keeps as extensions, e.g. webhooks, are not vendor extensions.
+func (oaf *OpenAPISpecFilter) filterExtensions() {
+	extensions.Strip(oaf.filtered, func(key string) bool {
+		return strings.HasPrefix(key, "x-") && oaf.cfg.IsExtensionStripped(key)
+	})
+}
+
+// stampProvenance adds the configured provenance extension to every operation
+// of the filtered spec. It runs after extension filtering, so the stamp is
+// never stripped. Operations are copied, as they are shared with the source spec.
+func (oaf *OpenAPISpecFilter) stampProvenance() {
+	pc := oaf.cfg.StampProvenance
+	if pc == nil {
+		return
+	}
+
+	var timestamp string
+	if pc.TimestampKey != "" {
+		timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	for _, pathItem := range oaf.filtered.Paths.Map() {
+		for method, op := range pathItem.Operations() {
+			stamped := *op
+			stamped.Extensions = maps.Clone(op.Extensions)
+			if stamped.Extensions == nil {
+				stamped.Extensions = make(map[string]any)
+			}
+			stamped.Extensions[pc.KeyOrDefault()] = pc.ValueOrDefault()
+			if timestamp != "" {
+				stamped.Extensions[pc.TimestampKey] = timestamp
+			}
+			pathItem.SetOperation(method, &stamped)
+		}
+	}
 }
@@ -0,0 +1,484 @@
+package filter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// testSpec is a small spec exercising most of the filters: descriptions,
+// extensions, unions, enums, optional properties, several media types,
+// request body components and links.
+const testSpec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  description: Pet store
+  version: 1.0.0
+  x-team: pets
+paths:
+  /pet:
+    put:
+      operationId: updatePet
+      description: Update an existing pet
+      x-internal: false
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+      responses:
+        '200':
+          description: Updated pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+            application/xml:
+              schema:
+                $ref: '#/components/schemas/Pet'
+          links:
+            GetPet:
+              operationId: getPet
+    post:
+      operationId: addPet
+      description: Add a new pet
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+      responses:
+        '200':
+          description: Added pet
+  /pet/{petId}:
+    get:
+      operationId: getPet
+      summary: Find a pet
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: A pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  requestBodies:
+    Pet:
+      description: A pet
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+        application/xml:
+          schema:
+            $ref: '#/components/schemas/Pet'
+  schemas:
+    Pet:
+      type: object
+      description: A pet
+      x-entity: pet
+      required: [name]
+      properties:
+        name:
+          type: string
+          description: Name of the pet
+        status:
+          type: string
+          nullable: true
+          enum: [available, pending, sold]
+        tag:
+          $ref: '#/components/schemas/Tag'
+        owner:
+          oneOf:
+            - $ref: '#/components/schemas/Person'
+            - $ref: '#/components/schemas/Company'
+    Tag:
+      type: object
+      properties:
+        name:
+          type: string
+    Label:
+      type: object
+      properties:
+        name:
+          type: string
+    Person:
+      type: object
+      properties:
+        name:
+          type: string
+    Company:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+// loadTestSpec loads a spec from its YAML source.
+func loadTestSpec(t *testing.T, data string) *openapi3.T {
+	t.Helper()
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(data))
+	if err != nil {
+		t.Fatalf("LoadFromData: %v", err)
+	}
+	return spec
+}
+
+// loadTestConfig loads a config from its YAML source.
+func loadTestConfig(t *testing.T, data string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".openapi-filter.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("config.LoadConfig: %v", err)
+	}
+	return cfg
+}
+
+// newTestFilter returns a filter discarding its logs.
+func newTestFilter(cfg *config.Config) *OpenAPISpecFilter {
+	return NewOpenAPISpecFilter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// filterTestSpec filters the spec with the config, both given as YAML.
+func filterTestSpec(t *testing.T, spec, cfg string) (*openapi3.T, Diagnostics) {
+	t.Helper()
+	filtered, diagnostics, err := newTestFilter(loadTestConfig(t, cfg)).
+		FilterWithDiagnostics(context.Background(), loadTestSpec(t, spec))
+	if err != nil {
+		t.Fatalf("FilterWithDiagnostics: %v", err)
+	}
+	return filtered, diagnostics
+}
+
+func marshalSpec(t *testing.T, spec *openapi3.T) []byte {
+	t.Helper()
+	data, err := spec.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	return data
+}
+
+func TestFilterLeavesInputUnchanged(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   string
+		check func(t *testing.T, filtered *openapi3.T) // Checks the transform ran, if set
+	}{
+		{"stripDescriptions", "paths: {\"/pet*\": [\"*\"]}\nstripDescriptions: true\nstripSummaries: true\n", nil},
+		{"stripExtensions", "paths: {\"/pet*\": [\"*\"]}\nstripExtensions: [\"x-*\"]\n", nil},
+		{"collapseUnions", "paths: {/pet: [put]}\ncollapseUnions: first\n", nil},
+		{"excludeSchemasByExtension", "paths: {/pet: [put]}\nexcludeByExtension: {x-entity: pet}\nexcludeSchemasByExtension: true\n", nil},
+		{"includeMediaTypes", "paths: {/pet: [put]}\nincludeMediaTypes: [application/xml]\n", nil},
+		{"excludeMediaTypes", "paths: {/pet: [put]}\nexcludeMediaTypes: [\"application/*\"]\ncomponents: {requestBodies: [Pet]}\n", nil},
+		{"mediaTypeRewrites", "paths: {/pet: [put]}\nmediaTypeRewrites: {application/json: application/vnd.pet+json}\n", nil},
+		{"excludeEnumValues", "paths: {/pet: [put]}\nexcludeEnumValues: {Pet: [sold]}\n", nil},
+		{"onlyRequiredProperties", "paths: {/pet: [put]}\nonlyRequiredProperties: true\n", nil},
+		{"removePointers", "paths: {/pet: [put]}\nremovePointers: [/components/schemas/Pet/properties/tag]\n", nil},
+		{"outputVersion", "paths: {/pet: [put]}\noutputVersion: \"3.1\"\n", nil},
+		{"dedupSchemas", "paths: {}\ncomponents: {schemas: [Tag, Label]}\ndedupSchemas: true\n", nil},
+		{"dereferenceOutput", "paths: {/pet: [put]}\ndereferenceOutput: true\n", nil},
+		{"componentRenames", "paths: {/pet: [put]}\ncomponentRenames: {schemas/Pet: Animal}\n", nil},
+		{"pruneDanglingLinks", "paths: {/pet: [put]}\n", nil},
+		{"stampProvenance", "paths: {/pet: [put]}\nstampProvenance: {}\n", nil},
+		{"info", "paths: {/pet: [put]}\ninfo: {exclude: [description], title: Animals}\n", func(t *testing.T, filtered *openapi3.T) {
+			if filtered.Info.Description != "" || filtered.Info.Title != "Animals" {
+				t.Errorf("got info %+v, want the description dropped and the title overridden", filtered.Info)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := loadTestSpec(t, testSpec)
+			cfg := loadTestConfig(t, tt.cfg)
+			wantSpec := marshalSpec(t, spec)
+			wantCfg := walk.Clone(*cfg)
+
+			filtered, err := newTestFilter(cfg).Filter(spec)
+			if err != nil {
+				t.Fatalf("Filter: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, filtered)
+			}
+			if got := marshalSpec(t, spec); !bytes.Equal(got, wantSpec) {
+				t.Errorf("source spec modified:\ngot  %s\nwant %s", got, wantSpec)
+			}
+			if !reflect.DeepEqual(*cfg, wantCfg) {
+				t.Errorf("config modified:\ngot  %+v\nwant %+v", *cfg, wantCfg)
+			}
+		})
+	}
+}
+
+func TestFilterReusesConfigAcrossSpecs(t *testing.T) {
+	cfg := loadTestConfig(t, "paths: {\"/pet*\": [\"*\"]}\n")
+	oaf := newTestFilter(cfg)
+	if _, err := oaf.Filter(loadTestSpec(t, testSpec)); err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+
+	// The glob is expanded against each spec, not bound to the first one
+	other := loadTestSpec(t, `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      responses:
+        '200': {description: Pets}
+`)
+	filtered, err := oaf.Filter(other)
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	if filtered.Paths.Value("/pets") == nil {
+		t.Errorf("path /pets not kept, got paths %v", filtered.Paths.InMatchingOrder())
+	}
+}
+
+func TestFilterPreparedMatchesFilter(t *testing.T) {
+	for _, cfg := range []string{
+		"paths: {/pet: [put]}\n",
+		"paths: {/pet: [put]}\ncollapseUnions: first\n",
+		"paths: {/pet: [put]}\nstripDescriptions: true\n",
+	} {
+		spec := loadTestSpec(t, testSpec)
+		ps, err := Prepare(spec)
+		if err != nil {
+			t.Fatalf("Prepare: %v", err)
+		}
+		wantSpec := marshalSpec(t, spec)
+		prepared, err := newTestFilter(loadTestConfig(t, cfg)).FilterPrepared(ps)
+		if err != nil {
+			t.Fatalf("FilterPrepared: %v", err)
+		}
+		filtered, err := newTestFilter(loadTestConfig(t, cfg)).Filter(loadTestSpec(t, testSpec))
+		if err != nil {
+			t.Fatalf("Filter: %v", err)
+		}
+		if got, want := marshalSpec(t, prepared), marshalSpec(t, filtered); !bytes.Equal(got, want) {
+			t.Errorf("config %q: FilterPrepared got\n%s\nwant\n%s", cfg, got, want)
+		}
+		if got := marshalSpec(t, spec); !bytes.Equal(got, wantSpec) {
+			t.Errorf("config %q: prepared spec modified", cfg)
+		}
+	}
+}
+
+func TestSetExternalDocs(t *testing.T) {
+	const withDocs = "openapi: 3.0.3\n" +
+		"info: {title: Pets, version: 1.0.0}\n" +
+		"externalDocs: {url: https://source.example.com}\n" +
+		"paths: {}\n"
+	const withoutDocs = "openapi: 3.0.3\ninfo: {title: Pets, version: 1.0.0}\npaths: {}\n"
+	const set = "setExternalDocs: {url: https://docs.example.com, description: Portal}\n"
+	tests := []struct {
+		name, spec, cfg string
+		want            *openapi3.ExternalDocs
+	}{
+		{"set on a spec without docs", withoutDocs, set,
+			&openapi3.ExternalDocs{URL: "https://docs.example.com", Description: "Portal"}},
+		{"set wins over kept docs", withDocs, "externalDocs: true\n" + set,
+			&openapi3.ExternalDocs{URL: "https://docs.example.com", Description: "Portal"}},
+		{"kept docs", withDocs, "externalDocs: true\n",
+			&openapi3.ExternalDocs{URL: "https://source.example.com"}},
+		{"dropped docs", withDocs, "externalDocs: false\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _ := filterTestSpec(t, tt.spec, tt.cfg)
+			if !reflect.DeepEqual(filtered.ExternalDocs, tt.want) {
+				t.Errorf("got externalDocs %+v, want %+v", filtered.ExternalDocs, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneExplicitlyListedIfUnreferenced(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      responses:
+        '200':
+          description: Pets
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+  /admin:
+    delete:
+      operationId: reset
+      responses:
+        '200':
+          description: Reset
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Admin'}
+components:
+  schemas:
+    Pet: {type: object}
+    Admin: {type: object}
+    Unused: {type: object}
+`
+	tests := []struct {
+		name, cfg string
+		want      []string
+	}{
+		{"listed kept", "paths:\n  /pets: [get]\ncomponents:\n  schemas: [Pet, Admin, Unused]\n",
+			[]string{"Admin", "Pet", "Unused"}},
+		{"excluded only pruned", "paths:\n  /pets: [get]\n  /admin: [delete]\nexcludeOperationIds: [reset]\n" +
+			"components:\n  schemas: [Pet, Admin, Unused]\n  pruneExplicitlyListedIfUnreferenced: true\n",
+			[]string{"Pet", "Unused"}},
+		{"unselected only pruned", "paths:\n  /pets: [get]\n" +
+			"components:\n  schemas: [Admin]\n  pruneExplicitlyListedIfUnreferenced: true\n",
+			[]string{"Pet"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _ := filterTestSpec(t, spec, tt.cfg)
+			if got := slices.Sorted(maps.Keys(filtered.Components.Schemas)); !slices.Equal(got, tt.want) {
+				t.Errorf("got schemas %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterStripsToolExtension(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+x-openapi-filter:
+  paths:
+    /pets: [get]
+info:
+  title: Pets
+  version: 1.0.0
+  x-team: pets
+  x-openapi-filter: {note: internal}
+paths:
+  /pets:
+    x-openapi-filter: {note: internal}
+    get:
+      x-openapi-filter: {visibility: public}
+      x-rate-limit: 10
+      responses:
+        '200':
+          description: Pets
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+components:
+  schemas:
+    Pet:
+      type: object
+      x-openapi-filter: {note: internal}
+`
+	tests := []struct {
+		cfg           string
+		wantRateLimit bool
+	}{
+		{"", true},
+		{"keepExtensions: ['*']\n", true},
+		{"keepExtensions: [x-openapi-filter]\n", false},
+		{"stripExtensions: [x-team]\n", true},
+	}
+	for _, tt := range tests {
+		filtered, _ := filterTestSpec(t, spec, "paths:\n  /pets: [get]\n"+tt.cfg)
+		data := marshalSpec(t, filtered)
+		if bytes.Contains(data, []byte(config.ToolConfigKey)) {
+			t.Errorf("config %q: got %s in the filtered spec:\n%s", tt.cfg, config.ToolConfigKey, data)
+		}
+		if got := bytes.Contains(data, []byte("x-rate-limit")); got != tt.wantRateLimit {
+			t.Errorf("config %q: got x-rate-limit kept %v, want %v:\n%s", tt.cfg, got, tt.wantRateLimit, data)
+		}
+	}
+}
+
+func TestStampProvenance(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      responses: {'200': {description: Pets}}
+  /admin:
+    delete:
+      responses: {'204': {description: Reset}}
+`
+	tests := []struct {
+		name, cfg      string
+		wantKey        string
+		wantValue      string
+		wantTimestamp  string
+		wantExtensions int
+	}{
+		{"disabled", "", "", "", "", 0},
+		{"default key", "stampProvenance: {value: public}\n", "x-filtered-by", "public", "", 1},
+		{"custom key and timestamp", "stampProvenance: {key: x-source, timestampKey: x-filtered-at}\n" +
+			"stripExtensions: [x-source, x-filtered-at]\n", "x-source", "openapi-filter", "x-filtered-at", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _ := filterTestSpec(t, spec, "paths:\n  /pets: [get]\n"+tt.cfg)
+			if filtered.Paths.Value("/admin") != nil {
+				t.Fatal("got /admin kept, want only /pets")
+			}
+			op := filtered.Paths.Value("/pets").Get
+			if len(op.Extensions) != tt.wantExtensions {
+				t.Errorf("got extensions %v, want %d", op.Extensions, tt.wantExtensions)
+			}
+			if tt.wantKey != "" && op.Extensions[tt.wantKey] != tt.wantValue {
+				t.Errorf("got %s = %v, want %q", tt.wantKey, op.Extensions[tt.wantKey], tt.wantValue)
+			}
+			if tt.wantTimestamp != "" {
+				timestamp, _ := op.Extensions[tt.wantTimestamp].(string)
+				if _, err := time.Parse(time.RFC3339, timestamp); err != nil {
+					t.Errorf("got %s = %q, want an RFC 3339 time: %v", tt.wantTimestamp, timestamp, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterDropsEmptiedComponents(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      responses:
+        '200': {description: Pets}
+components:
+  schemas:
+    Unused: {type: object}
+`
+	filtered, _ := filterTestSpec(t, spec, "paths:\n  /pets: [get]\ncomponents: {}\n")
+	if filtered.Components != nil {
+		t.Errorf("got components %+v, want them dropped", filtered.Components)
+	}
+	if out := marshalSpec(t, filtered); bytes.Contains(out, []byte(`"components"`)) {
+		t.Errorf("got components in the output:\n%s", out)
+	}
+}
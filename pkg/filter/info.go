@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"fmt"
+	"maps"
+)
+
+// filterInfo strips and overrides the fields of the info object of the
+// filtered spec as configured. The info object is copied, as it is shared
+// with the source spec. Extensions it keeps are still subject to the
+// spec-wide extension filtering, which runs afterwards. Returns an error if
+// the version to bump is not a semantic version.
+func (oaf *OpenAPISpecFilter) filterInfo() error {
+	ic := oaf.cfg.Info
+	if ic == nil || oaf.doc.Info == nil {
+		return nil
+	}
+
+	info := *oaf.doc.Info
+	if !ic.KeepsField("description") {
+		info.Description = ""
+	}
+	if !ic.KeepsField("termsOfService") {
+		info.TermsOfService = ""
+	}
+	if !ic.KeepsField("contact") {
+		info.Contact = nil
+	}
+	if !ic.KeepsField("license") {
+		info.License = nil
+	}
+	if info.Extensions != nil {
+		info.Extensions = maps.Clone(info.Extensions)
+		maps.DeleteFunc(info.Extensions, func(name string, _ any) bool {
+			return !ic.KeepsField(name)
+		})
+	}
+
+	if ic.Title != "" {
+		info.Title = ic.Title
+	}
+	if ic.Description != "" {
+		info.Description = ic.Description
+	}
+	if ic.Version != "" {
+		info.Version = ic.Version
+	}
+	if ic.BumpVersion != "" {
+		version, err := ic.BumpedVersion(info.Version)
+		if err != nil {
+			return fmt.Errorf("ic.BumpedVersion: %w", err)
+		}
+		info.Version = version
+	}
+	oaf.filtered.Info = &info
+	return nil
+}
@@ -0,0 +1,235 @@
+package filter
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/refs"
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// includeLinkTargets keeps the operations targeted by the links of kept
+// responses, by operationId or local operationRef, when IncludeLinkTargets is
+// set. It repeats until the links of the added operations are followed too.
+// A target is kept like an operation selected by a rule: excluded and
+// superseded paths and the operation filters still apply, and links whose
+// target is dropped are pruned by pruneDanglingLinks. Link components only
+// listed, not used by a kept response, do not select their target.
+func (oaf *OpenAPISpecFilter) includeLinkTargets(ctx context.Context) error {
+	if !oaf.cfg.IncludeLinkTargets {
+		return nil
+	}
+	operationsByID := oaf.sourceOperationsByID()
+	tried := make(map[*openapi3.Operation]struct{})
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var added bool
+		for _, link := range oaf.keptResponseLinks() {
+			target, ok := oaf.sourceLinkTarget(link, operationsByID)
+			if !ok {
+				continue
+			}
+			if _, ok := tried[target.op]; ok {
+				continue
+			}
+			tried[target.op] = struct{}{}
+			if oaf.includeLinkTarget(target) {
+				added = true
+			}
+		}
+		if !added {
+			return nil
+		}
+	}
+}
+
+// sourceOperation is an operation of the source spec, with its location.
+type sourceOperation struct {
+	path, method string
+	op           *openapi3.Operation
+}
+
+// sourceOperationsByID returns the operations of the source spec paths by
+// operationId. The first one in path order wins for duplicated operationIds.
+func (oaf *OpenAPISpecFilter) sourceOperationsByID() map[string]sourceOperation {
+	byID := make(map[string]sourceOperation)
+	for _, path := range slices.Sorted(maps.Keys(oaf.doc.Paths.Map())) {
+		ops := oaf.doc.Paths.Value(path).Operations()
+		for _, method := range slices.Sorted(maps.Keys(ops)) {
+			op := ops[method]
+			if _, ok := byID[op.OperationID]; !ok && op.OperationID != "" {
+				byID[op.OperationID] = sourceOperation{path, method, op}
+			}
+		}
+	}
+	return byID
+}
+
+// keptResponseLinks returns the links of the responses of the filtered spec
+// paths, resolving refs to link components.
+func (oaf *OpenAPISpecFilter) keptResponseLinks() []*openapi3.Link {
+	var links []*openapi3.Link
+	walk.Walk(oaf.filtered.Paths, func(v reflect.Value) bool {
+		resp, ok := v.Interface().(*openapi3.Response)
+		if !ok {
+			return true
+		}
+		for _, name := range slices.Sorted(maps.Keys(resp.Links)) {
+			if lr := resp.Links[name]; lr != nil && lr.Value != nil {
+				links = append(links, lr.Value)
+			}
+		}
+		return true
+	})
+	return links
+}
+
+// sourceLinkTarget returns the operation of the source spec paths targeted by
+// the link, if any.
+func (oaf *OpenAPISpecFilter) sourceLinkTarget(
+	link *openapi3.Link,
+	operationsByID map[string]sourceOperation,
+) (sourceOperation, bool) {
+	if link.OperationID != "" {
+		target, ok := operationsByID[link.OperationID]
+		return target, ok
+	}
+	if !strings.HasPrefix(link.OperationRef, "#") {
+		return sourceOperation{}, false
+	}
+	path, method, ok := refs.ParsePathRef(link.OperationRef)
+	if !ok || method == "" {
+		return sourceOperation{}, false
+	}
+	pathItem := oaf.doc.Paths.Value(path)
+	if pathItem == nil {
+		return sourceOperation{}, false
+	}
+	method = strings.ToUpper(method)
+	op := pathItem.GetOperation(method)
+	return sourceOperation{path, method, op}, op != nil
+}
+
+// includeLinkTarget adds a link target operation to the filtered spec. It
+// returns false if the operation is already kept or must be dropped.
+func (oaf *OpenAPISpecFilter) includeLinkTarget(target sourceOperation) bool {
+	path, method := target.path, target.method
+	if oaf.isSuperseded(path) || oaf.cfg.IsPathExcluded(path) {
+		oaf.logger.Debug("link target not kept: path excluded",
+			slog.String("method", method),
+			slog.String("path", path))
+		return false
+	}
+	newPathItem := oaf.filtered.Paths.Value(path)
+	if newPathItem != nil && newPathItem.GetOperation(method) != nil {
+		return false
+	}
+	pathConfig := oaf.cfg.Paths[path]
+	op, ok := oaf.prepareOperation(target.op, method, path, pathConfig)
+	if !ok {
+		return false
+	}
+	oaf.logger.Debug("operation selected as link target",
+		slog.String("method", method),
+		slog.String("path", path),
+		slog.String("operationId", op.OperationID))
+	if newPathItem == nil {
+		newPathItem = &openapi3.PathItem{}
+		oaf.preservePathServers(oaf.doc.Paths.Value(path), newPathItem, pathConfig)
+		oaf.filtered.Paths.Set(path, newPathItem)
+	}
+	newPathItem.SetOperation(method, op)
+	oaf.collectOperation(op)
+	return true
+}
+
+// pruneDanglingLinks removes the links of the filtered spec whose target
+// operation, by operationId or local operationRef, is not kept, and the refs
+// to removed link components. Each removed link is logged as a warning.
+// Targets in other documents are not checked.
+func (oaf *OpenAPISpecFilter) pruneDanglingLinks() {
+	operationIDs := oaf.keptOperationIDs()
+	if comps := oaf.filtered.Components; comps != nil {
+		for _, name := range slices.Sorted(maps.Keys(comps.Links)) {
+			if target, ok := oaf.linkTarget(comps.Links[name], operationIDs); !ok {
+				oaf.warn(CodeLinkTargetNotKept, refs.FormatPointer("components", "links", name),
+					"link target operation not kept, link component dropped",
+					slog.String("name", name),
+					slog.String("target", target))
+				delete(comps.Links, name)
+			}
+		}
+	}
+
+	walk.Walk(oaf.filtered, func(v reflect.Value) bool {
+		resp, ok := v.Interface().(*openapi3.Response)
+		if !ok || len(resp.Links) == 0 {
+			return true
+		}
+		for _, name := range slices.Sorted(maps.Keys(resp.Links)) {
+			if target, ok := oaf.linkTarget(resp.Links[name], operationIDs); !ok {
+				oaf.warn(CodeLinkTargetNotKept, "", "link target operation not kept, response link dropped",
+					slog.String("name", name),
+					slog.String("target", target))
+				delete(resp.Links, name)
+			}
+		}
+		return true
+	})
+}
+
+// keptOperationIDs returns the operationIds of the operations of the
+// filtered spec, including callback operations.
+func (oaf *OpenAPISpecFilter) keptOperationIDs() map[string]struct{} {
+	ids := make(map[string]struct{})
+	walk.Walk(oaf.filtered.Paths, func(v reflect.Value) bool {
+		if op, ok := v.Interface().(*openapi3.Operation); ok && op.OperationID != "" {
+			ids[op.OperationID] = struct{}{}
+		}
+		return true
+	})
+	return ids
+}
+
+// linkTarget returns the target of the link, and whether it is kept in the
+// filtered spec. A ref to a link component must resolve in the filtered spec.
+func (oaf *OpenAPISpecFilter) linkTarget(
+	lr *openapi3.LinkRef,
+	operationIDs map[string]struct{},
+) (string, bool) {
+	if lr == nil {
+		return "", true
+	}
+	if lr.Ref != "" {
+		_, name, ok := refs.ParseRef(lr.Ref)
+		if !ok || !strings.HasPrefix(lr.Ref, "#") {
+			return lr.Ref, true // Not a local link component
+		}
+		_, ok = oaf.filtered.Components.Links[name]
+		return lr.Ref, ok
+	}
+	link := lr.Value
+	switch {
+	case link == nil:
+		return "", true
+	case link.OperationID != "":
+		_, ok := operationIDs[link.OperationID]
+		return link.OperationID, ok
+	case strings.HasPrefix(link.OperationRef, "#"):
+		path, method, ok := refs.ParsePathRef(link.OperationRef)
+		if !ok || method == "" {
+			return link.OperationRef, true
+		}
+		pathItem := oaf.filtered.Paths.Value(path)
+		return link.OperationRef, pathItem != nil && pathItem.Operations()[strings.ToUpper(method)] != nil
+	}
+	return link.OperationRef, true
+}
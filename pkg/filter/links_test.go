@@ -0,0 +1,176 @@
+package filter
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+const linksSpec = `
+openapi: 3.0.3
+info: {title: Shop, version: 1.0.0}
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        '200':
+          description: User
+          links:
+            orders:
+              operationId: listOrders
+              parameters: {id: $response.body#/id}
+            profile:
+              $ref: '#/components/links/Profile'
+  /users/{id}/orders:
+    get:
+      operationId: listOrders
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        '200':
+          description: Orders
+          links:
+            first:
+              operationRef: '#/paths/~1orders~1{orderId}/get'
+              parameters: {orderId: $response.body#/0/id}
+  /users/{id}/profile:
+    get:
+      operationId: getProfile
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        '200': {description: Profile}
+  /orders/{orderId}:
+    get:
+      operationId: getOrder
+      parameters:
+        - {name: orderId, in: path, required: true, schema: {type: string}}
+      responses:
+        '200': {description: Order}
+    delete:
+      operationId: deleteOrder
+      parameters:
+        - {name: orderId, in: path, required: true, schema: {type: string}}
+      responses:
+        '204': {description: Deleted}
+components:
+  links:
+    Profile:
+      operationId: getProfile
+      parameters: {id: $response.body#/id}
+`
+
+func TestIncludeLinkTargets(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       string
+		wantPaths []string
+		wantLinks []string // Links of the kept responses, as "operationId.link"
+		wantDrops int      // Link target warnings
+	}{
+		{
+			name:      "disabled",
+			cfg:       "paths:\n  /users/{id}: [get]\n",
+			wantPaths: []string{"/users/{id}"},
+			wantDrops: 3, // orders, profile and the Profile component
+		},
+		{
+			name:      "enabled",
+			cfg:       "paths:\n  /users/{id}: [get]\nincludeLinkTargets: true\n",
+			wantPaths: []string{"/orders/{orderId}", "/users/{id}", "/users/{id}/orders", "/users/{id}/profile"},
+			wantLinks: []string{"getUser.orders", "getUser.profile", "listOrders.first"},
+		},
+		{
+			name:      "excluded target",
+			cfg:       "paths:\n  /users/{id}: [get]\nincludeLinkTargets: true\nexcludePaths: [/orders/*]\n",
+			wantPaths: []string{"/users/{id}", "/users/{id}/orders", "/users/{id}/profile"},
+			wantLinks: []string{"getUser.orders", "getUser.profile"},
+			wantDrops: 1,
+		},
+		{
+			name:      "excluded operationId",
+			cfg:       "paths:\n  /users/{id}: [get]\nincludeLinkTargets: true\nexcludeOperationIds: [getProfile]\n",
+			wantPaths: []string{"/orders/{orderId}", "/users/{id}", "/users/{id}/orders"},
+			wantLinks: []string{"getUser.orders", "listOrders.first"},
+			wantDrops: 2, // profile and the Profile component
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, diagnostics := filterTestSpec(t, linksSpec, tt.cfg)
+			if got := slices.Sorted(maps.Keys(filtered.Paths.Map())); !slices.Equal(got, tt.wantPaths) {
+				t.Errorf("got paths %v, want %v", got, tt.wantPaths)
+			}
+			if orders := filtered.Paths.Value("/orders/{orderId}"); orders != nil && orders.Delete != nil {
+				t.Error("got DELETE /orders/{orderId}, want only the link target kept")
+			}
+
+			var links []string
+			for _, pathItem := range filtered.Paths.Map() {
+				for _, op := range pathItem.Operations() {
+					for name := range op.Responses.Status(200).Value.Links {
+						links = append(links, op.OperationID+"."+name)
+					}
+				}
+			}
+			slices.Sort(links)
+			if !slices.Equal(links, tt.wantLinks) {
+				t.Errorf("got links %v, want %v", links, tt.wantLinks)
+			}
+
+			var drops int
+			for _, diag := range diagnostics {
+				if diag.Code == CodeLinkTargetNotKept {
+					drops++
+				}
+			}
+			if drops != tt.wantDrops {
+				t.Errorf("got %d link target warnings, want %d: %v", drops, tt.wantDrops, diagnostics)
+			}
+		})
+	}
+}
+
+func TestLinkOperationRefEscaping(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info: {title: Shop, version: 1.0.0}
+paths:
+  /orders:
+    post:
+      responses:
+        '201':
+          description: Created
+          links:
+            escaped:
+              operationRef: '#/paths/~1orders~1{orderId}/get'
+            encoded:
+              operationRef: '#/paths/~1orders~1%7BorderId%7D/get'
+  /orders/{orderId}:
+    get:
+      parameters:
+        - {name: orderId, in: path, required: true, schema: {type: string}}
+      responses:
+        '200': {description: Order}
+`
+	tests := []struct {
+		name, cfg string
+		wantLinks []string
+	}{
+		{"target kept", "paths:\n  /orders: [post]\n  /orders/{orderId}: [get]\n", []string{"encoded", "escaped"}},
+		{"target dropped", "paths:\n  /orders: [post]\n", nil},
+		{"target included", "paths:\n  /orders: [post]\nincludeLinkTargets: true\n", []string{"encoded", "escaped"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _ := filterTestSpec(t, spec, tt.cfg)
+			links := filtered.Paths.Value("/orders").Post.Responses.Status(201).Value.Links
+			if got := slices.Sorted(maps.Keys(links)); !slices.Equal(got, tt.wantLinks) {
+				t.Errorf("got links %v, want %v", got, tt.wantLinks)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package filter
+
+import (
+	"log/slog"
+	"maps"
+	pathpkg "path"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/components"
+	"github.com/zguydev/openapi-filter/internal/oas31"
+	"github.com/zguydev/openapi-filter/internal/refs"
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// Lint codes, stable identifiers of the kinds of config entries flagged by
+// LintConfig.
+const (
+	CodeLintPathNotFound        = "lint-path-not-found"
+	CodeLintPathExcluded        = "lint-path-excluded"
+	CodeLintMethodNotFound      = "lint-method-not-found"
+	CodeLintRedundantMethod     = "lint-redundant-method"
+	CodeLintUnusedResponses     = "lint-unused-responses"
+	CodeLintOperationIDNotFound = "lint-operation-id-not-found"
+	CodeLintOperationIDExcluded = "lint-operation-id-excluded"
+	CodeLintExcludeUnmatched    = "lint-exclude-unmatched"
+	CodeLintComponentNotFound   = "lint-component-not-found"
+	CodeLintRedundantComponent  = "lint-redundant-component"
+	CodeLintDuplicateEntry      = "lint-duplicate-entry"
+)
+
+// LintConfig flags the entries of cfg that are redundant, match nothing in
+// spec or have no effect, so the config can be cleaned up: paths, methods,
+// operationIds and components missing from the spec, methods already selected
+// by "*" or a method group, and components listed while kept anyway through
+// the refs of listed operations. Unlike Normalize, which fails on invalid
+// entries, it only reports warnings, with the config key of each entry. The
+// config must be linted as loaded, before Normalize expands it, and is not
+// modified.
+func LintConfig(cfg *config.Config, spec *openapi3.T) Diagnostics {
+	l := &linter{spec: spec}
+	for _, entry := range cfg.RemovedDuplicates() {
+		key, value, _ := strings.Cut(entry, ": ")
+		l.report(CodeLintDuplicateEntry, key, "duplicate entry, removed when the config is loaded",
+			slog.String("entry", value))
+	}
+	l.lintFilterConfig(&cfg.FilterConfig, "")
+	for _, name := range cfg.OutputNames() {
+		output := cfg.Outputs[name]
+		l.lintFilterConfig(&output, "outputs."+name+".")
+	}
+	return l.diagnostics
+}
+
+type linter struct {
+	spec        *openapi3.T
+	diagnostics Diagnostics
+}
+
+func (l *linter) report(code, key, msg string, attrs ...slog.Attr) {
+	diag := Diagnostic{Severity: SeverityWarning, Code: code, Message: msg, Key: key}
+	if len(attrs) != 0 {
+		diag.Details = make(map[string]any, len(attrs))
+		for _, attr := range attrs {
+			diag.Details[attr.Key] = attr.Value.Resolve().Any()
+		}
+	}
+	l.diagnostics = append(l.diagnostics, diag)
+}
+
+// specPaths returns the paths of the spec matched by the path key, a path
+// template or a glob pattern.
+func (l *linter) specPaths(key string) []string {
+	if l.spec.Paths == nil {
+		return nil
+	}
+	if !strings.ContainsAny(key, "*?[") {
+		if l.spec.Paths.Find(key) == nil {
+			return nil
+		}
+		return []string{key}
+	}
+	var matched []string
+	for _, path := range l.spec.Paths.InMatchingOrder() {
+		if ok, _ := pathpkg.Match(key, path); ok {
+			matched = append(matched, path)
+		}
+	}
+	return matched
+}
+
+func (l *linter) lintFilterConfig(fc *config.FilterConfig, prefix string) {
+	referenced := refs.NewRefsCollector()
+	for _, key := range slices.Sorted(maps.Keys(fc.Paths)) {
+		pc := fc.Paths[key]
+		pathKey := prefix + "paths." + key
+		paths := l.specPaths(key)
+		if len(paths) == 0 {
+			l.report(CodeLintPathNotFound, pathKey, "listed path matches no path of the spec")
+			continue
+		}
+		if !slices.ContainsFunc(paths, func(path string) bool { return !fc.IsPathExcluded(path) }) {
+			l.report(CodeLintPathExcluded, pathKey, "listed path is excluded by excludePaths")
+			continue
+		}
+		l.lintMethods(pc, paths, pathKey)
+		for _, path := range paths {
+			pathItem := l.spec.Paths.Find(path)
+			for method, op := range pathItem.Operations() {
+				if pc.KeepsMethod(method) {
+					referenced.CollectOperation(op)
+				}
+			}
+		}
+		if pc.Components != nil {
+			l.lintComponents(pc.Components, nil, pathKey+".components")
+		}
+	}
+
+	for _, list := range []struct {
+		key string
+		ids []string
+	}{
+		{"includeOperationIds", fc.IncludeOperationIds},
+		{"excludeOperationIds", fc.ExcludeOperationIds},
+	} {
+		for _, id := range list.ids {
+			if !l.hasOperationID(id) {
+				l.report(CodeLintOperationIDNotFound, prefix+list.key, "listed operationId not found in spec",
+					slog.String("operationId", id))
+			}
+		}
+	}
+	for _, id := range fc.IncludeOperationIds {
+		if fc.IsOperationIDExcluded(id) {
+			l.report(CodeLintOperationIDExcluded, prefix+"includeOperationIds",
+				"included operationId is excluded by excludeOperationIds",
+				slog.String("operationId", id))
+		}
+	}
+
+	for _, pattern := range fc.ExcludePaths {
+		if l.spec.Paths == nil || !slices.ContainsFunc(l.spec.Paths.InMatchingOrder(), func(path string) bool {
+			ok, _ := pathpkg.Match(pattern, path)
+			return ok
+		}) {
+			l.report(CodeLintExcludeUnmatched, prefix+"excludePaths", "excluded path pattern matches no path of the spec",
+				slog.String("pattern", pattern))
+		}
+	}
+
+	if fc.Components != nil {
+		l.lintComponents(fc.Components, referenced.Refs(), prefix+"components")
+	}
+}
+
+// lintMethods flags the methods of a path config that are redundant or not
+// defined by any of the spec paths it matches, and its response filters of
+// methods it does not select.
+func (l *linter) lintMethods(pc config.PathConfig, paths []string, pathKey string) {
+	methodsKey := pathKey + ".methods"
+	if slices.Contains(pc.Methods, config.MethodWildcard) && len(pc.Methods) > 1 {
+		l.report(CodeLintRedundantMethod, methodsKey, "methods listed along with *, which selects them all",
+			slog.Any("methods", pc.Methods))
+	}
+	for _, method := range pc.Methods {
+		if method == config.MethodWildcard || strings.HasPrefix(method, config.MethodGroupPrefix) {
+			continue
+		}
+		for _, group := range pc.Methods {
+			name, ok := strings.CutPrefix(group, config.MethodGroupPrefix)
+			if ok && slices.Contains(config.MethodGroups[strings.ToLower(name)], strings.ToUpper(method)) {
+				l.report(CodeLintRedundantMethod, methodsKey, "method already selected by a method group",
+					slog.String("method", method),
+					slog.String("group", group))
+				break
+			}
+		}
+		if !slices.ContainsFunc(paths, func(path string) bool {
+			return l.spec.Paths.Find(path).GetOperation(strings.ToUpper(method)) != nil
+		}) {
+			l.report(CodeLintMethodNotFound, methodsKey, "listed method not defined for the path",
+				slog.String("method", method))
+		}
+	}
+	for _, method := range slices.Sorted(maps.Keys(pc.Responses)) {
+		if !pc.KeepsMethod(method) {
+			l.report(CodeLintUnusedResponses, pathKey+".responses."+method,
+				"responses filtered for a method the path does not select")
+		}
+	}
+}
+
+// lintComponents flags the listed component names matching no component of
+// the spec, those already matched by a pattern of the same list, and, with
+// referenced, those kept anyway as referenced by listed operations.
+func (l *linter) lintComponents(cc *config.FilterComponentsConfig, referenced map[string]struct{}, key string) {
+	comps := l.spec.Components
+	if comps == nil {
+		comps = &openapi3.Components{}
+	}
+	type family struct {
+		def   string
+		names []string
+		keys  []string
+	}
+	var families []family
+	for _, typ := range components.ComponentTypes() {
+		families = append(families, family{
+			components.ComponentTypeToDef(typ),
+			components.ComponentTypeToCfgNames(cc, typ),
+			components.ComponentNames(comps, typ),
+		})
+	}
+	families = append(families, family{oas31.PathItemsDef, cc.PathItems, slices.Sorted(maps.Keys(oas31.PathItems(comps)))})
+
+	for _, f := range families {
+		listKey := key + "." + f.def
+		for _, name := range f.names {
+			isPattern := strings.ContainsAny(name, "*?[")
+			if isPattern {
+				if !slices.ContainsFunc(f.keys, func(k string) bool { ok, _ := pathpkg.Match(name, k); return ok }) {
+					l.report(CodeLintComponentNotFound, listKey, "component name pattern matches no component of the spec",
+						slog.String("pattern", name))
+				}
+				continue
+			}
+			if !slices.Contains(f.keys, name) {
+				l.report(CodeLintComponentNotFound, listKey, "listed component not found in spec",
+					slog.String("name", name))
+				continue
+			}
+			if pattern, ok := matchingPattern(f.names, name); ok {
+				l.report(CodeLintRedundantComponent, listKey, "component already listed by a name pattern",
+					slog.String("name", name),
+					slog.String("pattern", pattern))
+				continue
+			}
+			if _, ok := referenced[refs.FormatRef(f.def, name)]; ok {
+				l.report(CodeLintRedundantComponent, listKey, "listed component already kept as referenced by listed operations",
+					slog.String("name", name))
+			}
+		}
+	}
+}
+
+// matchingPattern returns the first glob pattern of names matching name.
+func matchingPattern(names []string, name string) (string, bool) {
+	for _, pattern := range names {
+		if strings.ContainsAny(pattern, "*?[") {
+			if ok, _ := pathpkg.Match(pattern, name); ok {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// hasOperationID reports whether an operation of the spec, including callback
+// operations, has the operationId.
+func (l *linter) hasOperationID(id string) bool {
+	if l.spec.Paths == nil {
+		return false
+	}
+	var found bool
+	var visit func(pathItem *openapi3.PathItem)
+	visit = func(pathItem *openapi3.PathItem) {
+		for _, op := range pathItem.Operations() {
+			if op.OperationID == id {
+				found = true
+			}
+			for _, cbr := range op.Callbacks {
+				if cbr != nil && cbr.Value != nil {
+					for _, cbPathItem := range cbr.Value.Map() {
+						if cbPathItem != nil {
+							visit(cbPathItem)
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, path := range l.spec.Paths.InMatchingOrder() {
+		visit(l.spec.Paths.Value(path))
+	}
+	return found
+}
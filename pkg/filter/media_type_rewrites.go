@@ -0,0 +1,95 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// rewriteMediaTypes renames the media types of every content map of the
+// filtered spec, in request bodies, responses, parameters and headers, as
+// configured by MediaTypeRewrites. Source media types are matched ignoring
+// case. Two media types of a content map ending up with the same name is an
+// error, reported once however many content maps collide.
+func (oaf *OpenAPISpecFilter) rewriteMediaTypes() error {
+	if len(oaf.cfg.MediaTypeRewrites) == 0 {
+		return nil
+	}
+
+	var collisions []string
+	walk.Walk(oaf.filtered, func(v reflect.Value) bool {
+		if v.Kind() != reflect.Struct || !v.CanAddr() {
+			return true
+		}
+		// Only declared fields, as the promoted Content of a header is
+		// rewritten when its embedded parameter is visited.
+		field, ok := v.Type().FieldByName("Content")
+		if !ok || len(field.Index) != 1 || field.Type != reflect.TypeFor[openapi3.Content]() {
+			return true
+		}
+		content := v.FieldByIndex(field.Index)
+		rewritten, err := oaf.rewriteContent(content.Interface().(openapi3.Content))
+		if err != nil {
+			if !slices.Contains(collisions, err.Error()) {
+				collisions = append(collisions, err.Error())
+			}
+			return true
+		}
+		content.Set(reflect.ValueOf(rewritten))
+		return true
+	})
+	if len(collisions) != 0 {
+		slices.Sort(collisions)
+		return errors.New(strings.Join(collisions, "; "))
+	}
+	return nil
+}
+
+// rewriteContent returns a copy of content with its media types renamed, or
+// content itself if none is.
+func (oaf *OpenAPISpecFilter) rewriteContent(content openapi3.Content) (openapi3.Content, error) {
+	if len(content) == 0 {
+		return content, nil
+	}
+
+	rewritten := make(openapi3.Content, len(content))
+	sources := make(map[string]string, len(content))
+	changed := false
+	for mediaType, mt := range content {
+		target := oaf.rewrittenMediaType(mediaType)
+		if source, ok := sources[target]; ok {
+			return nil, fmt.Errorf("media types %s and %s both rewritten to %s",
+				min(source, mediaType), max(source, mediaType), target)
+		}
+		if target != mediaType {
+			changed = true
+			oaf.logger.Debug("media type rewritten",
+				slog.String("from", mediaType),
+				slog.String("to", target))
+		}
+		sources[target] = mediaType
+		rewritten[target] = mt
+	}
+	if !changed {
+		return content, nil
+	}
+	return rewritten, nil
+}
+
+// rewrittenMediaType returns the MediaTypeRewrites target of a media type,
+// or the media type itself if it is not rewritten.
+func (oaf *OpenAPISpecFilter) rewrittenMediaType(mediaType string) string {
+	for from, to := range oaf.cfg.MediaTypeRewrites {
+		if strings.EqualFold(from, mediaType) {
+			return to
+		}
+	}
+	return mediaType
+}
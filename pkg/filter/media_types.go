@@ -0,0 +1,155 @@
+package filter
+
+import (
+	"log/slog"
+	"maps"
+	"mime"
+	pathpkg "path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// filtersMediaTypes reports whether media types of request bodies and
+// responses are filtered.
+func (oaf *OpenAPISpecFilter) filtersMediaTypes() bool {
+	return len(oaf.cfg.IncludeMediaTypes) != 0 || len(oaf.cfg.ExcludeMediaTypes) != 0
+}
+
+// filterMediaTypes removes the media types not kept by IncludeMediaTypes and
+// ExcludeMediaTypes from the request body and responses of the operation. A
+// request body left without content is dropped, in a copy of the operation,
+// while a response is kept without content, as it needs its description.
+// Content is filtered before refs are collected.
+func (oaf *OpenAPISpecFilter) filterMediaTypes(
+	op *openapi3.Operation,
+	method, path string,
+) *openapi3.Operation {
+	if !oaf.filtersMediaTypes() {
+		return op
+	}
+	if op.Responses != nil {
+		for _, respr := range op.Responses.Map() {
+			if resp := respr.Value; resp != nil && len(resp.Content) != 0 {
+				resp.Content = oaf.filterContent(resp.Content)
+			}
+		}
+	}
+
+	rbr := op.RequestBody
+	if rbr == nil || rbr.Value == nil {
+		return op
+	}
+	rbr.Value.Content = oaf.filterContent(rbr.Value.Content)
+	if len(rbr.Value.Content) != 0 {
+		return op
+	}
+	oaf.logger.Debug("request body dropped: no media type kept",
+		slog.String("ref", rbr.Ref),
+		slog.String("method", method),
+		slog.String("path", path))
+	filteredOp := *op
+	filteredOp.RequestBody = nil
+	return &filteredOp
+}
+
+// filterComponentMediaTypes filters the media types of the request body and
+// response components. Request body components left without content are
+// dropped, as operations drop them too.
+func (oaf *OpenAPISpecFilter) filterComponentMediaTypes(comps *openapi3.Components) {
+	if !oaf.filtersMediaTypes() {
+		return
+	}
+	for name, rbr := range comps.RequestBodies {
+		if rbr.Value == nil {
+			continue
+		}
+		rbr.Value.Content = oaf.filterContent(rbr.Value.Content)
+		if len(rbr.Value.Content) == 0 {
+			oaf.logger.Debug("request body component dropped: no media type kept",
+				slog.String("name", name))
+			delete(comps.RequestBodies, name)
+		}
+	}
+	for _, respr := range comps.Responses {
+		if respr.Value != nil && len(respr.Value.Content) != 0 {
+			respr.Value.Content = oaf.filterContent(respr.Value.Content)
+		}
+	}
+}
+
+// filterContent returns the entries of content with a kept media type, or
+// nil if there are none.
+func (oaf *OpenAPISpecFilter) filterContent(content openapi3.Content) openapi3.Content {
+	filtered := maps.Clone(content)
+	maps.DeleteFunc(filtered, func(mediaType string, _ *openapi3.MediaType) bool {
+		return !oaf.isMediaTypeKept(mediaType)
+	})
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
+
+// isMediaTypeKept reports whether a media type is kept by IncludeMediaTypes
+// and ExcludeMediaTypes. Patterns are matched against the media type without
+// parameters, ignoring case, e.g. "application/*+json".
+func (oaf *OpenAPISpecFilter) isMediaTypeKept(mediaType string) bool {
+	base := strings.ToLower(baseMediaType(mediaType))
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, err := pathpkg.Match(strings.ToLower(pattern), base); err == nil && ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(oaf.cfg.IncludeMediaTypes) != 0 && !matches(oaf.cfg.IncludeMediaTypes) {
+		return false
+	}
+	return !matches(oaf.cfg.ExcludeMediaTypes)
+}
+
+// hasRequiredResponseMediaType reports whether the operation has a success
+// (2xx) response with content of the required media type. Media type
+// parameters, such as charset, are ignored. Every operation matches when no
+// media type is required.
+func (oaf *OpenAPISpecFilter) hasRequiredResponseMediaType(op *openapi3.Operation) bool {
+	required := oaf.cfg.RequireResponseMediaType
+	if required == "" {
+		return true
+	}
+	if op.Responses == nil {
+		return false
+	}
+
+	for code, respr := range op.Responses.Map() {
+		if !isSuccessStatus(code) || respr.Value == nil {
+			continue
+		}
+		for mediaType := range respr.Value.Content {
+			if sameMediaType(mediaType, required) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSuccessStatus reports whether a response key is a 2xx status code or the
+// 2XX range.
+func isSuccessStatus(code string) bool {
+	return len(code) == 3 && code[0] == '2'
+}
+
+// sameMediaType compares two media types, ignoring parameters and case.
+func sameMediaType(a, b string) bool {
+	return strings.EqualFold(baseMediaType(a), baseMediaType(b))
+}
+
+func baseMediaType(mediaType string) string {
+	if base, _, err := mime.ParseMediaType(mediaType); err == nil {
+		return base
+	}
+	return strings.TrimSpace(mediaType)
+}
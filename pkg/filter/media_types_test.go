@@ -0,0 +1,90 @@
+package filter
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestFilterMediaTypesDropsEmptiedRequestBodyComponents(t *testing.T) {
+	filtered, _ := filterTestSpec(t, testSpec, `
+paths: {/pet: [put]}
+excludeMediaTypes: ["application/*"]
+components: {requestBodies: [Pet]}
+`)
+	if comps := filtered.Components; comps != nil && comps.RequestBodies["Pet"] != nil {
+		t.Errorf("request body component without content kept: %+v", comps.RequestBodies["Pet"].Value)
+	}
+	if err := filtered.Validate(context.Background()); err != nil {
+		t.Errorf("filtered spec is invalid: %v", err)
+	}
+}
+
+func TestFilterMediaTypesKeepsMatchingContent(t *testing.T) {
+	filtered, _ := filterTestSpec(t, testSpec, `
+paths: {/pet: [put]}
+includeMediaTypes: [application/xml]
+`)
+	op := filtered.Paths.Value("/pet").Put
+	content := op.Responses.Value("200").Value.Content
+	if _, ok := content["application/json"]; ok {
+		t.Errorf("excluded media type kept: %v", content)
+	}
+	if _, ok := content["application/xml"]; !ok {
+		t.Errorf("included media type dropped: %v", content)
+	}
+	if op.RequestBody == nil || len(op.RequestBody.Value.Content) != 1 {
+		t.Errorf("request body not filtered to application/xml: %+v", op.RequestBody)
+	}
+}
+
+func TestRequireResponseMediaType(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /json:
+    get:
+      responses:
+        '200':
+          description: JSON
+          content:
+            application/json; charset=utf-8: {schema: {type: object}}
+  /xml:
+    get:
+      responses:
+        '200':
+          description: XML
+          content:
+            application/xml: {schema: {type: object}}
+        default:
+          description: Error
+          content:
+            application/json: {schema: {type: object}}
+  /range:
+    get:
+      responses:
+        2XX:
+          description: JSON
+          content:
+            application/json: {schema: {type: object}}
+  /empty:
+    delete:
+      responses:
+        '204': {description: Deleted}
+`
+	tests := []struct {
+		cfg  string
+		want []string
+	}{
+		{"", []string{"DELETE /empty", "GET /json", "GET /range", "GET /xml"}},
+		{"requireResponseMediaType: application/json\n", []string{"GET /json", "GET /range"}},
+		{"requireResponseMediaType: Application/XML\n", []string{"GET /xml"}},
+	}
+	for _, tt := range tests {
+		filtered, _ := filterTestSpec(t, spec, "defaultPath: ['*']\n"+tt.cfg)
+		if got := operationKeys(filtered); !slices.Equal(got, tt.want) {
+			t.Errorf("config %q: got operations %v, want %v", tt.cfg, got, tt.want)
+		}
+	}
+}
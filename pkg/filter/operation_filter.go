@@ -0,0 +1,38 @@
+package filter
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// OperationFilter is a custom rule deciding whether an operation is kept,
+// for policies the config cannot express. It is called for the operations
+// kept by the configuration, once the operation-level filters are applied,
+// and must not modify the operation.
+type OperationFilter interface {
+	Keep(path, method string, op *openapi3.Operation) bool
+}
+
+// OperationFilterFunc adapts a function to an OperationFilter.
+type OperationFilterFunc func(path, method string, op *openapi3.Operation) bool
+
+// Keep calls f(path, method, op).
+func (f OperationFilterFunc) Keep(path, method string, op *openapi3.Operation) bool {
+	return f(path, method, op)
+}
+
+// AddOperationFilters registers custom operation filters. An operation is
+// kept only if every filter keeps it.
+func (oaf *OpenAPISpecFilter) AddOperationFilters(filters ...OperationFilter) {
+	oaf.opFilters = append(oaf.opFilters, filters...)
+}
+
+// keptByOperationFilters reports whether every custom operation filter keeps
+// the operation.
+func (oaf *OpenAPISpecFilter) keptByOperationFilters(path, method string, op *openapi3.Operation) bool {
+	for _, f := range oaf.opFilters {
+		if !f.Keep(path, method, op) {
+			return false
+		}
+	}
+	return true
+}
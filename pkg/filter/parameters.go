@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"log/slog"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// filterParameters returns a copy of the operation without the parameters
+// excluded by ExcludeParameterNames and ExcludeParameterLocations. The operation is returned as is when no
+// parameter is excluded.
+func (oaf *OpenAPISpecFilter) filterParameters(
+	op *openapi3.Operation,
+	method, path string,
+) *openapi3.Operation {
+	params := oaf.excludeParameters(op.Parameters, method, path)
+	if len(params) == len(op.Parameters) {
+		return op
+	}
+	filteredOp := *op
+	filteredOp.Parameters = params
+	return &filteredOp
+}
+
+// excludeParameters returns the parameters that are not excluded by
+// ExcludeParameterNames or ExcludeParameterLocations. Referenced parameters
+// are matched by the name and location of the resolved component.
+func (oaf *OpenAPISpecFilter) excludeParameters(
+	params openapi3.Parameters,
+	method, path string,
+) openapi3.Parameters {
+	if len(oaf.cfg.ExcludeParameterNames)+len(oaf.cfg.ExcludeParameterLocations) == 0 || len(params) == 0 {
+		return params
+	}
+
+	kept := make(openapi3.Parameters, 0, len(params))
+	for _, paramr := range params {
+		if p := paramr.Value; p != nil &&
+			(oaf.cfg.IsParameterExcluded(p.Name, p.In) || oaf.cfg.IsParameterLocationExcluded(p.In)) {
+			oaf.logger.Debug("parameter excluded",
+				slog.String("name", p.Name),
+				slog.String("in", p.In),
+				slog.String("method", method),
+				slog.String("path", path))
+			continue
+		}
+		kept = append(kept, paramr)
+	}
+	return kept
+}
+
+// isParameterLocationExcluded reports whether the parameter component is in a
+// location listed in ExcludeParameterLocations.
+func (oaf *OpenAPISpecFilter) isParameterLocationExcluded(paramr *openapi3.ParameterRef) bool {
+	return paramr != nil && paramr.Value != nil && oaf.cfg.IsParameterLocationExcluded(paramr.Value.In)
+}
@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const parametersSpec = `
+openapi: 3.0.3
+info: {title: Items, version: 1.0.0}
+paths:
+  /items/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: string}
+      - name: X-Trace
+        in: header
+        schema: {type: string}
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Debug'
+        - name: id
+          in: query
+          schema: {type: string}
+        - name: session
+          in: cookie
+          schema: {type: string}
+        - name: limit
+          in: query
+          schema: {type: integer}
+      responses:
+        '200': {description: Item}
+components:
+  parameters:
+    Debug:
+      name: X-Debug
+      in: header
+      schema: {type: boolean}
+`
+
+func parameterKeys(params openapi3.Parameters) []string {
+	var keys []string
+	for _, paramr := range params {
+		keys = append(keys, paramr.Value.Name+":"+paramr.Value.In)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestExcludeParameters(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           string
+		wantOperation []string
+		wantPath      []string
+	}{
+		{
+			name:          "none",
+			cfg:           "paths:\n  /items/{id}: [get]\n",
+			wantOperation: []string{"X-Debug:header", "id:query", "limit:query", "session:cookie"},
+			wantPath:      []string{"X-Trace:header", "id:path"},
+		},
+		{
+			name:          "by name and location, referenced",
+			cfg:           "paths:\n  /items/{id}: [get]\nexcludeParameterNames: [x-debug:header, X-Trace:header]\n",
+			wantOperation: []string{"id:query", "limit:query", "session:cookie"},
+			wantPath:      []string{"id:path"},
+		},
+		{
+			name:          "bare name keeps path parameters",
+			cfg:           "paths:\n  /items/{id}: [get]\nexcludeParameterNames: [id]\n",
+			wantOperation: []string{"X-Debug:header", "limit:query", "session:cookie"},
+			wantPath:      []string{"X-Trace:header", "id:path"},
+		},
+		{
+			name:          "by location",
+			cfg:           "paths:\n  /items/{id}: [get]\nexcludeParameterLocations: [cookie, header]\n",
+			wantOperation: []string{"id:query", "limit:query"},
+			wantPath:      []string{"id:path"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _ := filterTestSpec(t, parametersSpec, tt.cfg)
+			pathItem := filtered.Paths.Value("/items/{id}")
+			if got := parameterKeys(pathItem.Get.Parameters); !slices.Equal(got, tt.wantOperation) {
+				t.Errorf("got operation parameters %v, want %v", got, tt.wantOperation)
+			}
+			if got := parameterKeys(pathItem.Parameters); !slices.Equal(got, tt.wantPath) {
+				t.Errorf("got path parameters %v, want %v", got, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package filter
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// FilterPathItem returns a copy of the path item holding only the operations
+// selected by cfg, see [config.PathConfig.KeepsMethod], and its path-level
+// parameters. Path-level servers are kept if cfg preserves them, the global
+// PreservePathServers default being false. Unlike Filter, it applies no
+// other filter, e.g. response codes, and does not resolve refs or path
+// preset references. The operations are shared with the source path item.
+func FilterPathItem(item *openapi3.PathItem, cfg config.PathConfig) *openapi3.PathItem {
+	filtered := &openapi3.PathItem{Parameters: item.Parameters}
+	for method, op := range item.Operations() {
+		if cfg.KeepsMethod(method) {
+			filtered.SetOperation(method, op)
+		}
+	}
+	if cfg.ShouldPreserveServers(false) {
+		filtered.Servers = item.Servers
+	}
+	return filtered
+}
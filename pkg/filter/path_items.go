@@ -0,0 +1,140 @@
+package filter
+
+import (
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/zguydev/openapi-filter/internal/components"
+	"github.com/zguydev/openapi-filter/internal/oas31"
+	"github.com/zguydev/openapi-filter/internal/refs"
+)
+
+// filterPathItems copies to the filtered spec the webhooks and the reusable
+// path items of components.pathItems that are listed, or referenced by the
+// kept operations, e.g. by their callbacks, or by the webhooks, and collects
+// the refs they hold. The loader does not model these OpenAPI 3.1 objects, so
+// they are copied as raw values, and their refs to path items are followed
+// here. Listing path items for an OpenAPI 3.0 spec is reported with a
+// warning.
+func (oaf *OpenAPISpecFilter) filterPathItems(listed []string) {
+	if len(listed) != 0 && strings.HasPrefix(oaf.doc.OpenAPI, "3.0") {
+		oaf.warn(CodePathItemsUnsupported, "", "path items listed for an OpenAPI 3.0 spec, ignored",
+			slog.Any("names", listed))
+		listed = nil
+	}
+
+	queue := slices.Clone(listed)
+	for ref := range oaf.collector.Refs() {
+		if def, name, ok := refs.ParseRef(ref); ok && def == oas31.PathItemsDef {
+			queue = append(queue, name)
+		}
+	}
+	queue = append(queue, oaf.keepWebhooks()...)
+	slices.Sort(queue)
+
+	source := oas31.PathItems(oaf.doc.Components)
+	kept := make(map[string]any)
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := kept[name]; ok {
+			continue
+		}
+		item, ok := source[name]
+		if !ok {
+			oaf.warn(CodeComponentNotFound, "", "component not found",
+				slog.String("def", oas31.PathItemsDef),
+				slog.String("name", name))
+			continue
+		}
+		oaf.logger.Debug("keeping path item", slog.String("name", name))
+		kept[name] = item
+		for _, ref := range rawRefs(item) {
+			queue = oaf.collectRawRef(ref, queue)
+		}
+	}
+	if len(kept) == 0 {
+		return
+	}
+	comps := oaf.filtered.Components
+	comps.Extensions = maps.Clone(comps.Extensions)
+	if comps.Extensions == nil {
+		comps.Extensions = make(map[string]any)
+	}
+	comps.Extensions[oas31.PathItemsDef] = kept
+}
+
+// keepWebhooks copies the webhooks of the spec to the filtered spec, all of
+// them, as they are not selected by the config, collects the components they
+// reference and returns the names of the path items they reference.
+func (oaf *OpenAPISpecFilter) keepWebhooks() []string {
+	webhooks := oas31.Webhooks(oaf.doc)
+	if len(webhooks) == 0 {
+		return nil
+	}
+	oaf.filtered.Extensions = maps.Clone(oaf.filtered.Extensions)
+	if oaf.filtered.Extensions == nil {
+		oaf.filtered.Extensions = make(map[string]any)
+	}
+	oaf.filtered.Extensions[oas31.WebhooksKey] = webhooks
+
+	var pathItems []string
+	for _, ref := range rawRefs(webhooks) {
+		pathItems = oaf.collectRawRef(ref, pathItems)
+	}
+	return pathItems
+}
+
+// collectRawRef collects a component ref found in a raw value, with the
+// component it references, or appends the name of the referenced path item
+// to pathItems.
+func (oaf *OpenAPISpecFilter) collectRawRef(ref string, pathItems []string) []string {
+	def, name, ok := refs.ParseRef(ref)
+	if !ok {
+		return pathItems
+	}
+	if def == oas31.PathItemsDef {
+		return append(pathItems, name)
+	}
+	oaf.collector.AddRef(ref)
+	if typ, ok := components.ComponentDefToType(def); ok && oaf.doc.Components != nil {
+		oaf.collector.CollectComponent(oaf.doc.Components, typ, name)
+	}
+	return pathItems
+}
+
+// rawRefs returns the sorted "$ref" values found in a raw value, decoded
+// from JSON or YAML.
+func rawRefs(v any) []string {
+	var found []string
+	var visit func(v reflect.Value)
+	visit = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Interface:
+			if !v.IsNil() {
+				visit(v.Elem())
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				if iter.Key().Interface() == "$ref" {
+					if ref, ok := iter.Value().Interface().(string); ok {
+						found = append(found, ref)
+					}
+					continue
+				}
+				visit(iter.Value())
+			}
+		case reflect.Slice:
+			for i := range v.Len() {
+				visit(v.Index(i))
+			}
+		}
+	}
+	visit(reflect.ValueOf(v))
+	slices.Sort(found)
+	return slices.Compact(found)
+}
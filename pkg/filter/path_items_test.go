@@ -0,0 +1,119 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zguydev/openapi-filter/internal/oas31"
+)
+
+const pathItemsSpec = `
+openapi: 3.1.0
+info: {title: Hooks, version: 1.0.0}
+paths: {}
+components:
+  pathItems:
+    PetEvent:
+      post:
+        responses:
+          '200': {description: Received}
+  schemas:
+    Pet:
+      type: object
+`
+
+func TestFilterPathItemsOnlyIsNotEmpty(t *testing.T) {
+	cfg := loadTestConfig(t, `
+paths: {}
+components: {schemas: [], pathItems: [PetEvent]}
+failOnEmpty: true
+`)
+	filtered, diagnostics, err := newTestFilter(cfg).FilterWithDiagnostics(context.Background(), loadTestSpec(t, pathItemsSpec))
+	if errors.Is(err, ErrEmptyOutput) {
+		t.Fatalf("spec keeping only path items reported as empty")
+	}
+	if err != nil {
+		t.Fatalf("FilterWithDiagnostics: %v", err)
+	}
+	for _, diag := range diagnostics {
+		if diag.Code == CodeEmptyOutput {
+			t.Errorf("unexpected diagnostic: %v", diag)
+		}
+	}
+	if _, ok := oas31.PathItems(filtered.Components)["PetEvent"]; !ok {
+		t.Errorf("listed path item not kept")
+	}
+}
+
+const webhooksSpec = `
+openapi: 3.1.0
+info: {title: Hooks, version: 1.0.0}
+paths: {}
+webhooks:
+  newPet: {$ref: '#/components/pathItems/PetEvent'}
+  petDeleted:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Missing'}
+      responses:
+        '200': {description: Received}
+components:
+  pathItems:
+    PetEvent:
+      post:
+        requestBody:
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+        responses:
+          '200': {description: Received}
+    Unused:
+      post:
+        responses:
+          '200': {description: Received}
+  schemas:
+    Pet:
+      type: object
+    Other:
+      type: object
+`
+
+func TestFilterWebhooks(t *testing.T) {
+	tests := []struct {
+		name, cfg string
+	}{
+		{"default", "paths: {}\ncomponents: {}\n"},
+		{"keep extensions", "paths: {}\ncomponents: {}\nkeepExtensions: [x-internal]\n"},
+		{"dereference output", "paths: {}\ncomponents: {}\ndereferenceOutput: true\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, diagnostics := filterTestSpec(t, webhooksSpec, tt.cfg)
+			webhooks := oas31.Webhooks(filtered)
+			if _, ok := webhooks["newPet"]; !ok || len(webhooks) != 2 {
+				t.Errorf("got webhooks %v, want newPet and petDeleted", webhooks)
+			}
+			pathItems := oas31.PathItems(filtered.Components)
+			if _, ok := pathItems["PetEvent"]; !ok || len(pathItems) != 1 {
+				t.Errorf("got path items %v, want PetEvent", pathItems)
+			}
+			if filtered.Components == nil || filtered.Components.Schemas["Pet"] == nil || len(filtered.Components.Schemas) != 1 {
+				t.Errorf("got components %+v, want the Pet schema", filtered.Components)
+			}
+
+			var dangling []string
+			for _, diag := range diagnostics {
+				if diag.Code == CodeDanglingRef {
+					dangling = append(dangling, diag.String())
+				}
+			}
+			if len(dangling) != 1 || !strings.Contains(dangling[0], "#/components/schemas/Missing") {
+				t.Errorf("got dangling refs %v, want the Missing schema of petDeleted", dangling)
+			}
+		})
+	}
+}
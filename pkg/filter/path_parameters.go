@@ -0,0 +1,79 @@
+package filter
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// filterPathParameters copies the path-level parameters of every kept path to
+// the filtered spec, as they apply to all of its operations, and collects
+// their refs. Parameters excluded by name or location are stripped, and
+// with PrunePathParameters, so are parameters overridden by every kept
+// operation of the path.
+func (oaf *OpenAPISpecFilter) filterPathParameters() {
+	for path, newPathItem := range oaf.filtered.Paths.Map() {
+		pathItem := oaf.doc.Paths.Value(path)
+		if pathItem == nil || len(pathItem.Parameters) == 0 {
+			continue
+		}
+		params := oaf.excludeParameters(pathItem.Parameters, "", path)
+		if oaf.cfg.PrunePathParameters {
+			params = oaf.pruneOverriddenParameters(params, newPathItem, path)
+		}
+		if len(params) == 0 {
+			continue
+		}
+		newPathItem.Parameters = params
+
+		oaf.excludeSchemas(params)
+		oaf.trimExamples(params)
+		oaf.collapseUnions(params)
+		oaf.keepRequiredProperties(params)
+		oaf.collector.CollectParameters(params)
+	}
+}
+
+// pruneOverriddenParameters returns the path-level parameters that are not
+// overridden, by a parameter with the same name and location, in every
+// operation of the filtered path item.
+func (oaf *OpenAPISpecFilter) pruneOverriddenParameters(
+	params openapi3.Parameters,
+	newPathItem *openapi3.PathItem,
+	path string,
+) openapi3.Parameters {
+	ops := newPathItem.Operations()
+	return slices.DeleteFunc(slices.Clone(params), func(paramr *openapi3.ParameterRef) bool {
+		p := paramr.Value
+		if p == nil {
+			return false
+		}
+		for _, op := range ops {
+			if !overridesParameter(op, p) {
+				return false
+			}
+		}
+		oaf.logger.Debug("path parameter pruned: overridden by every kept operation",
+			slog.String("name", p.Name),
+			slog.String("in", p.In),
+			slog.String("path", path))
+		return true
+	})
+}
+
+// overridesParameter reports whether the operation defines a parameter with
+// the same name and location as p. Header names are case-insensitive.
+func overridesParameter(op *openapi3.Operation, p *openapi3.Parameter) bool {
+	for _, opParamr := range op.Parameters {
+		opParam := opParamr.Value
+		if opParam == nil || opParam.In != p.In {
+			continue
+		}
+		if opParam.Name == p.Name || (p.In == openapi3.ParameterInHeader && strings.EqualFold(opParam.Name, p.Name)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package filter
+
+import (
+	"log/slog"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// prefixPaths prepends the configured PathPrefix, normalized by
+// [config.FilterConfig.Normalize], to every path of the filtered spec.
+func (oaf *OpenAPISpecFilter) prefixPaths() {
+	prefix := oaf.cfg.PathPrefix
+	if prefix == "" {
+		return
+	}
+
+	paths := openapi3.NewPaths()
+	paths.Extensions = oaf.filtered.Paths.Extensions
+	for path, pathItem := range oaf.filtered.Paths.Map() {
+		paths.Set(prefix+path, pathItem)
+	}
+	oaf.filtered.Paths = paths
+	oaf.logger.Debug("paths prefixed", slog.String("prefix", prefix))
+}
@@ -0,0 +1,190 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// errNoValue is returned when a JSON Pointer does not reference a value.
+var errNoValue = errors.New("no value at pointer")
+
+// removePointers removes the values referenced by the JSON Pointers of
+// RemovePointers from the filtered spec, after every other filter. Pointers
+// referencing no value, e.g. one already filtered out, are reported with a
+// warning.
+func (oaf *OpenAPISpecFilter) removePointers() {
+	for _, pointer := range oaf.cfg.RemovePointers {
+		tokens, err := config.ParsePointer(pointer)
+		if err != nil {
+			continue // Reported by Normalize
+		}
+		if _, err := removeValue(reflect.ValueOf(oaf.filtered), tokens); err != nil {
+			oaf.warn(CodePointerNotRemoved, "", "value not removed",
+				slog.String("pointer", pointer),
+				slog.Any("error", err))
+			continue
+		}
+		oaf.logger.Debug("value removed", slog.String("pointer", pointer))
+	}
+}
+
+// removeValue removes the value referenced by tokens from v, following the
+// JSON names of the spec objects, and returns v, or its replacement when v is
+// a list. References are not followed.
+func removeValue(v reflect.Value, tokens []string) (reflect.Value, error) {
+	token, rest := tokens[0], tokens[1:]
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, errNoValue
+		}
+		switch m := v.Interface().(type) {
+		case *openapi3.Paths:
+			return v, removeFromMapLike(m.Value, m.Delete, m.Extensions, token, rest)
+		case *openapi3.Responses:
+			return v, removeFromMapLike(m.Value, m.Delete, m.Extensions, token, rest)
+		case *openapi3.Callback:
+			return v, removeFromMapLike(m.Value, m.Delete, m.Extensions, token, rest)
+		}
+		_, err := removeValue(v.Elem(), tokens)
+		return v, err
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, errNoValue
+		}
+		elem, err := removeValue(v.Elem(), tokens)
+		if err != nil {
+			return v, err
+		}
+		replaced := reflect.New(v.Type()).Elem()
+		replaced.Set(elem)
+		return replaced, nil
+
+	case reflect.Struct:
+		if !v.CanAddr() {
+			addressable := reflect.New(v.Type()).Elem()
+			addressable.Set(v)
+			v = addressable
+		}
+		if ref, value := v.FieldByName("Ref"), v.FieldByName("Value"); ref.IsValid() && value.IsValid() {
+			if ref.String() != "" {
+				return v, fmt.Errorf("value is a reference to %s", ref.String())
+			}
+			_, err := removeValue(value, tokens)
+			return v, err
+		}
+		if extensions := v.FieldByName("Extensions"); isExtension(token) && extensions.IsValid() {
+			_, err := removeValue(extensions, tokens)
+			return v, err
+		}
+		field, ok := jsonField(v, token)
+		if !ok {
+			return v, errNoValue
+		}
+		if len(rest) == 0 {
+			if field.IsZero() {
+				return v, errNoValue
+			}
+			field.SetZero()
+			return v, nil
+		}
+		replaced, err := removeValue(field, rest)
+		if err != nil {
+			return v, err
+		}
+		field.Set(replaced)
+		return v, nil
+
+	case reflect.Map:
+		key := reflect.ValueOf(token)
+		if !key.CanConvert(v.Type().Key()) {
+			return v, errNoValue
+		}
+		key = key.Convert(v.Type().Key())
+		elem := v.MapIndex(key)
+		if !elem.IsValid() {
+			return v, errNoValue
+		}
+		if len(rest) == 0 {
+			v.SetMapIndex(key, reflect.Value{})
+			return v, nil
+		}
+		replaced, err := removeValue(elem, rest)
+		if err != nil {
+			return v, err
+		}
+		v.SetMapIndex(key, replaced)
+		return v, nil
+
+	case reflect.Slice:
+		i, err := strconv.Atoi(token)
+		if err != nil || i < 0 || i >= v.Len() {
+			return v, errNoValue
+		}
+		if len(rest) == 0 {
+			removed := reflect.MakeSlice(v.Type(), 0, v.Len()-1)
+			removed = reflect.AppendSlice(removed, v.Slice(0, i))
+			return reflect.AppendSlice(removed, v.Slice(i+1, v.Len())), nil
+		}
+		replaced, err := removeValue(v.Index(i), rest)
+		if err != nil {
+			return v, err
+		}
+		v.Index(i).Set(replaced)
+		return v, nil
+	}
+	return v, errNoValue
+}
+
+// removeFromMapLike removes the value referenced by token and rest from a
+// map-like spec object, such as Paths, given its accessors.
+func removeFromMapLike[V any](
+	value func(string) V,
+	del func(string),
+	extensions map[string]any,
+	token string,
+	rest []string,
+) error {
+	if isExtension(token) {
+		_, err := removeValue(reflect.ValueOf(extensions), append([]string{token}, rest...))
+		return err
+	}
+	elem := reflect.ValueOf(value(token))
+	if elem.IsNil() {
+		return errNoValue
+	}
+	if len(rest) == 0 {
+		del(token)
+		return nil
+	}
+	_, err := removeValue(elem, rest)
+	return err
+}
+
+// isExtension reports whether the pointer token names a vendor extension.
+func isExtension(token string) bool {
+	return strings.HasPrefix(token, "x-")
+}
+
+// jsonField returns the field of the struct v with the given JSON name.
+func jsonField(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
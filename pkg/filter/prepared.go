@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/refs"
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// PreparedSpec is a source spec indexed once to be filtered many times with
+// different configurations. It holds the refs used by each operation of the
+// spec, transitively, so kept operations are not walked again on every
+// filtering. Filtering a prepared spec never modifies it.
+type PreparedSpec struct {
+	doc     *openapi3.T
+	opRefs  map[*openapi3.Operation]map[string]struct{} // Refs used by each operation
+	allRefs map[string]struct{}                         // Refs used by any operation
+}
+
+// Prepare indexes the refs of the operations of spec. The spec must not be
+// modified afterwards.
+func Prepare(spec *openapi3.T) (*PreparedSpec, error) {
+	if spec == nil || spec.Paths == nil {
+		return nil, fmt.Errorf("spec has no paths")
+	}
+	ps := &PreparedSpec{
+		doc:    spec,
+		opRefs: make(map[*openapi3.Operation]map[string]struct{}),
+	}
+	all := refs.NewRefsCollector()
+	for _, pathItem := range spec.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			collector := refs.NewRefsCollector()
+			collector.CollectOperation(op)
+			ps.opRefs[op] = collector.Refs()
+			all.AddRefs(collector.Refs())
+		}
+	}
+	ps.allRefs = all.Refs()
+	return ps, nil
+}
+
+// Filter filters the prepared spec with cfg, see [OpenAPISpecFilter.Filter].
+func (ps *PreparedSpec) Filter(cfg config.FilterConfig, logger *slog.Logger) (*openapi3.T, error) {
+	oaf := NewOpenAPISpecFilter(&config.Config{FilterConfig: cfg}, logger)
+	return oaf.FilterPrepared(ps)
+}
+
+// FilterPrepared is like Filter, using the index of a prepared spec and
+// leaving it unmodified.
+func (oaf *OpenAPISpecFilter) FilterPrepared(ps *PreparedSpec) (*openapi3.T, error) {
+	oaf.prepared = ps
+	defer func() { oaf.prepared = nil }()
+	return oaf.Filter(ps.doc)
+}
+
+// indexCopy returns the index of doc, a copy of the prepared spec made by
+// walk.Clone, or nil if ps is nil.
+func (ps *PreparedSpec) indexCopy(doc *openapi3.T) *PreparedSpec {
+	if ps == nil {
+		return nil
+	}
+	cp := &PreparedSpec{
+		doc:     doc,
+		opRefs:  make(map[*openapi3.Operation]map[string]struct{}, len(ps.opRefs)),
+		allRefs: ps.allRefs,
+	}
+	for path, pathItem := range ps.doc.Paths.Map() {
+		cpOps := doc.Paths.Value(path).Operations()
+		for method, op := range pathItem.Operations() {
+			cp.opRefs[cpOps[method]] = ps.opRefs[op]
+		}
+	}
+	return cp
+}
+
+// operationRefs returns the indexed refs of a source operation. Operations
+// copied by the filter, e.g. to drop responses, are not indexed.
+func (ps *PreparedSpec) operationRefs(op *openapi3.Operation) (map[string]struct{}, bool) {
+	if ps == nil {
+		return nil, false
+	}
+	refs, ok := ps.opRefs[op]
+	return refs, ok
+}
+
+// allOperationRefs returns the refs used by any operation of the spec.
+func (ps *PreparedSpec) allOperationRefs() (map[string]struct{}, bool) {
+	if ps == nil {
+		return nil, false
+	}
+	return ps.allRefs, true
+}
@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// keepPrimaryResponse returns a copy of the operation that only contains its
+// primary response: the lowest 2xx status code, else the 2XX range, else the
+// default response. An operation with none of them is logged as a warning
+// and returned unchanged. The source operation is left untouched.
+func (oaf *OpenAPISpecFilter) keepPrimaryResponse(
+	op *openapi3.Operation,
+	method, path string,
+) *openapi3.Operation {
+	code, ok := primaryResponseCode(op.Responses)
+	if !ok {
+		oaf.warn(CodeNoSuccessResponse, operationPointer(path, method),
+			"operation has no success response, keeping all responses",
+			slog.String("method", method),
+			slog.String("path", path))
+		return op
+	}
+	if op.Responses.Len() == 1 {
+		return op
+	}
+
+	filteredOp := *op
+	filteredOp.Responses = openapi3.NewResponsesWithCapacity(1)
+	filteredOp.Responses.Extensions = op.Responses.Extensions
+	filteredOp.Responses.Set(code, op.Responses.Value(code))
+	return &filteredOp
+}
+
+// primaryResponseCode returns the status code of the primary response, see
+// keepPrimaryResponse, and whether there is one.
+func primaryResponseCode(responses *openapi3.Responses) (string, bool) {
+	if responses == nil {
+		return "", false
+	}
+	var codes []string
+	for code := range responses.Map() {
+		if len(code) == 3 && code[0] == '2' {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) != 0 {
+		// Exact codes sort before the 2XX range, as digits sort before "X"
+		slices.Sort(codes)
+		return codes[0], true
+	}
+	if responses.Default() != nil {
+		return "default", true
+	}
+	return "", false
+}
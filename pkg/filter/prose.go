@@ -0,0 +1,41 @@
+package filter
+
+import (
+	"reflect"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// stripProse clears the description and summary fields of every object of
+// the filtered spec, as configured by StripDescriptions and StripSummaries.
+// The required description of responses is set to an empty string instead.
+func (oaf *OpenAPISpecFilter) stripProse() {
+	var fields []string
+	if oaf.cfg.StripDescriptions {
+		fields = append(fields, "Description")
+	}
+	if oaf.cfg.StripSummaries {
+		fields = append(fields, "Summary")
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	walk.Walk(oaf.filtered, func(v reflect.Value) bool {
+		if v.Kind() != reflect.Struct || !v.CanAddr() {
+			return true
+		}
+		for _, name := range fields {
+			field := v.FieldByName(name)
+			switch {
+			case !field.IsValid() || !field.CanSet():
+			case field.Kind() == reflect.String:
+				field.SetString("")
+			case field.Type() == reflect.TypeFor[*string]() && !field.IsNil():
+				// Required by the response object
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,100 @@
+package filter
+
+import (
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// indexComposedRequired records, for each schema of the source spec composed
+// with allOf, the properties required anywhere in its composition: by the
+// composing schema or by any of its allOf branches, transitively. A branch
+// used by several compositions gets the properties required by all of them.
+func (oaf *OpenAPISpecFilter) indexComposedRequired() {
+	oaf.composedRequired = nil
+	if !oaf.cfg.OnlyRequiredProperties {
+		return
+	}
+	oaf.composedRequired = make(map[*openapi3.Schema]map[string]struct{})
+	walk.Walk(oaf.doc, func(v reflect.Value) bool {
+		sc, ok := v.Interface().(*openapi3.Schema)
+		if !ok || len(sc.AllOf) == 0 {
+			return true
+		}
+		members := allOfMembers(sc, make(map[*openapi3.Schema]struct{}))
+		required := make(map[string]struct{})
+		for member := range members {
+			for _, name := range member.Required {
+				required[name] = struct{}{}
+			}
+		}
+		for member := range members {
+			if oaf.composedRequired[member] == nil {
+				oaf.composedRequired[member] = make(map[string]struct{})
+			}
+			maps.Copy(oaf.composedRequired[member], required)
+		}
+		return true
+	})
+}
+
+// allOfMembers adds sc and its allOf branches, transitively, to members and
+// returns it.
+func allOfMembers(sc *openapi3.Schema, members map[*openapi3.Schema]struct{}) map[*openapi3.Schema]struct{} {
+	if _, ok := members[sc]; ok {
+		return members
+	}
+	members[sc] = struct{}{}
+	for _, branch := range sc.AllOf {
+		if branch != nil && branch.Value != nil {
+			allOfMembers(branch.Value, members)
+		}
+	}
+	return members
+}
+
+// keepRequiredProperties removes the properties that are not required from
+// every schema reachable from root, when OnlyRequiredProperties is set. Like
+// collapseUnions, it must run before refs of root are collected, so
+// components only used by removed properties are not copied into the
+// filtered spec.
+//
+// Composed schemas are handled conservatively: a schema of an allOf
+// composition keeps the properties required anywhere in it, as a property may
+// be optional in the branch defining it and required by another one. The
+// property named by a discriminator is always kept. anyOf and oneOf branches
+// are independent, each keeps its own required properties.
+func (oaf *OpenAPISpecFilter) keepRequiredProperties(root any) {
+	if !oaf.cfg.OnlyRequiredProperties {
+		return
+	}
+	walk.Walk(root, func(v reflect.Value) bool {
+		if sc, ok := v.Interface().(*openapi3.Schema); ok {
+			oaf.dropOptionalProperties(sc)
+		}
+		return true
+	})
+}
+
+// dropOptionalProperties removes the properties of a single schema that are
+// not required, in place.
+func (oaf *OpenAPISpecFilter) dropOptionalProperties(sc *openapi3.Schema) {
+	for _, name := range slices.Sorted(maps.Keys(sc.Properties)) {
+		if slices.Contains(sc.Required, name) {
+			continue
+		}
+		if _, ok := oaf.composedRequired[sc][name]; ok {
+			continue
+		}
+		if sc.Discriminator != nil && sc.Discriminator.PropertyName == name {
+			continue
+		}
+		oaf.logger.Debug("property dropped: not required", slog.String("property", name))
+		delete(sc.Properties, name)
+	}
+}
@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// filterRules keeps all operations of the spec that are selected by any of the
+// configured selection rules, by their operationId or by their x-openapi-filter
+// settings. Operations are merged
+// into path items already kept by the explicit paths configuration.
+func (oaf *OpenAPISpecFilter) filterRules(ctx context.Context) error {
+	if len(oaf.cfg.Rules) == 0 && len(oaf.cfg.IncludeOperationIds) == 0 && !oaf.cfg.HasOverrideSelection() {
+		return nil
+	}
+
+	for path, pathItem := range oaf.doc.Paths.Map() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if oaf.isSuperseded(path) || oaf.cfg.IsPathExcluded(path) {
+			continue
+		}
+		newPathItem := oaf.filtered.Paths.Value(path)
+		isNew := newPathItem == nil
+		if isNew {
+			newPathItem = &openapi3.PathItem{}
+		}
+
+		var matched bool
+		for method, op := range pathItem.Operations() {
+			if !oaf.matchRules(path, method, op) {
+				continue
+			}
+			if newPathItem.GetOperation(method) != nil {
+				continue // already kept by the explicit paths configuration
+			}
+			op, ok := oaf.prepareOperation(op, method, path, config.PathConfig{})
+			if !ok {
+				continue
+			}
+			oaf.logger.Debug("operation selected by rule",
+				slog.String("method", method),
+				slog.String("path", path),
+				slog.String("operationId", op.OperationID))
+			matched = true
+			newPathItem.SetOperation(method, op)
+			oaf.collectOperation(op)
+		}
+
+		if matched && isNew {
+			oaf.preservePathServers(pathItem, newPathItem, config.PathConfig{})
+			oaf.filtered.Paths.Set(path, newPathItem)
+		}
+	}
+	return nil
+}
+
+// matchRules reports whether the operation is selected by any selection rule,
+// by its operationId or by its x-openapi-filter settings.
+func (oaf *OpenAPISpecFilter) matchRules(
+	path, method string,
+	op *openapi3.Operation,
+) bool {
+	if oaf.cfg.IsOperationIDIncluded(op.OperationID) {
+		return true
+	}
+	if _, ok := oaf.cfg.MatchIncludedOverride(op.Extensions); ok {
+		return true
+	}
+	for _, rule := range oaf.cfg.Rules {
+		if rule.Matches(path, method, op.Tags) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOperationIDs warns about duplicate operationIds in the spec and
+// listed operationIds not found in it, as they make selection ambiguous.
+func (oaf *OpenAPISpecFilter) validateOperationIDs() {
+	for _, problem := range oaf.cfg.ValidateOperationIDs(oaf.doc) {
+		oaf.warn(CodeAmbiguousOperationID, "", "ambiguous selection by operationId", slog.String("reason", problem))
+	}
+}
@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const rulesSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /v1/pets:
+    get:
+      tags: [pets]
+      responses: {'200': {description: Pets}}
+  /v2/pets:
+    get:
+      tags: [pets]
+      responses: {'200': {description: Pets}}
+    post:
+      tags: [pets]
+      responses: {'201': {description: Created}}
+  /v2/store/orders:
+    get:
+      tags: [store]
+      responses: {'200': {description: Orders}}
+    delete:
+      tags: [store, admin]
+      responses: {'204': {description: Deleted}}
+`
+
+// operationKeys returns the kept operations, as "METHOD path", sorted.
+func operationKeys(spec *openapi3.T) []string {
+	var keys []string
+	for path, pathItem := range spec.Paths.Map() {
+		for method := range pathItem.Operations() {
+			keys = append(keys, method+" "+path)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestFilterRules(t *testing.T) {
+	tests := []struct {
+		name, cfg string
+		want      []string
+	}{
+		{
+			name: "GETs under a prefix",
+			cfg:  "rules:\n  - prefix: /v2\n    methods: [get]\n",
+			want: []string{"GET /v2/pets", "GET /v2/store/orders"},
+		},
+		{
+			name: "glob and tag",
+			cfg:  "rules:\n  - glob: /v2/*/*\n    tags: [admin]\n",
+			want: []string{"DELETE /v2/store/orders"},
+		},
+		{
+			name: "union across rules",
+			cfg:  "rules:\n  - prefix: /v1\n  - prefix: /v2\n    methods: [post]\n",
+			want: []string{"GET /v1/pets", "POST /v2/pets"},
+		},
+		{
+			name: "rules add to paths",
+			cfg:  "paths:\n  /v2/pets: [post]\nrules:\n  - tags: [store]\n    methods: [get]\n",
+			want: []string{"GET /v2/store/orders", "POST /v2/pets"},
+		},
+		{
+			name: "excluded paths win",
+			cfg:  "excludePaths: [/v2/store/*]\nrules:\n  - prefix: /v2\n",
+			want: []string{"GET /v2/pets", "POST /v2/pets"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _ := filterTestSpec(t, rulesSpec, tt.cfg)
+			if got := operationKeys(filtered); !slices.Equal(got, tt.want) {
+				t.Errorf("got operations %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// hasRequiredSecurityScheme reports whether the effective security of the
+// operation uses one of the schemes of RequireSecurityScheme. Operations
+// without security, whose requirements use no scheme, match only with
+// IncludeUnsecured. Every operation matches when no scheme is required.
+func (oaf *OpenAPISpecFilter) hasRequiredSecurityScheme(op *openapi3.Operation) bool {
+	required := oaf.cfg.RequireSecurityScheme
+	if len(required) == 0 {
+		return true
+	}
+	schemes := securitySchemes(oaf.effectiveSecurity(op))
+	if len(schemes) == 0 {
+		return oaf.cfg.IncludeUnsecured
+	}
+	for _, scheme := range schemes {
+		if slices.Contains(required, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// effectiveSecurity returns the security requirements of the operation: its
+// own when set, even to an empty list, otherwise the root ones.
+func (oaf *OpenAPISpecFilter) effectiveSecurity(op *openapi3.Operation) openapi3.SecurityRequirements {
+	if op.Security != nil {
+		return *op.Security
+	}
+	return oaf.doc.Security
+}
+
+// securitySchemes returns the names of the schemes used by the requirements.
+func securitySchemes(reqs openapi3.SecurityRequirements) []string {
+	var schemes []string
+	for _, req := range reqs {
+		for scheme := range req {
+			if !slices.Contains(schemes, scheme) {
+				schemes = append(schemes, scheme)
+			}
+		}
+	}
+	return schemes
+}
+
+// pruneSecuritySchemes removes the security scheme components not used by the
+// effective security of any kept operation, nor by the kept root security,
+// when RequireSecurityScheme is set.
+func (oaf *OpenAPISpecFilter) pruneSecuritySchemes() {
+	comps := oaf.filtered.Components
+	if len(oaf.cfg.RequireSecurityScheme) == 0 || len(comps.SecuritySchemes) == 0 {
+		return
+	}
+
+	used := securitySchemes(oaf.filtered.Security)
+	for _, pathItem := range oaf.filtered.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			used = append(used, securitySchemes(oaf.effectiveSecurity(op))...)
+		}
+	}
+	for name := range comps.SecuritySchemes {
+		if !slices.Contains(used, name) {
+			oaf.logger.Debug("security scheme pruned: not used by kept operations",
+				slog.String("name", name))
+			delete(comps.SecuritySchemes, name)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"log/slog"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// filtersServers reports whether server entries are selected by URL patterns
+// or by extension values.
+func (oaf *OpenAPISpecFilter) filtersServers() bool {
+	return oaf.cfg.Servers.IsFiltered() || len(oaf.cfg.ServersByExtension) != 0
+}
+
+// filterServers returns the servers whose URL matches the configured server
+// patterns and whose extensions carry the configured ServersByExtension
+// values. Servers are returned as is when neither is configured, and nil when
+// none is kept.
+func (oaf *OpenAPISpecFilter) filterServers(servers openapi3.Servers) openapi3.Servers {
+	if !oaf.filtersServers() || len(servers) == 0 {
+		return servers
+	}
+
+	kept := make(openapi3.Servers, 0, len(servers))
+	for _, server := range servers {
+		if server == nil || !oaf.cfg.Servers.Matches(server.URL) {
+			oaf.logger.Debug("server not matching servers patterns, removed",
+				slog.String("url", serverURL(server)))
+			continue
+		}
+		if !oaf.cfg.MatchesServerExtensions(server.Extensions) {
+			oaf.logger.Debug("server not matching servers extension values, removed",
+				slog.String("url", server.URL))
+			continue
+		}
+		kept = append(kept, server)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// filterOperationServers returns a copy of the operation that only contains
+// servers matching the configured server patterns and extension values. The
+// source operation is left untouched.
+func (oaf *OpenAPISpecFilter) filterOperationServers(op *openapi3.Operation) *openapi3.Operation {
+	if !oaf.filtersServers() || op.Servers == nil {
+		return op
+	}
+
+	filteredOp := *op
+	filteredOp.Servers = nil
+	if servers := oaf.filterServers(*op.Servers); servers != nil {
+		filteredOp.Servers = &servers
+	}
+	return &filteredOp
+}
+
+func serverURL(server *openapi3.Server) string {
+	if server == nil {
+		return ""
+	}
+	return server.URL
+}
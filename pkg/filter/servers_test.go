@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+)
+
+const serversSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+servers:
+  - url: https://api.example.com
+paths:
+  /pets:
+    servers:
+      - url: https://pets.example.com
+    get:
+      servers:
+        - url: https://read.pets.example.com
+      responses:
+        '200': {description: Pets}
+`
+
+func TestServersPrecedence(t *testing.T) {
+	for _, servers := range []bool{false, true} {
+		for _, preservePathServers := range []bool{false, true} {
+			for _, pathPreserve := range []string{"", "false", "true"} {
+				name := fmt.Sprintf("servers=%v/preservePathServers=%v/preserveServers=%q", servers, preservePathServers, pathPreserve)
+				t.Run(name, func(t *testing.T) {
+					cfg := fmt.Sprintf("servers: %v\npreservePathServers: %v\n", servers, preservePathServers)
+					if pathPreserve == "" {
+						cfg += "paths:\n  /pets: [get]\n"
+					} else {
+						cfg += "paths:\n  /pets:\n    methods: [get]\n    preserveServers: " + pathPreserve + "\n"
+					}
+					wantPathServers := preservePathServers
+					if pathPreserve != "" {
+						wantPathServers = pathPreserve == "true"
+					}
+
+					filtered, _ := filterTestSpec(t, serversSpec, cfg)
+					if got := len(filtered.Servers) == 1; got != servers {
+						t.Errorf("got root servers %v, want kept %v", filtered.Servers, servers)
+					}
+					pathItem := filtered.Paths.Value("/pets")
+					if got := len(pathItem.Servers) == 1; got != wantPathServers {
+						t.Errorf("got path servers %v, want kept %v", pathItem.Servers, wantPathServers)
+					}
+					if op := pathItem.Get; op.Servers == nil || len(*op.Servers) != 1 {
+						t.Errorf("got operation servers %v, want them kept with the operation", op.Servers)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// ErrOperationNotFound is returned by FilterToOperation when the spec has no
+// operation for the given path and method.
+var ErrOperationNotFound = errors.New("operation not found")
+
+// FilterToOperation returns the minimal self-contained spec holding only the
+// operation of spec at path and method, e.g. to generate a client for a
+// single endpoint. The spec keeps the components the operation references,
+// transitively, the security schemes of its effective security, and the
+// servers of the source spec. The method is case-insensitive.
+func FilterToOperation(spec *openapi3.T, path, method string) (*openapi3.T, error) {
+	var op *openapi3.Operation
+	if pathItem := spec.Paths.Value(path); pathItem != nil {
+		op = pathItem.Operations()[strings.ToUpper(method)]
+	}
+	if op == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrOperationNotFound, strings.ToUpper(method), path)
+	}
+
+	// Components are not listed, so only the referenced ones are kept. Security
+	// schemes are not referenced by refs and are listed instead. The root
+	// security is kept only if the operation inherits it.
+	security := spec.Security
+	if op.Security != nil {
+		security = *op.Security
+	}
+	cfg := &config.Config{FilterConfig: config.FilterConfig{
+		Servers:             config.ServersConfig{Enabled: true},
+		PreservePathServers: true,
+		Paths: map[string]config.PathConfig{
+			path: {Methods: []string{strings.ToLower(method)}},
+		},
+		Components: &config.FilterComponentsConfig{
+			SecuritySchemes: securitySchemes(security),
+		},
+		Security: op.Security == nil,
+	}}
+
+	filtered, err := NewOpenAPISpecFilter(cfg, slog.Default()).Filter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("oaf.Filter: %w", err)
+	}
+	return filtered, nil
+}
@@ -0,0 +1,145 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// ErrUnsupportedConversion is returned by Filter when the filtered spec cannot
+// be converted to the configured OutputVersion.
+var ErrUnsupportedConversion = errors.New("unsupported spec version conversion")
+
+// convertVersion sets the openapi field of the filtered spec to the configured
+// OutputVersion, converting its schemas between OpenAPI 3.0 and 3.1:
+//   - 3.0 to 3.1: `nullable: true` becomes a "null" type, added to the enum
+//     if any. Without a type, nullable has no effect and is dropped;
+//   - 3.1 to 3.0: a "null" type becomes `nullable: true`.
+//
+// Schemas that cannot be converted mechanically, such as exclusive bounds or
+// several non-null types, fail the conversion with ErrUnsupportedConversion.
+func (oaf *OpenAPISpecFilter) convertVersion() error {
+	version, err := oaf.cfg.OpenAPIVersion()
+	if err != nil {
+		return fmt.Errorf("oaf.cfg.OpenAPIVersion: %w", err)
+	}
+	if version == "" {
+		return nil
+	}
+	from, to := minorVersion(oaf.doc.OpenAPI), minorVersion(version)
+	if from != "3.0" && from != "3.1" {
+		return fmt.Errorf("%w: from OpenAPI %s", ErrUnsupportedConversion, oaf.doc.OpenAPI)
+	}
+	oaf.filtered.OpenAPI = version
+	if from == to {
+		return nil
+	}
+
+	convert := upgradeSchema
+	if to == "3.0" {
+		convert = downgradeSchema
+	}
+	c := &schemaConverter{convert: convert, seen: make(map[*openapi3.Schema]struct{})}
+	if comps := oaf.filtered.Components; comps != nil {
+		for _, name := range slices.Sorted(maps.Keys(comps.Schemas)) {
+			c.walk("components.schemas."+name, comps.Schemas[name])
+		}
+	}
+	paths := oaf.filtered.Paths.Map()
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		ops := paths[path].Operations()
+		for _, method := range slices.Sorted(maps.Keys(ops)) {
+			c.walk("paths."+path+"."+strings.ToLower(method), ops[method])
+		}
+	}
+	c.walk("spec", oaf.filtered)
+	if len(c.errs) != 0 {
+		return fmt.Errorf("%w: from OpenAPI %s to %s: %w", ErrUnsupportedConversion,
+			oaf.doc.OpenAPI, version, errors.Join(c.errs...))
+	}
+	return nil
+}
+
+// schemaConverter converts every schema reachable from the walked values once,
+// reporting errors at the first location a schema is found.
+type schemaConverter struct {
+	convert func(sc *openapi3.Schema) error
+	seen    map[*openapi3.Schema]struct{}
+	errs    []error
+}
+
+func (c *schemaConverter) walk(location string, root any) {
+	walk.Walk(root, func(v reflect.Value) bool {
+		sc, ok := v.Interface().(*openapi3.Schema)
+		if !ok {
+			return true
+		}
+		if _, ok := c.seen[sc]; ok {
+			return false
+		}
+		c.seen[sc] = struct{}{}
+		if err := c.convert(sc); err != nil {
+			c.errs = append(c.errs, fmt.Errorf("%s: %w", location, err))
+		}
+		return true
+	})
+}
+
+// upgradeSchema converts a 3.0 schema to 3.1.
+func upgradeSchema(sc *openapi3.Schema) error {
+	if sc.ExclusiveMin || sc.ExclusiveMax {
+		return errors.New("boolean exclusiveMinimum and exclusiveMaximum cannot be converted")
+	}
+	if !sc.Nullable {
+		return nil
+	}
+	sc.Nullable = false
+	if len(sc.Type.Slice()) == 0 {
+		return nil
+	}
+	if !sc.Type.Includes(openapi3.TypeNull) {
+		types := append(slices.Clone(sc.Type.Slice()), openapi3.TypeNull)
+		sc.Type = (*openapi3.Types)(&types)
+	}
+	if len(sc.Enum) != 0 && !slices.Contains(sc.Enum, nil) {
+		sc.Enum = append(slices.Clone(sc.Enum), nil)
+	}
+	return nil
+}
+
+// downgradeSchema converts a 3.1 schema to 3.0.
+func downgradeSchema(sc *openapi3.Schema) error {
+	types := sc.Type.Slice()
+	if !slices.Contains(types, openapi3.TypeNull) {
+		if len(types) > 1 {
+			return fmt.Errorf("several types %v cannot be converted", types)
+		}
+		return nil
+	}
+	types = slices.DeleteFunc(slices.Clone(types), func(typ string) bool {
+		return typ == openapi3.TypeNull
+	})
+	if len(types) != 1 {
+		return fmt.Errorf("types %v cannot be converted to a single nullable type", sc.Type.Slice())
+	}
+	sc.Type = (*openapi3.Types)(&types)
+	sc.Nullable = true
+	return nil
+}
+
+// minorVersion returns the major and minor parts of an OpenAPI version,
+// e.g. "3.0" for "3.0.4".
+func minorVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
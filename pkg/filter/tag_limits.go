@@ -0,0 +1,50 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrTagOperationLimit is returned by Filter when a tag has more kept
+// operations than its MaxOperationsPerTag limit. The error lists the tags.
+var ErrTagOperationLimit = errors.New("tags exceed their operation limit")
+
+// checkTagOperationLimits counts the operations of the filtered spec per tag
+// and reports an error for each tag over its MaxOperationsPerTag limit,
+// returning ErrTagOperationLimit if any. An operation listing a tag twice is
+// counted once, and callback operations are not counted.
+func (oaf *OpenAPISpecFilter) checkTagOperationLimits() error {
+	if len(oaf.cfg.MaxOperationsPerTag) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, path := range oaf.filtered.Paths.InMatchingOrder() {
+		for _, op := range oaf.filtered.Paths.Value(path).Operations() {
+			for _, tag := range slices.Compact(slices.Sorted(slices.Values(op.Tags))) {
+				counts[tag]++
+			}
+		}
+	}
+
+	var exceeded []string
+	for _, tag := range slices.Sorted(maps.Keys(counts)) {
+		limit, ok := oaf.cfg.TagOperationLimit(tag)
+		if !ok || counts[tag] <= limit {
+			continue
+		}
+		oaf.report(SeverityError, CodeTagOperationLimit, "", "tag has more kept operations than its limit",
+			slog.String("tag", tag),
+			slog.Int("operations", counts[tag]),
+			slog.Int("limit", limit))
+		exceeded = append(exceeded, fmt.Sprintf("%s (%d > %d)", tag, counts[tag], limit))
+	}
+	if len(exceeded) != 0 {
+		return fmt.Errorf("%w: %s", ErrTagOperationLimit, strings.Join(exceeded, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"log/slog"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+)
+
+// filterTags returns the root tag definitions kept in the filtered spec:
+// those listed in the tags config, if any, and with PruneTags, only those
+// the kept operations reference. A listed tag missing from the spec is
+// logged as a warning.
+func (oaf *OpenAPISpecFilter) filterTags(tags openapi3.Tags) openapi3.Tags {
+	tc := oaf.cfg.Tags
+	for _, name := range tc.Names {
+		if tags.Get(name) == nil {
+			oaf.warn(CodeTagNotFound, "", "tag not found in spec", slog.String("name", name))
+		}
+	}
+
+	var used map[string]struct{}
+	if oaf.cfg.PruneTags {
+		used = oaf.keptOperationTags()
+	}
+	var kept openapi3.Tags
+	for _, tag := range tags {
+		if tag == nil || !tc.Keeps(tag.Name) {
+			continue
+		}
+		if _, ok := used[tag.Name]; used != nil && !ok {
+			oaf.logger.Debug("tag not referenced by kept operations, dropped",
+				slog.String("name", tag.Name))
+			continue
+		}
+		kept = append(kept, tag)
+	}
+	return kept
+}
+
+// keptOperationTags returns the tags of the operations of the filtered spec,
+// including callback operations.
+func (oaf *OpenAPISpecFilter) keptOperationTags() map[string]struct{} {
+	tags := make(map[string]struct{})
+	walk.Walk(oaf.filtered.Paths, func(v reflect.Value) bool {
+		if op, ok := v.Interface().(*openapi3.Operation); ok {
+			for _, tag := range op.Tags {
+				tags[tag] = struct{}{}
+			}
+		}
+		return true
+	})
+	return tags
+}
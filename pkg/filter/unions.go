@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"log/slog"
+	"reflect"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/internal/walk"
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// collapseUnions replaces every anyOf/oneOf reachable from a kept operation or
+// component with a single inlined branch chosen by the configured strategy.
+// It must run before refs of root are collected, so components used only by
+// dropped branches are not copied into the filtered spec.
+func (oaf *OpenAPISpecFilter) collapseUnions(root any) {
+	strategy := oaf.cfg.CollapseUnions
+	if strategy == "" {
+		return
+	}
+	walk.Walk(root, func(v reflect.Value) bool {
+		if sc, ok := v.Interface().(*openapi3.Schema); ok {
+			oaf.collapseSchema(sc, strategy)
+		}
+		return true
+	})
+}
+
+// collapseSchema collapses the unions of a single schema in place.
+func (oaf *OpenAPISpecFilter) collapseSchema(
+	sc *openapi3.Schema,
+	strategy config.CollapseStrategy,
+) {
+	for _, union := range []struct {
+		kind     string
+		branches *openapi3.SchemaRefs
+	}{
+		{"oneOf", &sc.OneOf},
+		{"anyOf", &sc.AnyOf},
+	} {
+		branches := *union.branches
+		if len(branches) == 0 {
+			continue
+		}
+		idx := 0
+		if strategy == config.CollapseUnionsByDiscriminator {
+			idx = discriminatedBranch(sc, branches)
+		}
+		branch := branches[idx]
+		*union.branches = nil
+		sc.Discriminator = nil // no longer applies without the union
+
+		if len(branches) > 1 {
+			oaf.warn(CodeLossyUnionCollapse, "", "lossy transform: union collapsed to a single branch",
+				slog.String("kind", union.kind),
+				slog.Int("branch", idx),
+				slog.String("ref", branch.Ref),
+				slog.Int("dropped", len(branches)-1))
+		}
+		inlineBranch(sc, branch)
+	}
+}
+
+// discriminatedBranch returns the index of the branch targeted by the first
+// discriminator mapping in key order, or 0 if there is none.
+func discriminatedBranch(sc *openapi3.Schema, branches openapi3.SchemaRefs) int {
+	if sc.Discriminator == nil || len(sc.Discriminator.Mapping) == 0 {
+		return 0
+	}
+	keys := make([]string, 0, len(sc.Discriminator.Mapping))
+	for key := range sc.Discriminator.Mapping {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	target := sc.Discriminator.Mapping[keys[0]]
+	for i, branch := range branches {
+		if branch.Ref == target {
+			return i
+		}
+	}
+	return 0
+}
+
+// inlineBranch merges the chosen union branch into the schema. A schema that
+// only consisted of the union is replaced by the branch, keeping its own
+// title and description; otherwise the branch is added to allOf.
+func inlineBranch(sc *openapi3.Schema, branch *openapi3.SchemaRef) {
+	if branch.Value == nil {
+		sc.AllOf = append(sc.AllOf, branch)
+		return
+	}
+	if !isUnionOnly(sc) {
+		sc.AllOf = append(sc.AllOf, &openapi3.SchemaRef{Value: branch.Value})
+		return
+	}
+
+	title, description := sc.Title, sc.Description
+	*sc = *branch.Value
+	if title != "" {
+		sc.Title = title
+	}
+	if description != "" {
+		sc.Description = description
+	}
+}
+
+// isUnionOnly reports whether the schema carries no constraints of its own
+// apart from annotations, once its unions are removed.
+func isUnionOnly(sc *openapi3.Schema) bool {
+	return (sc.Type == nil || len(*sc.Type) == 0) &&
+		len(sc.Properties) == 0 &&
+		len(sc.AllOf) == 0 && len(sc.OneOf) == 0 && len(sc.AnyOf) == 0 &&
+		sc.Not == nil && sc.Items == nil &&
+		len(sc.Enum) == 0
+}
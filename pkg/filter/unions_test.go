@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+const unionsSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pet:
+    get:
+      responses:
+        '200':
+          description: Pet
+          content:
+            application/json:
+              schema:
+                description: A cat or a dog
+                oneOf:
+                  - $ref: '#/components/schemas/Cat'
+                  - $ref: '#/components/schemas/Dog'
+                discriminator:
+                  propertyName: kind
+                  mapping:
+                    dog: '#/components/schemas/Dog'
+  /owner:
+    get:
+      responses:
+        '200':
+          description: Owner
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name: {type: string}
+                anyOf:
+                  - required: [name]
+                  - $ref: '#/components/schemas/Dog'
+components:
+  schemas:
+    Cat:
+      type: object
+      properties:
+        kind: {type: string}
+        purrs: {type: boolean}
+    Dog:
+      type: object
+      properties:
+        kind: {type: string}
+        barks: {type: boolean}
+`
+
+func TestCollapseUnions(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          string
+		wantProperty string   // Property of the collapsed /pet schema
+		wantSchemas  []string // Components kept
+		wantWarnings int
+	}{
+		{"disabled", "", "", []string{"Cat", "Dog"}, 0},
+		{"first", "collapseUnions: first\n", "purrs", []string{}, 2},
+		{"byDiscriminator", "collapseUnions: byDiscriminator\n", "barks", []string{}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, diagnostics := filterTestSpec(t, unionsSpec, "paths:\n  /pet: [get]\n  /owner: [get]\ncomponents: {}\n"+tt.cfg)
+
+			pet := filtered.Paths.Value("/pet").Get.Responses.Status(200).Value.
+				Content.Get("application/json").Schema.Value
+			if tt.wantProperty == "" {
+				if len(pet.OneOf) != 2 {
+					t.Errorf("got oneOf %v, want the union kept", pet.OneOf)
+				}
+			} else {
+				if len(pet.OneOf) != 0 || pet.Discriminator != nil {
+					t.Errorf("got oneOf %v and discriminator %v, want the union collapsed", pet.OneOf, pet.Discriminator)
+				}
+				if pet.Properties[tt.wantProperty] == nil || pet.Description != "A cat or a dog" {
+					t.Errorf("got properties %v and description %q, want the %s branch inlined",
+						slices.Sorted(maps.Keys(pet.Properties)), pet.Description, tt.wantProperty)
+				}
+
+				owner := filtered.Paths.Value("/owner").Get.Responses.Status(200).Value.
+					Content.Get("application/json").Schema.Value
+				if len(owner.AnyOf) != 0 || len(owner.AllOf) != 1 || owner.Properties["name"] == nil {
+					t.Errorf("got anyOf %v, allOf %v, want the first branch added to allOf", owner.AnyOf, owner.AllOf)
+				}
+			}
+
+			got := []string{}
+			if filtered.Components != nil {
+				got = slices.Sorted(maps.Keys(filtered.Components.Schemas))
+			}
+			if !slices.Equal(got, tt.wantSchemas) {
+				t.Errorf("got schemas %v, want %v", got, tt.wantSchemas)
+			}
+			var warnings int
+			for _, diag := range diagnostics {
+				if diag.Code == CodeLossyUnionCollapse {
+					warnings++
+				}
+			}
+			if warnings != tt.wantWarnings {
+				t.Errorf("got %d lossy collapse warnings, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
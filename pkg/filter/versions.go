@@ -0,0 +1,90 @@
+package filter
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// findSupersededPaths collects the selected paths of the spec for which the
+// same resource is selected under a higher version, when LatestVersionOnly is
+// set. Paths differ only by the version segment matched by the version
+// pattern, whose first capture group is the version number. Unversioned paths
+// are never superseded, and neither are paths whose newer versions are not
+// selected, see selectsPath.
+func (oaf *OpenAPISpecFilter) findSupersededPaths() error {
+	if !oaf.cfg.LatestVersionOnly {
+		return nil
+	}
+
+	re, err := oaf.cfg.VersionRegexp()
+	if err != nil {
+		return fmt.Errorf("oaf.cfg.VersionRegexp: %w", err)
+	}
+
+	type versionedPath struct {
+		path, resource string
+		version        int
+	}
+	var versioned []versionedPath
+	latest := make(map[string]versionedPath)
+	for _, path := range oaf.doc.Paths.InMatchingOrder() {
+		if !oaf.selectsPath(path) {
+			continue
+		}
+		loc := re.FindStringSubmatchIndex(path)
+		if loc == nil || loc[2] < 0 {
+			continue
+		}
+		version, err := strconv.Atoi(path[loc[2]:loc[3]])
+		if err != nil {
+			continue
+		}
+
+		vp := versionedPath{
+			path:     path,
+			resource: path[:loc[0]] + "\x00" + path[loc[1]:],
+			version:  version,
+		}
+		versioned = append(versioned, vp)
+		if cur, ok := latest[vp.resource]; !ok || vp.version > cur.version {
+			latest[vp.resource] = vp
+		}
+	}
+
+	oaf.superseded = make(map[string]struct{})
+	for _, vp := range versioned {
+		if newest := latest[vp.resource]; newest.path != vp.path {
+			oaf.superseded[vp.path] = struct{}{}
+			oaf.logger.Debug("path superseded by a newer version",
+				slog.String("path", vp.path),
+				slog.String("latest", newest.path))
+		}
+	}
+	return nil
+}
+
+// selectsPath reports whether the config selects operations of the path, by
+// the paths configuration or by selection rules, operationIds and
+// x-openapi-filter settings.
+func (oaf *OpenAPISpecFilter) selectsPath(path string) bool {
+	if _, ok := oaf.cfg.Paths[path]; ok {
+		return true
+	}
+	if oaf.cfg.IsPathExcluded(path) {
+		return false
+	}
+	for method, op := range oaf.doc.Paths.Value(path).Operations() {
+		if oaf.matchRules(path, method, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSuperseded reports whether the path is dropped in favor of a newer version
+// of the same resource.
+func (oaf *OpenAPISpecFilter) isSuperseded(path string) bool {
+	_, ok := oaf.superseded[path]
+	return ok
+}
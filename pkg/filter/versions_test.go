@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"slices"
+	"testing"
+)
+
+const versionsSpec = `
+openapi: 3.0.3
+info: {title: Users, version: 1.0.0}
+paths:
+  /v1/users:
+    get:
+      operationId: listUsersV1
+      tags: [users]
+      responses:
+        '200': {description: Users}
+  /v2/users:
+    get:
+      operationId: listUsersV2
+      tags: [users]
+      responses:
+        '200': {description: Users}
+  /v1/orders:
+    get:
+      operationId: listOrdersV1
+      responses:
+        '200': {description: Orders}
+  /health:
+    get:
+      responses:
+        '200': {description: Healthy}
+`
+
+func TestLatestVersionOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		want []string
+	}{
+		{
+			name: "highest version kept",
+			cfg:  "paths: {\"/v*/users\": [get], /health: [get]}\nlatestVersionOnly: true\n",
+			want: []string{"/health", "/v2/users"},
+		},
+		{
+			name: "unselected newer version",
+			cfg:  "paths: {/v1/users: [get]}\nlatestVersionOnly: true\n",
+			want: []string{"/v1/users"},
+		},
+		{
+			name: "newer version excluded",
+			cfg:  "paths: {\"/v*/users\": [get]}\nexcludePaths: [/v2/users]\nlatestVersionOnly: true\n",
+			want: []string{"/v1/users"},
+		},
+		{
+			name: "selected by rule",
+			cfg:  "paths: {}\nrules: [{tags: [users]}]\nlatestVersionOnly: true\n",
+			want: []string{"/v2/users"},
+		},
+		{
+			name: "disabled",
+			cfg:  "paths: {\"/v*/users\": [get]}\n",
+			want: []string{"/v1/users", "/v2/users"},
+		},
+		{
+			name: "custom pattern",
+			cfg:  "paths: {\"/v*/users\": [get]}\nlatestVersionOnly: true\nversionPattern: \"^/v(1)/\"\n",
+			want: []string{"/v1/users", "/v2/users"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _ := filterTestSpec(t, versionsSpec, tt.cfg)
+			got := filtered.Paths.InMatchingOrder()
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got paths %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
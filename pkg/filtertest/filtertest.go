@@ -0,0 +1,140 @@
+// Package filtertest provides golden file helpers for testing filter configs:
+// a spec and a config are run through the same pipeline as the CLI, and the
+// output is compared to an expected file.
+//
+// Golden files are rewritten with the actual output when the tests run with
+// the -update-golden flag:
+//
+//	go test ./... -update-golden
+package filtertest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+	"github.com/zguydev/openapi-filter/pkg/filter"
+	"github.com/zguydev/openapi-filter/pkg/loader"
+	"github.com/zguydev/openapi-filter/pkg/writer"
+)
+
+var update = flag.Bool("update-golden", false, "Rewrite golden files with the actual filter output")
+
+// RunFilterFixture filters the spec at specPath with the config at
+// configPath, and fails the test if the output differs from the golden file
+// at goldenPath. An empty configPath uses the config embedded in the spec.
+// The output is written like the CLI does, keeping the source layout, and
+// compared with line endings normalized.
+func RunFilterFixture(t testing.TB, specPath, configPath, goldenPath string) {
+	t.Helper()
+
+	got, err := FilterFile(specPath, configPath)
+	if err != nil {
+		t.Fatalf("filter %s: %v", specPath, err)
+	}
+
+	if *update {
+		if err := os.WriteFile(goldenPath, got, 0o644); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update-golden to create it): %v", err)
+	}
+	if !bytes.Equal(normalize(got), normalize(want)) {
+		t.Errorf("filtered %s differs from %s (run with -update-golden to update it):\n%s",
+			specPath, goldenPath, firstDifference(normalize(got), normalize(want)))
+	}
+}
+
+// FilterFile filters the spec at specPath with the config at configPath and
+// returns the YAML output. An empty configPath uses the config embedded in
+// the spec. Logs are discarded.
+func FilterFile(specPath, configPath string) ([]byte, error) {
+	return FilterSource(nil, specPath, configPath)
+}
+
+// FilterSource is FilterFile, loading the spec from source with specLoader,
+// e.g. from memory. A nil specLoader is the [loader.FileLoader] configured by
+// the config. The output keeps the source layout only if source is a file.
+func FilterSource(specLoader loader.Loader, source, configPath string) ([]byte, error) {
+	cfg := &config.Config{}
+	if configPath != "" {
+		var err error
+		if cfg, err = config.LoadConfig(configPath); err != nil {
+			return nil, fmt.Errorf("config.LoadConfig: %w", err)
+		}
+	}
+
+	if specLoader == nil {
+		specLoader = loader.NewFileLoader(cfg.Tool.Loader)
+	}
+	spec, err := specLoader.Load(context.Background(), source)
+	if err != nil {
+		return nil, fmt.Errorf("specLoader.Load: %w", err)
+	}
+	if config.HasEmbeddedConfig(spec) {
+		if cfg, err = config.LoadLayeredConfig(spec, configPath); err != nil {
+			return nil, fmt.Errorf("config.LoadLayeredConfig: %w", err)
+		}
+	} else if configPath == "" {
+		return nil, fmt.Errorf("no config path and no config embedded in %s", source)
+	}
+	if cfg.IsMultiOutput() {
+		return nil, fmt.Errorf("multi-output configs are not supported, filter each output separately")
+	}
+	if err := cfg.Validate(spec); err != nil {
+		return nil, fmt.Errorf("cfg.Validate: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	filtered, err := filter.NewOpenAPISpecFilter(cfg, logger).Filter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("oaf.Filter: %w", err)
+	}
+
+	var writeOpts writer.Options
+	if info, err := os.Stat(source); err == nil && info.Mode().IsRegular() {
+		writeOpts.SourcePath = source
+	}
+	out, err := writer.MarshalSpec(filtered, writer.FormatYAML, writeOpts)
+	if err != nil {
+		return nil, fmt.Errorf("writer.MarshalSpec: %w", err)
+	}
+	return out, nil
+}
+
+// normalize converts CRLF line endings and drops trailing newlines, so golden
+// files edited on any platform compare equal.
+func normalize(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	return bytes.TrimRight(data, "\n")
+}
+
+// firstDifference describes the first line where got and want differ.
+func firstDifference(got, want []byte) string {
+	gotLines := bytes.Split(got, []byte("\n"))
+	wantLines := bytes.Split(want, []byte("\n"))
+	for i := range max(len(gotLines), len(wantLines)) {
+		var g, w []byte
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if !bytes.Equal(g, w) {
+			return fmt.Sprintf("line %d:\n  got:  %q\n  want: %q", i+1, g, w)
+		}
+	}
+	return "no line differs"
+}
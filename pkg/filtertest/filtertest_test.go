@@ -0,0 +1,94 @@
+package filtertest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/pkg/loader"
+)
+
+func TestRunFilterFixture(t *testing.T) {
+	RunFilterFixture(t, "testdata/openapi.yaml", "testdata/.openapi-filter.yaml", "testdata/filtered.openapi.yaml")
+}
+
+func TestRunFilterFixtureEmbeddedConfig(t *testing.T) {
+	RunFilterFixture(t, "testdata/embedded.openapi.yaml", "", "testdata/filtered.openapi.yaml")
+}
+
+// recordingTB records the failures of a test instead of failing it.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *recordingTB) Fatalf(format string, args ...any) {
+	tb.Errorf(format, args...)
+}
+
+func TestRunFilterFixtureReportsDifference(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	RunFilterFixture(tb, "testdata/openapi.yaml", "testdata/.openapi-filter.yaml", "testdata/embedded.openapi.yaml")
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "line 2:") {
+		t.Errorf("got failures %q, want one reporting line 2", tb.errors)
+	}
+}
+
+func TestFilterSourceFromMemory(t *testing.T) {
+	want, err := FilterFile("testdata/openapi.yaml", "testdata/.openapi-filter.yaml")
+	if err != nil {
+		t.Fatalf("FilterFile: %v", err)
+	}
+	fromMemory := loader.LoaderFunc(func(_ context.Context, source string) (*openapi3.T, error) {
+		return openapi3.NewLoader().LoadFromFile("testdata/" + source)
+	})
+	got, err := FilterSource(fromMemory, "openapi.yaml", "testdata/.openapi-filter.yaml")
+	if err != nil {
+		t.Fatalf("FilterSource: %v", err)
+	}
+	// Without a source file, the layout and comments of the source are lost
+	if strings.Contains(string(got), "# Public") {
+		t.Errorf("got:\n%s\nwant no source comment", got)
+	}
+	if gotSpec, wantSpec := loadJSON(t, got), loadJSON(t, want); gotSpec != wantSpec {
+		t.Errorf("got:\n%s\nwant the spec of FilterFile:\n%s", got, want)
+	}
+}
+
+func TestFilterFileErrors(t *testing.T) {
+	tests := []struct {
+		name, specPath, configPath, want string
+	}{
+		{"no config", "testdata/openapi.yaml", "", "no config path"},
+		{"multi-output", "testdata/openapi.yaml", "testdata/multi.openapi-filter.yaml", "multi-output"},
+		{"missing spec", "testdata/missing.yaml", "testdata/.openapi-filter.yaml", "specLoader.Load"},
+	}
+	for _, tt := range tests {
+		if _, err := FilterFile(tt.specPath, tt.configPath); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+// loadJSON returns the JSON encoding of the YAML spec data.
+func loadJSON(t *testing.T, data []byte) string {
+	t.Helper()
+	spec, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		t.Fatalf("LoadFromData: %v", err)
+	}
+	encoded, err := spec.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,172 @@
+package loader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// diskCache caches remote ref documents on disk across runs, keyed by URL.
+// A cached document younger than the TTL is used as is. Older documents are
+// revalidated with a conditional request using their ETag and Last-Modified
+// validators, and refetched if they changed.
+type diskCache struct {
+	dir    string
+	ttl    time.Duration
+	client *http.Client
+}
+
+// cacheEntry is the metadata stored next to a cached document.
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+}
+
+func newDiskCache(dir string, ttl time.Duration, client *http.Client) *diskCache {
+	return &diskCache{dir: dir, ttl: ttl, client: client}
+}
+
+// ReadFromHTTP is an [openapi3.ReadFromURIFunc] reading remote documents
+// through the cache.
+func (c *diskCache) ReadFromHTTP(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+	if location.Scheme == "" || location.Host == "" {
+		return nil, openapi3.ErrURINotSupported
+	}
+
+	key := cacheKey(location)
+	entry, data, err := c.load(key)
+	if err == nil && time.Since(entry.FetchedAt) < c.ttl {
+		return data, nil
+	}
+	if err != nil {
+		entry = nil // Missing or unreadable entries are refetched
+	}
+
+	req, err := http.NewRequestWithContext(loaderContext(loader), http.MethodGet, location.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		entry.FetchedAt = time.Now()
+		if err := c.storeEntry(key, entry); err != nil {
+			return nil, fmt.Errorf("c.storeEntry: %w", err)
+		}
+		return data, nil
+	case resp.StatusCode > 399:
+		return nil, &statusError{location: location, code: resp.StatusCode}
+	}
+
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll: %w", err)
+	}
+	entry = &cacheEntry{
+		URL:          location.String(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	}
+	if err := c.store(key, entry, data); err != nil {
+		return nil, fmt.Errorf("c.store: %w", err)
+	}
+	return data, nil
+}
+
+// cacheKey returns the file name of the cached document at location.
+func cacheKey(location *url.URL) string {
+	sum := sha256.Sum256([]byte(location.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *diskCache) load(key string) (*cacheEntry, []byte, error) {
+	raw, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		return nil, nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	return &entry, data, nil
+}
+
+// store writes the document before its metadata, so that an entry is never
+// visible without its document.
+func (c *diskCache) store(key string, entry *cacheEntry, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(c.dir, key), data); err != nil {
+		return fmt.Errorf("writeFileAtomic: %w", err)
+	}
+	return c.storeEntry(key, entry)
+}
+
+func (c *diskCache) storeEntry(key string, entry *cacheEntry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(c.dir, key+".json"), raw); err != nil {
+		return fmt.Errorf("writeFileAtomic: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file renamed to name, so that
+// concurrent runs sharing the cache never read a partial file.
+func writeFileAtomic(name string, data []byte) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("tmp.Write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("tmp.Close: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("os.Chmod: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync/atomic"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ErrSpecTooLarge is returned when loading a spec reads more than
+// LoaderConfig.MaxSpecBytes.
+var ErrSpecTooLarge = errors.New("spec exceeds max size")
+
+// sizeLimiter fails the reads of a loader once the documents read, the spec
+// and its external ref documents, exceed a total size. Local files too large
+// are rejected before they are read, other documents once read.
+type sizeLimiter struct {
+	read     openapi3.ReadFromURIFunc
+	maxBytes int64
+	total    atomic.Int64 // Safe for the concurrent reads of the prefetcher
+}
+
+func newSizeLimiter(read openapi3.ReadFromURIFunc, maxBytes int64) *sizeLimiter {
+	return &sizeLimiter{read: read, maxBytes: maxBytes}
+}
+
+// ReadFromURI is an [openapi3.ReadFromURIFunc].
+func (sl *sizeLimiter) ReadFromURI(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+	if location.Scheme == "" || location.Scheme == "file" {
+		if info, err := os.Stat(location.Path); err == nil && sl.total.Load()+info.Size() > sl.maxBytes {
+			return nil, fmt.Errorf("%w: %s is %d bytes, max %d", ErrSpecTooLarge, location, info.Size(), sl.maxBytes)
+		}
+	}
+	data, err := sl.read(loader, location)
+	if err != nil {
+		return nil, err
+	}
+	if total := sl.total.Add(int64(len(data))); total > sl.maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes read up to %s, max %d", ErrSpecTooLarge, total, location, sl.maxBytes)
+	}
+	return data, nil
+}
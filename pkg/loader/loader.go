@@ -1,17 +1,119 @@
 package loader
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
 	"github.com/getkin/kin-openapi/openapi3"
 
 	"github.com/zguydev/openapi-filter/pkg/config"
 )
 
+// ErrRemoteRefNotAllowed is returned when a spec references a remote document
+// and LoaderConfig.IsExternalRefsAllowed is not set.
+var ErrRemoteRefNotAllowed = errors.New("remote ref not allowed, set external_refs_allowed")
+
 func NewLoader(cfg *config.LoaderConfig) *openapi3.Loader {
+	return NewLoaderContext(context.Background(), cfg)
+}
+
+// NewLoaderContext returns a loader whose external ref fetches are canceled
+// when ctx is done.
+func NewLoaderContext(ctx context.Context, cfg *config.LoaderConfig) *openapi3.Loader {
+	return NewLoaderWithLogger(ctx, cfg, slog.Default())
+}
+
+// NewLoaderWithLogger is like NewLoaderContext, logging the retries of
+// external ref fetches to logger. Refs to local files are always followed,
+// refs to remote documents only if cfg allows external refs.
+func NewLoaderWithLogger(ctx context.Context, cfg *config.LoaderConfig, logger *slog.Logger) *openapi3.Loader {
 	loader := openapi3.NewLoader()
+	loader.Context = ctx
+	// Remote documents are rejected by the reader, kin-openapi gating all
+	// documents other than the spec
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = readLocalFile
 	if cfg == nil {
 		return loader
 	}
 
-	loader.IsExternalRefsAllowed = cfg.IsExternalRefsAllowed
+	if cfg.IsExternalRefsAllowed {
+		client := &http.Client{Timeout: cfg.HTTPTimeoutOrDefault()}
+		readFromHTTP := readFromHTTP(client)
+		if cfg.CacheDir != "" {
+			readFromHTTP = newDiskCache(cfg.CacheDir, cfg.CacheTTL, client).ReadFromHTTP
+		}
+		if cfg.FetchRetries > 0 {
+			readFromHTTP = newRetrier(readFromHTTP, cfg.FetchRetries, cfg.FetchRetryBackoff, logger).ReadFromURI
+		}
+		readFromHTTP = allowedHostsOnly(readFromHTTP, cfg)
+		read := openapi3.ReadFromURIs(readFromHTTP, openapi3.ReadFromFile)
+		if cfg.MaxSpecBytes > 0 {
+			read = newSizeLimiter(read, cfg.MaxSpecBytes).ReadFromURI
+		}
+		loader.ReadFromURIFunc = newPrefetcher(read, cfg.MaxConcurrentFetches).ReadFromURI
+	} else if cfg.MaxSpecBytes > 0 {
+		loader.ReadFromURIFunc = newSizeLimiter(readLocalFile, cfg.MaxSpecBytes).ReadFromURI
+	}
 	return loader
 }
+
+// readLocalFile is [openapi3.ReadFromFile], failing the reads of remote
+// documents with ErrRemoteRefNotAllowed.
+func readLocalFile(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+	if location.Host != "" || (location.Scheme != "" && location.Scheme != "file") {
+		return nil, fmt.Errorf("%w: %s", ErrRemoteRefNotAllowed, location)
+	}
+	return openapi3.ReadFromFile(loader, location)
+}
+
+// allowedHostsOnly wraps read, failing the reads of remote documents on the
+// hosts cfg does not allow with config.ErrHostNotAllowed.
+func allowedHostsOnly(read openapi3.ReadFromURIFunc, cfg *config.LoaderConfig) openapi3.ReadFromURIFunc {
+	return func(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if location.Host != "" && !cfg.IsHostAllowed(location.Hostname()) {
+			return nil, fmt.Errorf("%w: %s", config.ErrHostNotAllowed, location.Host)
+		}
+		return read(loader, location)
+	}
+}
+
+// readFromHTTP is [openapi3.ReadFromHTTP], with requests bound to the
+// context of the loader.
+func readFromHTTP(client *http.Client) openapi3.ReadFromURIFunc {
+	return func(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if location.Scheme == "" || location.Host == "" {
+			return nil, openapi3.ErrURINotSupported
+		}
+		req, err := http.NewRequestWithContext(loaderContext(loader), http.MethodGet, location.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("client.Do: %w", err)
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		if resp.StatusCode > 399 {
+			return nil, &statusError{location: location, code: resp.StatusCode}
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("io.ReadAll: %w", err)
+		}
+		return data, nil
+	}
+}
+
+// loaderContext returns the context of the loader, which may be unset.
+func loaderContext(loader *openapi3.Loader) context.Context {
+	if loader.Context == nil {
+		return context.Background()
+	}
+	return loader.Context
+}
@@ -0,0 +1,180 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultMaxConcurrentFetches is the number of external ref documents fetched
+// concurrently when LoaderConfig.MaxConcurrentFetches is unset.
+const DefaultMaxConcurrentFetches = 8
+
+// prefetcher reads the external ref documents of a spec concurrently, ahead of
+// the loader, which resolves refs one document at a time. Documents are
+// cached by URL, so each one is read once; the loader then reads them from the
+// cache.
+type prefetcher struct {
+	read openapi3.ReadFromURIFunc
+	sem  chan struct{} // Bounds the concurrent reads
+
+	mu   sync.Mutex
+	docs map[string]*fetchedDoc
+}
+
+type fetchedDoc struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+func newPrefetcher(read openapi3.ReadFromURIFunc, maxConcurrent int) *prefetcher {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentFetches
+	}
+	return &prefetcher{
+		read: read,
+		sem:  make(chan struct{}, maxConcurrent),
+		docs: make(map[string]*fetchedDoc),
+	}
+}
+
+// ReadFromURI is an [openapi3.ReadFromURIFunc]. On the first read of a
+// document, all documents it references, transitively, are fetched
+// concurrently, and the errors of all fetches are returned joined.
+func (p *prefetcher) ReadFromURI(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+	if doc, ok := p.cached(location); ok {
+		doc.once.Do(func() {}) // Wait for a fetch in progress
+		return doc.data, doc.err
+	}
+	if err := p.prefetch(loader, location); err != nil {
+		return nil, err
+	}
+	doc, _ := p.cached(location)
+	return doc.data, doc.err
+}
+
+func (p *prefetcher) cached(location *url.URL) (*fetchedDoc, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	doc, ok := p.docs[location.String()]
+	return doc, ok
+}
+
+// prefetch fetches the document at root and the documents it references,
+// transitively, with at most cap(p.sem) concurrent reads. Fetches waiting for
+// a read slot fail once the context of the loader is done.
+func (p *prefetcher) prefetch(loader *openapi3.Loader, root *url.URL) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	var fetch func(location *url.URL)
+	fetch = func(location *url.URL) {
+		defer wg.Done()
+
+		p.mu.Lock()
+		doc, ok := p.docs[location.String()]
+		if !ok {
+			doc = &fetchedDoc{}
+			p.docs[location.String()] = doc
+		}
+		p.mu.Unlock()
+		if ok {
+			return // Fetched or being fetched by another worker
+		}
+
+		doc.once.Do(func() {
+			select {
+			case p.sem <- struct{}{}:
+			case <-loaderContext(loader).Done():
+				doc.err = loaderContext(loader).Err()
+				return
+			}
+			defer func() { <-p.sem }()
+			doc.data, doc.err = p.read(loader, location)
+		})
+		if doc.err != nil {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("%s: %w", location, doc.err))
+			mu.Unlock()
+			return
+		}
+
+		for _, ref := range externalRefs(doc.data, location) {
+			wg.Add(1)
+			go fetch(ref)
+		}
+	}
+
+	wg.Add(1)
+	fetch(root)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// externalRefs returns the locations of the documents referenced by the $refs
+// of data, resolved against the location of data. Unparsable documents have
+// no refs, the loader reports their errors.
+func externalRefs(data []byte, location *url.URL) []*url.URL {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	var refs []*url.URL
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				if u := resolveRef(ref, location); u != nil {
+					if _, ok := seen[u.String()]; !ok {
+						seen[u.String()] = struct{}{}
+						refs = append(refs, u)
+					}
+				}
+			}
+			for _, item := range v {
+				walk(item)
+			}
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(doc)
+	return refs
+}
+
+// resolveRef returns the location of the document referenced by ref, without
+// fragment, or nil for a ref within the same document.
+func resolveRef(ref string, base *url.URL) *url.URL {
+	docRef, _, _ := strings.Cut(ref, "#")
+	if docRef == "" {
+		return nil
+	}
+	u, err := url.Parse(docRef)
+	if err != nil {
+		return nil
+	}
+	switch {
+	case u.IsAbs() || u.Host != "":
+		return u
+	case base.IsAbs():
+		return base.ResolveReference(u)
+	case path.IsAbs(u.Path):
+		return u
+	default:
+		return &url.URL{Path: path.Join(path.Dir(base.Path), u.Path)}
+	}
+}
@@ -0,0 +1,121 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var errFetch = errors.New("fetch failed")
+
+// fakeRemote serves documents by URL, counting the reads of each one. Each
+// read takes latency, as a network fetch would.
+type fakeRemote struct {
+	docs    map[string]string
+	latency time.Duration
+
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+// newFakeRemote returns documents where the root references n documents,
+// which all reference a shared one, and the root also references the
+// failing documents.
+func newFakeRemote(n int, failing []string, latency time.Duration) *fakeRemote {
+	docs := map[string]string{
+		"https://example.com/shared.yaml": "Shared: {type: string}\n",
+	}
+	var root strings.Builder
+	for i := range n {
+		name := fmt.Sprintf("doc%d.yaml", i)
+		fmt.Fprintf(&root, "P%d: {$ref: '%s#/S'}\n", i, name)
+		docs["https://example.com/"+name] = "S: {$ref: 'shared.yaml#/Shared'}\n"
+	}
+	for _, name := range failing {
+		fmt.Fprintf(&root, "%s: {$ref: '%s#/S'}\n", name, name)
+	}
+	docs["https://example.com/root.yaml"] = root.String()
+	return &fakeRemote{docs: docs, latency: latency, reads: make(map[string]int)}
+}
+
+func (r *fakeRemote) ReadFromURI(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+	time.Sleep(r.latency)
+	r.mu.Lock()
+	r.reads[location.String()]++
+	r.mu.Unlock()
+	data, ok := r.docs[location.String()]
+	if !ok {
+		return nil, errFetch
+	}
+	return []byte(data), nil
+}
+
+func prefetchRoot(t testing.TB, p *prefetcher) error {
+	t.Helper()
+	root, err := url.Parse("https://example.com/root.yaml")
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	_, err = p.ReadFromURI(openapi3.NewLoader(), root)
+	return err
+}
+
+func TestPrefetcherReadsEachDocumentOnce(t *testing.T) {
+	remote := newFakeRemote(16, nil, 0)
+	p := newPrefetcher(remote.ReadFromURI, 4)
+	if err := prefetchRoot(t, p); err != nil {
+		t.Fatalf("ReadFromURI: %v", err)
+	}
+	if len(remote.reads) != len(remote.docs) {
+		t.Errorf("got %d documents read, want %d", len(remote.reads), len(remote.docs))
+	}
+	for location, reads := range remote.reads {
+		if reads != 1 {
+			t.Errorf("got %d reads of %s, want 1", reads, location)
+		}
+	}
+
+	// The loader reads the prefetched documents from the cache
+	shared, _ := url.Parse("https://example.com/shared.yaml")
+	data, err := p.ReadFromURI(openapi3.NewLoader(), shared)
+	if err != nil || string(data) != remote.docs[shared.String()] {
+		t.Errorf("got cached %q, %v, want the shared document", data, err)
+	}
+	if reads := remote.reads[shared.String()]; reads != 1 {
+		t.Errorf("got %d reads of the cached document, want 1", reads)
+	}
+}
+
+func TestPrefetcherJoinsErrors(t *testing.T) {
+	remote := newFakeRemote(4, []string{"missing1.yaml", "missing2.yaml"}, 0)
+	err := prefetchRoot(t, newPrefetcher(remote.ReadFromURI, 2))
+	if !errors.Is(err, errFetch) {
+		t.Fatalf("got %v, want fetch errors", err)
+	}
+	for _, name := range []string{"missing1.yaml", "missing2.yaml"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("got %v, want the error of %s", err, name)
+		}
+	}
+}
+
+// BenchmarkPrefetcher fetches 32 documents taking 2ms each, sequentially and
+// with the default concurrency.
+func BenchmarkPrefetcher(b *testing.B) {
+	for _, maxConcurrent := range []int{1, DefaultMaxConcurrentFetches} {
+		b.Run(fmt.Sprintf("concurrency=%d", maxConcurrent), func(b *testing.B) {
+			for range b.N {
+				remote := newFakeRemote(32, nil, 2*time.Millisecond)
+				if err := prefetchRoot(b, newPrefetcher(remote.ReadFromURI, maxConcurrent)); err != nil {
+					b.Fatalf("ReadFromURI: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// DefaultFetchRetryBackoff is the delay before the first retry of an external
+// ref fetch when LoaderConfig.FetchRetryBackoff is unset.
+const DefaultFetchRetryBackoff = 500 * time.Millisecond
+
+// statusError is returned when a remote document is served with an error
+// status code.
+type statusError struct {
+	location *url.URL
+	code     int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("error loading %q: request returned status code %d", e.location, e.code)
+}
+
+// retrier retries the reads of remote documents failing transiently: 5xx
+// responses and connection errors. The delay doubles after each retry. Retries
+// stop when the context of the loader is done, or would be before the next
+// attempt.
+type retrier struct {
+	read    openapi3.ReadFromURIFunc
+	retries int
+	backoff time.Duration
+	logger  *slog.Logger
+}
+
+func newRetrier(read openapi3.ReadFromURIFunc, retries int, backoff time.Duration, logger *slog.Logger) *retrier {
+	if backoff <= 0 {
+		backoff = DefaultFetchRetryBackoff
+	}
+	return &retrier{read: read, retries: retries, backoff: backoff, logger: logger}
+}
+
+// ReadFromURI is an [openapi3.ReadFromURIFunc].
+func (r *retrier) ReadFromURI(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+	ctx := loaderContext(loader)
+	delay := r.backoff
+	for attempt := 1; ; attempt++ {
+		data, err := r.read(loader, location)
+		if err == nil || attempt > r.retries || !isTransient(err) {
+			return data, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, err // No time left for another attempt
+		}
+		r.logger.Debug("retrying external ref fetch",
+			slog.String("url", location.String()),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.Any("error", err))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w (retry canceled: %w)", err, ctx.Err())
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// isTransient reports whether a failed read may succeed if retried: a 5xx
+// response or a connection error. Canceled reads and 4xx responses are not
+// retried.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if statusErr := (*statusError)(nil); errors.As(err, &statusErr) {
+		return statusErr.code >= http.StatusInternalServerError
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
@@ -0,0 +1,72 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// Loader loads the spec to filter from a source, such as a file path. It
+// lets specs be read from elsewhere, e.g. an embedded FS, a database or
+// memory in tests.
+type Loader interface {
+	Load(ctx context.Context, source string) (*openapi3.T, error)
+}
+
+// LoaderFunc adapts a function to the Loader interface.
+type LoaderFunc func(ctx context.Context, source string) (*openapi3.T, error)
+
+// Load calls f(ctx, source).
+func (f LoaderFunc) Load(ctx context.Context, source string) (*openapi3.T, error) {
+	return f(ctx, source)
+}
+
+// FileLoader is the default Loader, reading the spec from a file path with
+// the kin-openapi loader configured by a LoaderConfig.
+type FileLoader struct {
+	cfg    *config.LoaderConfig
+	logger *slog.Logger
+}
+
+// NewFileLoader returns a FileLoader honoring cfg, which may be nil.
+func NewFileLoader(cfg *config.LoaderConfig) *FileLoader {
+	return &FileLoader{cfg: cfg, logger: slog.Default()}
+}
+
+// WithLogger sets the logger of the retries of external ref fetches and
+// returns fl.
+func (fl *FileLoader) WithLogger(logger *slog.Logger) *FileLoader {
+	fl.logger = logger
+	return fl
+}
+
+// Load loads the spec at the file path source. External ref fetches are
+// canceled when ctx is done. A spec split across several documents is loaded
+// as one: the targets of its refs to other documents are moved to its
+// components, named after their file and fragment, e.g. schemas/pet.yaml to
+// #/components/schemas/schemas_pet, and path items are inlined.
+func (fl *FileLoader) Load(ctx context.Context, source string) (*openapi3.T, error) {
+	loader := NewLoaderWithLogger(ctx, fl.cfg, fl.logger)
+	var documents atomic.Int64 // Read concurrently by the prefetcher
+	read := loader.ReadFromURIFunc
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+		documents.Add(1)
+		return read(loader, location)
+	}
+
+	doc, err := loader.LoadFromFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("loader.LoadFromFile: %w", err)
+	}
+	if documents.Load() > 1 {
+		doc.InternalizeRefs(ctx, nil)
+		fl.logger.Debug("spec refs to other documents internalized", slog.String("path", source))
+	}
+	return doc, nil
+}
@@ -0,0 +1,85 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+func TestFileLoaderLoadsSpecWithBOM(t *testing.T) {
+	specs := map[string]string{
+		"yaml": "openapi: 3.0.3\ninfo: {title: Pets, version: 1.0.0}\npaths:\n  /pets: {}\n",
+		"json": `{"openapi": "3.0.3", "info": {"title": "Pets", "version": "1.0.0"}, "paths": {"/pets": {}}}`,
+	}
+	for format, content := range specs {
+		t.Run(format, func(t *testing.T) {
+			specPath := filepath.Join(t.TempDir(), "openapi."+format)
+			if err := os.WriteFile(specPath, append([]byte("\xef\xbb\xbf"), content...), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+			spec, err := NewFileLoader(nil).Load(context.Background(), specPath)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if spec.OpenAPI != "3.0.3" || spec.Paths.Value("/pets") == nil {
+				t.Errorf("got openapi %q and paths %v, want the loaded spec", spec.OpenAPI, spec.Paths.InMatchingOrder())
+			}
+		})
+	}
+}
+
+func TestFileLoaderRemoteRefs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow.yaml" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		fmt.Fprint(w, "Pet: {type: object}\n")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		file    string
+		cfg     *config.LoaderConfig
+		wantErr error
+	}{
+		{"allowed host", "pet.yaml", &config.LoaderConfig{IsExternalRefsAllowed: true, AllowedHosts: []string{"127.0.0.1"}}, nil},
+		{"host not allowed", "pet.yaml", &config.LoaderConfig{IsExternalRefsAllowed: true, AllowedHosts: []string{"*.example.com"}}, config.ErrHostNotAllowed},
+		{"timeout", "slow.yaml", &config.LoaderConfig{IsExternalRefsAllowed: true, HTTPTimeout: 20 * time.Millisecond}, context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := fmt.Sprintf(`openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      responses:
+        '200':
+          description: Pet
+          content:
+            application/json:
+              schema: {$ref: '%s/%s#/Pet'}
+`, srv.URL, tt.file)
+			specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+			if err := os.WriteFile(specPath, []byte(spec), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+			_, err := NewFileLoader(tt.cfg).Load(context.Background(), specPath)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// Format is an output format of the plan.
+type Format string
+
+const (
+	FormatText   Format = "text"   // Human-readable lines
+	FormatGitHub Format = "github" // GitHub Actions workflow commands
+)
+
+// ParseFormat parses a plan format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatText, FormatGitHub:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported plan format: %s", s)
+	}
+}
+
+// Write renders the plan entries in the given format. For the GitHub format,
+// annotations point at the lines of configPath defining the related path keys.
+func Write(w io.Writer, entries []Entry, format Format, configPath string) error {
+	switch format {
+	case FormatText:
+		return writeText(w, entries)
+	case FormatGitHub:
+		return writeGitHub(w, entries, configPath)
+	default:
+		return fmt.Errorf("unsupported plan format: %s", format)
+	}
+}
+
+func writeText(w io.Writer, entries []Entry) error {
+	for _, e := range entries {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", e.Level, e.Message); err != nil {
+			return fmt.Errorf("fmt.Fprintf: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeGitHub(w io.Writer, entries []Entry, configPath string) error {
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, e.Path)
+	}
+	lines, err := config.KeyLines(configPath, keys)
+	if err != nil {
+		return fmt.Errorf("config.KeyLines: %w", err)
+	}
+
+	file := filepath.ToSlash(configPath)
+	for _, e := range entries {
+		props := "file=" + escapeProperty(file)
+		if line, ok := lines[e.Path]; ok {
+			props += fmt.Sprintf(",line=%d", line)
+		}
+		props += ",title=openapi-filter"
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n",
+			e.Level, props, escapeData(e.Message)); err != nil {
+			return fmt.Errorf("fmt.Fprintf: %w", err)
+		}
+	}
+	return nil
+}
+
+// escapeData escapes a workflow command message as GitHub Actions expects.
+func escapeData(s string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	).Replace(s)
+}
+
+// escapeProperty escapes a workflow command property value.
+func escapeProperty(s string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	).Replace(s)
+}
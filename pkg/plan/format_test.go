@@ -0,0 +1,48 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+func TestWriteGitHubTypoedPath(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".openapi-filter.yaml")
+	configData := "servers: true\npaths:\n  /pets: [get]\n  /pest: [get]\n"
+	if err := os.WriteFile(configPath, []byte(configData), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	paths := openapi3.NewPaths()
+	paths.Set("/pets", &openapi3.PathItem{Get: &openapi3.Operation{}})
+	doc := &openapi3.T{OpenAPI: "3.0.3", Paths: paths}
+	cfg := &config.FilterConfig{Paths: map[string]config.PathConfig{
+		"/pets": {Methods: []string{"get"}},
+		"/pest": {Methods: []string{"get"}},
+	}}
+
+	var out strings.Builder
+	if err := Write(&out, Build(cfg, doc), FormatGitHub, configPath); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	file := escapeProperty(filepath.ToSlash(configPath))
+	want := "::warning file=" + file + ",line=4,title=openapi-filter::path not found in spec: /pest\n" +
+		"::notice file=" + file + ",line=3,title=openapi-filter::keep GET /pets\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestEscapeGitHub(t *testing.T) {
+	if got, want := escapeData("50% done\nnext"), "50%25 done%0Anext"; got != want {
+		t.Errorf("escapeData = %q, want %q", got, want)
+	}
+	if got, want := escapeProperty("C:\\a,b"), "C%3A\\a%2Cb"; got != want {
+		t.Errorf("escapeProperty = %q, want %q", got, want)
+	}
+}
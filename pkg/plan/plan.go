@@ -0,0 +1,140 @@
+// Package plan builds a dry-run plan describing what filtering a spec with a
+// given config would keep, and renders it in human- and machine-readable
+// formats.
+package plan
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zguydev/openapi-filter/pkg/config"
+)
+
+// Level is the severity of a plan entry.
+type Level string
+
+const (
+	LevelNotice  Level = "notice"
+	LevelWarning Level = "warning"
+)
+
+// Entry is a single planned action or a problem found in the config.
+type Entry struct {
+	Level   Level
+	Path    string // Path key from the config or spec the entry relates to
+	Method  string // Upper-cased HTTP method, empty for path-level entries
+	Message string
+}
+
+var knownMethods = []string{
+	http.MethodConnect,
+	http.MethodDelete,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPatch,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodTrace,
+}
+
+// Build computes the plan of applying cfg to doc without modifying either.
+// Entries are sorted by path and method.
+func Build(cfg *config.FilterConfig, doc *openapi3.T) []Entry {
+	var entries []Entry
+	kept := make(map[string]map[string]struct{})
+	keep := func(path, method, reason string) {
+		if cfg.IsPathExcluded(path) || !cfg.KeepsMethod(method) {
+			return
+		}
+		if op := doc.Paths.Value(path).GetOperation(method); op != nil &&
+			cfg.IsOperationIDExcluded(op.OperationID) {
+			return
+		}
+		if kept[path] == nil {
+			kept[path] = make(map[string]struct{})
+		}
+		if _, ok := kept[path][method]; ok {
+			return
+		}
+		kept[path][method] = struct{}{}
+		entries = append(entries, Entry{
+			Level:   LevelNotice,
+			Path:    path,
+			Method:  method,
+			Message: "keep " + method + " " + path + reason,
+		})
+	}
+
+	for path, pathConfig := range cfg.Paths {
+		pathItem := doc.Paths.Find(path)
+		if pathItem == nil {
+			entries = append(entries, Entry{
+				Level:   LevelWarning,
+				Path:    path,
+				Message: "path not found in spec: " + path,
+			})
+			continue
+		}
+		for _, method := range pathConfig.Methods {
+			method = strings.ToUpper(method)
+			switch {
+			case !slices.Contains(knownMethods, method):
+				entries = append(entries, Entry{
+					Level:   LevelWarning,
+					Path:    path,
+					Method:  method,
+					Message: "unknown HTTP method in filter config: " + method,
+				})
+			case pathItem.GetOperation(method) == nil:
+				entries = append(entries, Entry{
+					Level:   LevelWarning,
+					Path:    path,
+					Method:  method,
+					Message: "method not exists for specified path: " + method + " " + path,
+				})
+			default:
+				keep(path, method, "")
+			}
+		}
+	}
+
+	if len(cfg.Rules) != 0 || len(cfg.IncludeOperationIds) != 0 || cfg.HasOverrideSelection() {
+		for path, pathItem := range doc.Paths.Map() {
+			for method, op := range pathItem.Operations() {
+				if cfg.IsOperationIDIncluded(op.OperationID) {
+					keep(path, method, " (selected by operationId "+op.OperationID+")")
+					continue
+				}
+				if key, ok := cfg.MatchIncludedOverride(op.Extensions); ok {
+					keep(path, method, " (selected by "+config.ToolConfigKey+" "+key+")")
+					continue
+				}
+				for _, rule := range cfg.Rules {
+					if rule.Matches(path, method, op.Tags) {
+						keep(path, method, " (selected by rule)")
+						break
+					}
+				}
+			}
+		}
+	}
+
+	for _, problem := range cfg.ValidateOperationIDs(doc) {
+		entries = append(entries, Entry{
+			Level:   LevelWarning,
+			Message: problem,
+		})
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+	return entries
+}
@@ -0,0 +1,191 @@
+package writer
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// layoutNode converts the marshaled spec to a YAML node tree laid out like the
+// source spec: mapping keys keep their source order, keys not in the source
+// come after them, and comments of nodes surviving in the output are kept.
+// Anchors and aliases are not restored, as the loader expands them. The
+// layout is best-effort: if the source cannot be read as YAML, the node tree
+// is returned in the marshaled order.
+func layoutNode(data any, sourcePath string) (*yaml.Node, error) {
+	out, err := toNode(data)
+	if err != nil {
+		return nil, fmt.Errorf("toNode: %w", err)
+	}
+
+	content, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	var src yaml.Node
+	if err := yaml.Unmarshal(content, &src); err != nil {
+		return out, nil
+	}
+	applyLayout(out, &src)
+	return out, nil
+}
+
+// sortedNode converts the marshaled spec to a YAML node tree with the keys of
+// every mapping sorted alphabetically. Sequences keep their order.
+func sortedNode(data any) (*yaml.Node, error) {
+	out, err := toNode(data)
+	if err != nil {
+		return nil, fmt.Errorf("toNode: %w", err)
+	}
+	sortMappings(out)
+	return out, nil
+}
+
+// toNode converts the marshaled spec to a YAML node tree, encoding it
+// directly into the nodes rather than through its YAML text.
+func toNode(data any) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := root.Encode(data); err != nil {
+		return nil, fmt.Errorf("root.Encode: %w", err)
+	}
+	// Wrap the root as a loaded document is, which the layout expects
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&root}}, nil
+}
+
+// sortMappings sorts the key/value pairs of every mapping of n by key,
+// recursively.
+func sortMappings(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		pairs := make([][2]*yaml.Node, 0, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			pairs = append(pairs, [2]*yaml.Node{n.Content[i], n.Content[i+1]})
+		}
+		slices.SortStableFunc(pairs, func(a, b [2]*yaml.Node) int {
+			return strings.Compare(a[0].Value, b[0].Value)
+		})
+		for i, pair := range pairs {
+			n.Content[2*i], n.Content[2*i+1] = pair[0], pair[1]
+		}
+	}
+	for _, child := range n.Content {
+		sortMappings(child)
+	}
+}
+
+// applyLayout orders the mappings of out like those of src and copies the
+// comments of src, recursively.
+func applyLayout(out, src *yaml.Node) {
+	if src.Kind == yaml.AliasNode && src.Alias != nil {
+		src = src.Alias
+	}
+	copyComments(out, src)
+
+	switch {
+	case out.Kind == yaml.DocumentNode && src.Kind == yaml.DocumentNode:
+		if len(out.Content) == 1 && len(src.Content) == 1 {
+			applyLayout(out.Content[0], src.Content[0])
+		}
+	case out.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		applyMappingLayout(out, src)
+	case out.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		applySequenceLayout(out, src)
+	}
+}
+
+// applyMappingLayout sorts the key/value pairs of out by their position in src.
+func applyMappingLayout(out, src *yaml.Node) {
+	type pair struct {
+		key, value *yaml.Node
+		order      int
+		src        int // Index of the key in src.Content, -1 if not in src
+	}
+
+	srcIndex := make(map[string]int, len(src.Content)/2)
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		if _, ok := srcIndex[src.Content[i].Value]; !ok {
+			srcIndex[src.Content[i].Value] = i
+		}
+	}
+
+	pairs := make([]pair, 0, len(out.Content)/2)
+	for i := 0; i+1 < len(out.Content); i += 2 {
+		p := pair{key: out.Content[i], value: out.Content[i+1], order: len(src.Content) + i, src: -1}
+		if j, ok := srcIndex[p.key.Value]; ok {
+			p.order, p.src = j, j
+		}
+		pairs = append(pairs, p)
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int {
+		return a.order - b.order
+	})
+
+	out.Content = out.Content[:0]
+	for _, p := range pairs {
+		if p.src >= 0 {
+			copyComments(p.key, src.Content[p.src])
+			applyLayout(p.value, src.Content[p.src+1])
+		}
+		out.Content = append(out.Content, p.key, p.value)
+	}
+}
+
+// applySequenceLayout matches the items of out with those of src: by position
+// when no item was removed, by their scalar fields otherwise.
+func applySequenceLayout(out, src *yaml.Node) {
+	if len(out.Content) == len(src.Content) {
+		for i := range out.Content {
+			applyLayout(out.Content[i], src.Content[i])
+		}
+		return
+	}
+
+	used := make([]bool, len(src.Content))
+	for _, item := range out.Content {
+		fp := fingerprint(item)
+		for j, srcItem := range src.Content {
+			if !used[j] && fingerprint(srcItem) == fp {
+				used[j] = true
+				applyLayout(item, srcItem)
+				break
+			}
+		}
+	}
+}
+
+// fingerprint identifies a sequence item by its scalar value or, for a
+// mapping, by its scalar fields regardless of their order.
+func fingerprint(n *yaml.Node) string {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Value
+	case yaml.MappingNode:
+		var fields []string
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if v := n.Content[i+1]; v.Kind == yaml.ScalarNode {
+				fields = append(fields, n.Content[i].Value+"="+v.Value)
+			}
+		}
+		slices.Sort(fields)
+		return strings.Join(fields, "\x00")
+	}
+	return ""
+}
+
+// copyComments copies the comments of src to out, unless out has its own.
+func copyComments(out, src *yaml.Node) {
+	if out.HeadComment == "" {
+		out.HeadComment = src.HeadComment
+	}
+	if out.LineComment == "" {
+		out.LineComment = src.LineComment
+	}
+	if out.FootComment == "" {
+		out.FootComment = src.FootComment
+	}
+}
@@ -0,0 +1,119 @@
+package writer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const layoutSource = `# Pets API
+openapi: 3.0.3
+paths:
+  # Listing
+  /pets:
+    get:
+      responses:
+        '404':
+          description: Not found
+        '200':
+          description: Pets # The pets
+      summary: List pets
+  /owners:
+    get:
+      responses:
+        '200':
+          description: Owners
+info:
+  version: 1.0.0
+  title: Pets
+tags:
+  - name: pets
+    description: Pets
+  - name: owners
+`
+
+const layoutWant = `# Pets API
+openapi: 3.0.3
+paths:
+  # Listing
+  /pets:
+    get:
+      responses:
+        "404":
+          description: Not found
+        "200":
+          description: Pets # The pets
+      summary: List pets
+info:
+  version: 1.0.0
+  title: Pets
+  description: Added
+tags:
+  - name: pets
+    description: Pets
+`
+
+func TestWriteSpecStreamSourceLayout(t *testing.T) {
+	sourcePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(sourcePath, []byte(layoutSource), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	doc, err := openapi3.NewLoader().LoadFromFile(sourcePath)
+	if err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	doc.Paths.Delete("/owners")
+	doc.Tags = doc.Tags[:1]
+	doc.Info.Description = "Added"
+
+	got, err := MarshalSpec(doc, FormatYAML, Options{SourcePath: sourcePath})
+	if err != nil {
+		t.Fatalf("MarshalSpec: %v", err)
+	}
+	if string(got) != layoutWant {
+		t.Errorf("got:\n%s\nwant:\n%s", got, layoutWant)
+	}
+}
+
+func TestWriteSpecStreamSortKeys(t *testing.T) {
+	sourcePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(sourcePath, []byte(layoutSource), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	doc, err := openapi3.NewLoader().LoadFromFile(sourcePath)
+	if err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	got, err := MarshalSpec(doc, FormatYAML, Options{SourcePath: sourcePath, SortKeys: true})
+	if err != nil {
+		t.Fatalf("MarshalSpec: %v", err)
+	}
+	want := `info:
+  title: Pets
+  version: 1.0.0
+openapi: 3.0.3
+paths:
+  /owners:
+    get:
+      responses:
+        "200":
+          description: Owners
+  /pets:
+    get:
+      responses:
+        "200":
+          description: Pets
+        "404":
+          description: Not found
+      summary: List pets
+tags:
+  - description: Pets
+    name: pets
+  - name: owners
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
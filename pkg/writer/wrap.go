@@ -0,0 +1,151 @@
+package writer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The yaml.v3 encoder never wraps lines, so the YAML output is wrapped in two
+// steps: foldLongStrings marks the strings ending past the line width as
+// folded scalars, which the encoder writes on a single line below a ">-"
+// header, and foldWriter breaks those lines between words. A folded scalar
+// reads a single line break as a space, so the strings load back unchanged.
+
+// foldLongStrings sets the folded style on the single-line string scalars of n
+// ending past lineWidth, and returns their values. Strings that cannot be
+// broken between words, or whose block would carry a comment, are left as is.
+func foldLongStrings(n *yaml.Node, lineWidth int) map[string]struct{} {
+	folded := make(map[string]struct{})
+	markLongStrings(n, 0, lineWidth, folded)
+	return folded
+}
+
+// markLongStrings marks the long strings of n, written from column col.
+func markLongStrings(n *yaml.Node, col, lineWidth int, folded map[string]struct{}) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, child := range n.Content {
+			markLongStrings(child, col, lineWidth, folded)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if value.Kind == yaml.ScalarNode {
+				markLongString(value, col+utf8.RuneCountInString(key.Value)+2, lineWidth, folded)
+				continue
+			}
+			markLongStrings(value, col+2, lineWidth, folded)
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			if item.Kind == yaml.ScalarNode {
+				markLongString(item, col+2, lineWidth, folded)
+				continue
+			}
+			markLongStrings(item, col+2, lineWidth, folded)
+		}
+	}
+}
+
+// markLongString marks the scalar n, written from column col, if it is a
+// string ending past lineWidth that can be broken between words.
+func markLongString(n *yaml.Node, col, lineWidth int, folded map[string]struct{}) {
+	if n.ShortTag() != "!!str" || n.Style != 0 || n.LineComment != "" {
+		return
+	}
+	if col+utf8.RuneCountInString(n.Value) <= lineWidth ||
+		strings.Contains(n.Value, "\n") || strings.TrimSpace(n.Value) != n.Value || !strings.Contains(n.Value, " ") {
+		return
+	}
+	n.Style = yaml.FoldedStyle
+	folded[n.Value] = struct{}{}
+}
+
+// foldWriter breaks the lines of the folded strings written by the encoder,
+// as they are written.
+type foldWriter struct {
+	w         io.Writer
+	lineWidth int
+	folded    map[string]struct{}
+
+	buf    []byte // Start of a line not written in full yet
+	header bool   // The previous line opened a folded scalar
+}
+
+func newFoldWriter(w io.Writer, lineWidth int, folded map[string]struct{}) *foldWriter {
+	return &foldWriter{w: w, lineWidth: lineWidth, folded: folded}
+}
+
+// Write writes the complete lines of p, breaking those of folded strings,
+// and buffers the rest until the end of its line is written.
+func (fw *foldWriter) Write(p []byte) (int, error) {
+	fw.buf = append(fw.buf, p...)
+	var out bytes.Buffer
+	for {
+		i := bytes.IndexByte(fw.buf, '\n')
+		if i < 0 {
+			break
+		}
+		fw.writeLine(&out, string(fw.buf[:i]))
+		fw.buf = fw.buf[i+1:]
+	}
+	if _, err := fw.w.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the buffered rest of the output.
+func (fw *foldWriter) Flush() error {
+	if len(fw.buf) == 0 {
+		return nil
+	}
+	_, err := fw.w.Write(fw.buf)
+	fw.buf = nil
+	return err
+}
+
+// writeLine writes line to out, broken between words if it holds a folded
+// string.
+func (fw *foldWriter) writeLine(out *bytes.Buffer, line string) {
+	text := strings.TrimLeft(line, " ")
+	indent := line[:len(line)-len(text)]
+	if _, ok := fw.folded[text]; fw.header && ok {
+		fw.writeFolded(out, indent, text)
+	} else {
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	fw.header = strings.HasSuffix(line, " >-")
+}
+
+// writeFolded writes text as lines of a folded scalar, breaking it at single
+// spaces between words: a line starting with a blank would be read back as
+// more indented, keeping its line break.
+func (fw *foldWriter) writeFolded(out *bytes.Buffer, indent, text string) {
+	width := fw.lineWidth - utf8.RuneCountInString(indent)
+	start, lastBreak := 0, -1
+	for i := 1; i < len(text)-1; i++ {
+		if text[i] != ' ' || isBlank(text[i-1]) || isBlank(text[i+1]) {
+			continue
+		}
+		if utf8.RuneCountInString(text[start:i]) > width && lastBreak > start {
+			out.WriteString(indent + text[start:lastBreak] + "\n")
+			start = lastBreak + 1
+		}
+		lastBreak = i
+	}
+	if utf8.RuneCountInString(text[start:]) > width && lastBreak > start {
+		out.WriteString(indent + text[start:lastBreak] + "\n")
+		start = lastBreak + 1
+	}
+	out.WriteString(indent + text[start:] + "\n")
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
@@ -0,0 +1,155 @@
+package writer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format of the spec.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat parses a spec format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatYAML, FormatJSON:
+		return f, nil
+	case "yml":
+		return FormatYAML, nil
+	default:
+		return "", fmt.Errorf("unsupported spec format: %s", s)
+	}
+}
+
+// Options controls how a spec is written.
+type Options struct {
+	// YAMLLineWidth is the preferred line width of the YAML output.
+	// Long single-line strings are folded between words at this width, see
+	// foldLongStrings. 0 disables wrapping.
+	YAMLLineWidth int
+
+	// SourcePath is the path of the spec the document was loaded from.
+	// When set, the YAML output keeps the key order and comments of the
+	// source, see layoutNode.
+	SourcePath string
+
+	// SortKeys sorts the keys of every mapping alphabetically, keeping the
+	// order of sequences, for reproducible output. It overrides the source
+	// layout of SourcePath. JSON keys are always sorted.
+	SortKeys bool
+}
+
+// WriteSpecToFile writes doc to the file at specPath, see WriteSpecStream.
+func WriteSpecToFile(doc *openapi3.T, specPath string, format Format, opts Options) error {
+	outputFile, err := os.Create(specPath)
+	if err != nil {
+		return fmt.Errorf("os.Create: %w", err)
+	}
+	defer outputFile.Close() //nolint:errcheck
+
+	// The encoder writes small chunks, batch them into fewer syscalls
+	w := bufio.NewWriter(outputFile)
+	if err := WriteSpecStream(w, doc, format, opts); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("w.Flush: %w", err)
+	}
+	if err := outputFile.Close(); err != nil {
+		return fmt.Errorf("outputFile.Close: %w", err)
+	}
+	return nil
+}
+
+// MarshalSpec returns doc encoded in format, as WriteSpecStream writes it.
+func MarshalSpec(doc *openapi3.T, format Format, opts Options) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteSpecStream(&buf, doc, format, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteSpecStream writes doc to w in format.
+//
+// The document is first converted by doc.MarshalYAML into a tree of maps, so
+// memory still grows with the size of the spec. Only the encoded text is
+// streamed: YAML is written to w as it is encoded, never held as a whole.
+// encoding/json encodes each value into a buffer before writing it, so the
+// JSON text is held in memory once.
+func WriteSpecStream(w io.Writer, doc *openapi3.T, format Format, opts Options) error {
+	switch format {
+	case FormatYAML:
+		return writeYAML(w, doc, opts)
+	case FormatJSON:
+		return writeJSON(w, doc)
+	default:
+		return fmt.Errorf("unsupported spec format: %s", format)
+	}
+}
+
+func writeYAML(w io.Writer, doc *openapi3.T, opts Options) error {
+	yamlData, err := doc.MarshalYAML()
+	if err != nil {
+		return fmt.Errorf("doc.MarshalYAML: %w", err)
+	}
+	switch {
+	case opts.SortKeys:
+		if yamlData, err = sortedNode(yamlData); err != nil {
+			return fmt.Errorf("sortedNode: %w", err)
+		}
+	case opts.SourcePath != "":
+		if yamlData, err = layoutNode(yamlData, opts.SourcePath); err != nil {
+			return fmt.Errorf("layoutNode: %w", err)
+		}
+	}
+
+	var fw *foldWriter
+	if opts.YAMLLineWidth > 0 {
+		node, ok := yamlData.(*yaml.Node)
+		if !ok {
+			if node, err = toNode(yamlData); err != nil {
+				return fmt.Errorf("toNode: %w", err)
+			}
+		}
+		fw = newFoldWriter(w, opts.YAMLLineWidth, foldLongStrings(node, opts.YAMLLineWidth))
+		yamlData, w = node, fw
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(yamlData); err != nil {
+		return fmt.Errorf("encoder.Encode: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("encoder.Close: %w", err)
+	}
+	if fw != nil {
+		if err := fw.Flush(); err != nil {
+			return fmt.Errorf("fw.Flush: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, doc *openapi3.T) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("enc.Encode: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+const testSpecPath = "../../examples/petstore/openapi.yaml"
+
+func loadTestSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromFile(testSpecPath)
+	if err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	return doc
+}
+
+// chunkWriter records the writes it receives.
+type chunkWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestWriteSpecStreamMatchesBuffered(t *testing.T) {
+	doc := loadTestSpec(t)
+	yamlData, err := doc.MarshalYAML()
+	if err != nil {
+		t.Fatalf("MarshalYAML: %v", err)
+	}
+	var buffered bytes.Buffer
+	encoder := yaml.NewEncoder(&buffered)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(yamlData); err != nil {
+		t.Fatalf("encoder.Encode: %v", err)
+	}
+
+	var w chunkWriter
+	if err := WriteSpecStream(&w, doc, FormatYAML, Options{}); err != nil {
+		t.Fatalf("WriteSpecStream: %v", err)
+	}
+	if !bytes.Equal(w.Bytes(), buffered.Bytes()) {
+		t.Errorf("streamed output differs from buffered output:\n%s\nwant:\n%s", w.Bytes(), buffered.Bytes())
+	}
+	if w.writes < 2 {
+		t.Errorf("got %d writes, want the output written in chunks", w.writes)
+	}
+}
+
+func TestWriteSpecOutputsAgree(t *testing.T) {
+	doc := loadTestSpec(t)
+	tests := []struct {
+		name   string
+		format Format
+		opts   Options
+	}{
+		{"yaml", FormatYAML, Options{}},
+		{"yaml wrapped", FormatYAML, Options{YAMLLineWidth: 40}},
+		{"yaml sorted", FormatYAML, Options{SortKeys: true}},
+		{"yaml source layout", FormatYAML, Options{SourcePath: testSpecPath}},
+		{"json", FormatJSON, Options{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marshaled, err := MarshalSpec(doc, tt.format, tt.opts)
+			if err != nil {
+				t.Fatalf("MarshalSpec: %v", err)
+			}
+			var streamed bytes.Buffer
+			if err := WriteSpecStream(&streamed, doc, tt.format, tt.opts); err != nil {
+				t.Fatalf("WriteSpecStream: %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "openapi."+string(tt.format))
+			if err := WriteSpecToFile(doc, path, tt.format, tt.opts); err != nil {
+				t.Fatalf("WriteSpecToFile: %v", err)
+			}
+			written, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("os.ReadFile: %v", err)
+			}
+			if !bytes.Equal(streamed.Bytes(), marshaled) {
+				t.Error("WriteSpecStream output differs from MarshalSpec")
+			}
+			if !bytes.Equal(written, marshaled) {
+				t.Error("WriteSpecToFile output differs from MarshalSpec")
+			}
+
+			reloaded, err := openapi3.NewLoader().LoadFromData(marshaled)
+			if err != nil {
+				t.Fatalf("LoadFromData: %v", err)
+			}
+			got, _ := json.Marshal(reloaded)
+			want, _ := json.Marshal(doc)
+			if !bytes.Equal(got, want) {
+				t.Error("output does not load back to the written spec")
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for s, want := range map[string]Format{"yaml": FormatYAML, "YML": FormatYAML, "json": FormatJSON} {
+		if got, err := ParseFormat(s); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", s, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("toml"); err == nil {
+		t.Error("ParseFormat(\"toml\") succeeded, want an error")
+	}
+}
+
+func TestWriteSpecStreamLineWidth(t *testing.T) {
+	description := strings.Repeat("A long description of the pets API. ", 8)
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "Pets", Version: "1.0.0", Description: strings.TrimSpace(description)},
+		Paths:   openapi3.NewPaths(),
+		Tags:    openapi3.Tags{{Name: "pets", Description: "Pets  of the store, by kind. " + strings.TrimSpace(description)}},
+	}
+	tests := []struct {
+		lineWidth int
+		wrapped   bool
+	}{
+		{0, false},
+		{1000, false},
+		{40, true},
+	}
+	for _, tt := range tests {
+		out, err := MarshalSpec(doc, FormatYAML, Options{YAMLLineWidth: tt.lineWidth})
+		if err != nil {
+			t.Fatalf("MarshalSpec: %v", err)
+		}
+		unwrapped := strings.Contains(string(out), "description: "+strings.TrimSpace(description)+"\n")
+		if unwrapped == tt.wrapped {
+			t.Errorf("line width %d: got wrapped %v, want %v:\n%s", tt.lineWidth, !unwrapped, tt.wrapped, out)
+		}
+		if tt.wrapped {
+			for _, line := range strings.Split(string(out), "\n") {
+				if len(line) > tt.lineWidth {
+					t.Errorf("line width %d: got line of %d bytes: %q", tt.lineWidth, len(line), line)
+				}
+			}
+		}
+
+		reloaded, err := openapi3.NewLoader().LoadFromData(out)
+		if err != nil {
+			t.Fatalf("LoadFromData: %v", err)
+		}
+		if reloaded.Info.Description != doc.Info.Description {
+			t.Errorf("line width %d: got description %q, want it unchanged", tt.lineWidth, reloaded.Info.Description)
+		}
+		if got := reloaded.Tags[0].Description; got != doc.Tags[0].Description {
+			t.Errorf("line width %d: got tag description %q, want it unchanged", tt.lineWidth, got)
+		}
+	}
+}